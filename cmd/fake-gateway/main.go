@@ -0,0 +1,65 @@
+// Command fake-gateway plays the fake payment provider: it sends a signed
+// webhook for a payment to a running server, the way a real provider would
+// once the customer pays.
+//
+//	go run ./cmd/fake-gateway -payment PAY-20240115-0002 -amount 155000
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"flashlight-go/internal/gateway"
+)
+
+func main() {
+	url := flag.String("url", "http://localhost:8080/api/v1/webhooks/payments/fake", "webhook endpoint")
+	secret := flag.String("secret", os.Getenv("FAKE_GATEWAY_SECRET"), "shared signing secret")
+	paymentNumber := flag.String("payment", "", "payment number to settle")
+	amount := flag.Float64("amount", 0, "amount the customer paid, tip included")
+	status := flag.String("status", "paid", "paid, failed, expired or cancelled")
+	eventID := flag.String("event", "", "event ID; reuse one to simulate a redelivery")
+	flag.Parse()
+
+	if *paymentNumber == "" || *secret == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *eventID == "" {
+		*eventID = fmt.Sprintf("evt-%d", time.Now().UnixNano())
+	}
+
+	body, err := json.Marshal(gateway.FakePayload{
+		EventID:       *eventID,
+		PaymentNumber: *paymentNumber,
+		TransactionID: fmt.Sprintf("FAKE-%d", time.Now().Unix()),
+		Status:        *status,
+		Amount:        *amount,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, *url, bytes.NewReader(body))
+	if err != nil {
+		log.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(gateway.FakeSignatureHeader, gateway.NewFakeAdapter(*secret).Sign(body))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	response, _ := io.ReadAll(resp.Body)
+	fmt.Printf("%s\n%s\n", resp.Status, response)
+}
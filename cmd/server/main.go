@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	netmail "net/mail"
+	"time"
+	_ "time/tzdata"
 
 	"flashlight-go/config"
 	"flashlight-go/internal/database"
+	"flashlight-go/internal/gateway"
 	"flashlight-go/internal/handler"
+	"flashlight-go/internal/jobs"
+	"flashlight-go/internal/mail"
+	"flashlight-go/internal/middleware"
+	"flashlight-go/internal/receipt"
 	"flashlight-go/internal/repository"
 	"flashlight-go/internal/routes"
 	"flashlight-go/internal/service"
+	"flashlight-go/internal/settlement"
+	"flashlight-go/pkg/utils"
 )
 
 func main() {
@@ -19,6 +30,12 @@ func main() {
 		log.Fatal("Failed to load configuration:", err)
 	}
 
+	// Resolve outlet time zone
+	outletLocation, err := cfg.Outlet.Location()
+	if err != nil {
+		log.Fatal("Invalid outlet timezone:", err)
+	}
+
 	// Connect to database
 	db, err := database.Connect(cfg)
 	if err != nil {
@@ -40,21 +57,184 @@ func main() {
 	workOrderRepo := repository.NewWorkOrderRepository(db)
 	workOrderItemRepo := repository.NewWorkOrderItemRepository(db)
 	productRepo := repository.NewProductRepository(db)
+	productCategoryRepo := repository.NewProductCategoryRepository(db)
+	productPriceRepo := repository.NewProductPriceRepository(db)
+	pricingRuleRepo := repository.NewPricingRuleRepository(db)
+	vehiclePriceTierRepo := repository.NewVehiclePriceTierRepository(db)
+	promoRepo := repository.NewPromoRepository(db)
 	paymentRepo := repository.NewPaymentRepository(db)
 	shiftRepo := repository.NewShiftRepository(db)
+	shiftHandoverRepo := repository.NewShiftHandoverRepository(db)
+	storedValueRepo := repository.NewStoredValueRepository(db)
+	giftCardRepo := repository.NewGiftCardRepository(db)
+	loyaltyPointRepo := repository.NewLoyaltyPointRepository(db)
+	loyaltyEarnRuleRepo := repository.NewLoyaltyEarnRuleRepository(db)
+	loyaltyRewardRepo := repository.NewLoyaltyRewardRepository(db)
+	tipRepo := repository.NewTipAllocationRepository(db)
+	idempotencyRepo := repository.NewIdempotencyKeyRepository(db)
+	webhookEventRepo := repository.NewPaymentWebhookEventRepository(db)
+	usedApprovalTokenRepo := repository.NewUsedApprovalTokenRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	receiptDeliveryRepo := repository.NewReceiptDeliveryRepository(db)
+	cashMovementRepo := repository.NewCashMovementRepository(db)
+	settlementRepo := repository.NewSettlementRepository(db)
+	paymentMethodRepo := repository.NewPaymentMethodRepository(db)
+	customerVehicleRepo := repository.NewCustomerVehicleRepository(db)
+	fleetAccountRepo := repository.NewFleetAccountRepository(db)
+	invoiceRepo := repository.NewInvoiceRepository(db)
+
+	// Register payment providers
+	gateways := gateway.NewRegistry()
+	if cfg.Gateway.FakeSecret != "" {
+		gateways.Register(gateway.NewFakeAdapter(cfg.Gateway.FakeSecret))
+	}
 
 	// Initialize services
 	userService := service.NewUserService(userRepo)
-	workOrderService := service.NewWorkOrderService(workOrderRepo, workOrderItemRepo, productRepo, db)
-	paymentService := service.NewPaymentService(paymentRepo, workOrderRepo)
-	shiftService := service.NewShiftService(shiftRepo, paymentRepo)
+	pricingService := service.NewPricingService(productRepo, productPriceRepo, pricingRuleRepo, vehiclePriceTierRepo, promoRepo, customerVehicleRepo, userRepo, outletLocation, cfg.Outlet.TaxRate)
+	loyaltyService := service.NewLoyaltyService(loyaltyPointRepo, loyaltyEarnRuleRepo, loyaltyRewardRepo, workOrderRepo, paymentRepo, productRepo, userRepo, pricingService, db, cfg.Loyalty.PointsExpiryDays)
+	workOrderService := service.NewWorkOrderService(workOrderRepo, workOrderItemRepo, productRepo, productCategoryRepo, shiftRepo, pricingService, loyaltyService, db)
+	paymentMethodService := service.NewPaymentMethodService(paymentMethodRepo, paymentRepo, refundRepo, cfg.Outlet.Code, outletLocation)
+	if err := paymentMethodService.EnsureDefaults(context.Background()); err != nil {
+		log.Fatal("Failed to set up payment methods:", err)
+	}
+	walletService := service.NewWalletService(storedValueRepo, giftCardRepo, userRepo, shiftRepo, paymentMethodService, db, cfg.Wallet.GiftCardValidityDays)
+	tipService := service.NewTipService(tipRepo, userRepo, cfg.Tip.SplitRule, outletLocation)
+	qrisMerchant := utils.QRISMerchant{
+		AcquirerGUI: cfg.QRIS.AcquirerGUI,
+		MerchantPAN: cfg.QRIS.MerchantPAN,
+		MerchantID:  cfg.QRIS.MerchantID,
+		NMID:        cfg.QRIS.NMID,
+		Criteria:    cfg.QRIS.MerchantCriteria,
+		MCC:         cfg.QRIS.MCC,
+		Name:        cfg.QRIS.MerchantName,
+		City:        cfg.QRIS.MerchantCity,
+		PostalCode:  cfg.QRIS.PostalCode,
+		TerminalID:  cfg.QRIS.TerminalID,
+	}
+	receiptHeader := receipt.Header{
+		OutletName: cfg.Receipt.OutletName,
+		Address:    cfg.Receipt.Address,
+		Phone:      cfg.Receipt.Phone,
+		TaxID:      cfg.Receipt.TaxID,
+		Footer:     cfg.Receipt.FooterLines(),
+	}
+	receiptService := service.NewReceiptService(workOrderRepo, receiptHeader, cfg.Receipt.Paper, cfg.Receipt.QRURL, outletLocation)
+
+	// E-receipts go out by SMTP, or are written to disk in development
+	var mailTransport mail.Transport
+	switch cfg.Mail.Transport {
+	case mail.TransportSMTP:
+		mailTransport = &mail.SMTPTransport{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			Timeout:  time.Duration(cfg.Mail.TimeoutSeconds) * time.Second,
+		}
+	case mail.TransportFile:
+		mailTransport = &mail.FileTransport{Dir: cfg.Mail.FileDir}
+	default:
+		log.Fatalf("Unknown mail transport %q", cfg.Mail.Transport)
+	}
+	mailFrom := netmail.Address{Name: cfg.Mail.FromName, Address: cfg.Mail.FromAddress}
+	receiptDeliveryService, err := service.NewReceiptDeliveryService(receiptDeliveryRepo, workOrderRepo, receiptService, mailTransport, mailFrom, cfg.Mail.AutoSendEReceipt)
+	if err != nil {
+		log.Fatal("Failed to load mail templates:", err)
+	}
+
+	fleetAccountService := service.NewFleetAccountService(fleetAccountRepo, customerVehicleRepo, userRepo, paymentRepo, invoiceRepo)
+	invoiceService := service.NewInvoiceService(invoiceRepo, fleetAccountRepo, paymentRepo, workOrderRepo, paymentMethodService, receiptHeader, cfg.Invoice.PaymentInstructionLines(), outletLocation, db)
+	paymentService := service.NewPaymentService(paymentRepo, workOrderRepo, shiftRepo, walletService, loyaltyService, tipService, receiptDeliveryService, paymentMethodService, fleetAccountService, qrisMerchant, db)
+	webhookService := service.NewPaymentWebhookService(gateways, paymentRepo, webhookEventRepo, workOrderRepo, paymentService, db)
+	refundService := service.NewRefundService(refundRepo, paymentRepo, shiftRepo, workOrderRepo, walletService, loyaltyService, paymentMethodService, db)
+	cashDenominations, err := cfg.Outlet.Denominations()
+	if err != nil {
+		log.Fatal("Invalid cash denominations:", err)
+	}
+	shiftService := service.NewShiftService(shiftRepo, shiftHandoverRepo, paymentRepo, userRepo, cashDenominations, db)
+	supervisorService := service.NewSupervisorService(userRepo, usedApprovalTokenRepo, db)
+	cashMovementService := service.NewCashMovementService(cashMovementRepo, shiftRepo, db)
+	settlementLayouts, err := settlement.LoadLayouts(cfg.Settlement.LayoutsFile)
+	if err != nil {
+		log.Fatal("Failed to load settlement layouts:", err)
+	}
+	settlementService := service.NewSettlementService(settlementRepo, paymentRepo, settlementLayouts, outletLocation, db)
+	productService := service.NewProductService(productRepo, productCategoryRepo, productPriceRepo, db)
 
 	// Initialize handlers
 	userHandler := handler.NewUserHandler(userService)
 	workOrderHandler := handler.NewWorkOrderHandler(workOrderService)
+	productHandler := handler.NewProductHandler(productService)
+	pricingRuleHandler := handler.NewPricingRuleHandler(pricingService)
+	walletHandler := handler.NewWalletHandler(walletService)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyService)
+	tipHandler := handler.NewTipHandler(tipService)
+	paymentHandler := handler.NewPaymentHandler(paymentService, supervisorService)
+	webhookHandler := handler.NewPaymentWebhookHandler(webhookService)
+	refundHandler := handler.NewRefundHandler(refundService)
+	receiptHandler := handler.NewReceiptHandler(receiptService, receiptDeliveryService)
+	supervisorHandler := handler.NewSupervisorHandler(supervisorService)
+	cashMovementHandler := handler.NewCashMovementHandler(cashMovementService, shiftService, supervisorService)
+	settlementHandler := handler.NewSettlementHandler(settlementService)
+	paymentMethodHandler := handler.NewPaymentMethodHandler(paymentMethodService)
+	fleetAccountHandler := handler.NewFleetAccountHandler(fleetAccountService)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceService)
+	shiftHandler := handler.NewShiftHandler(shiftService)
+
+	// Initialize middleware
+	idempotency := middleware.IdempotencyMiddleware(idempotencyRepo, time.Duration(cfg.Idempotency.KeyTTLHours)*time.Hour)
+	activeShift := middleware.ActiveShiftMiddleware(shiftRepo)
+
+	// Start background jobs
+	if cfg.Jobs.Enabled {
+		pendingTTL := time.Duration(cfg.Jobs.PendingPaymentTTLMinutes) * time.Minute
+		runner := jobs.NewRunner(db)
+		runner.Add(jobs.Job{
+			Name:     "expire-pending-payments",
+			Interval: time.Duration(cfg.Jobs.PaymentExpiryIntervalSeconds) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := paymentService.ExpirePending(ctx, pendingTTL)
+				return err
+			},
+		})
+		runner.Add(jobs.Job{
+			Name:     "generate-invoices",
+			Interval: time.Duration(cfg.Jobs.InvoiceGenerationIntervalMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				_, err := invoiceService.GenerateLastMonth(ctx)
+				return err
+			},
+		})
+		runner.Add(jobs.Job{
+			Name:     "expire-gift-cards",
+			Interval: time.Duration(cfg.Jobs.GiftCardExpiryIntervalMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				_, err := walletService.ExpireGiftCards(ctx, time.Now())
+				return err
+			},
+		})
+		runner.Add(jobs.Job{
+			Name:     "expire-loyalty-points",
+			Interval: time.Duration(cfg.Jobs.LoyaltyExpiryIntervalMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				_, err := loyaltyService.ExpireAll(ctx, time.Now())
+				return err
+			},
+		})
+		runner.Add(jobs.Job{
+			Name:     "delete-expired-idempotency-keys",
+			Interval: time.Duration(cfg.Jobs.IdempotencyCleanupIntervalMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				_, err := idempotencyRepo.DeleteExpired(ctx, time.Now())
+				return err
+			},
+		})
+		runner.Start(context.Background())
+	}
 
 	// Setup routes
-	router := routes.NewRouter(userHandler, workOrderHandler)
+	router := routes.NewRouter(userHandler, workOrderHandler, productHandler, pricingRuleHandler, walletHandler, loyaltyHandler, tipHandler, paymentHandler, webhookHandler, refundHandler, receiptHandler, supervisorHandler, cashMovementHandler, settlementHandler, paymentMethodHandler, fleetAccountHandler, invoiceHandler, shiftHandler, idempotency, activeShift)
 	r := router.Setup()
 
 	// Start server
@@ -63,8 +243,4 @@ func main() {
 	if err := r.Run(addr); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
-
-	// Suppress unused variable warnings (these will be used when adding more routes)
-	_ = paymentService
-	_ = shiftService
 }
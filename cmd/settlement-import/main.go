@@ -0,0 +1,103 @@
+// Command settlement-import imports an acquirer's settlement file straight
+// into the database and prints the reconciliation report of every settlement
+// date in it. It reads the same configuration as the server.
+//
+//	go run ./cmd/settlement-import -layout qris -file settlement-20240116.csv
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"flashlight-go/config"
+	"flashlight-go/internal/database"
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/repository"
+	"flashlight-go/internal/service"
+	"flashlight-go/internal/settlement"
+)
+
+func main() {
+	layout := flag.String("layout", "", "settlement layout name")
+	file := flag.String("file", "", "settlement CSV file")
+	date := flag.String("date", "", "settlement date (YYYY-MM-DD) for layouts without a date column")
+	flag.Parse()
+
+	if *layout == "" || *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+	outletLocation, err := cfg.Outlet.Location()
+	if err != nil {
+		log.Fatal("Invalid outlet timezone:", err)
+	}
+	layouts, err := settlement.LoadLayouts(cfg.Settlement.LayoutsFile)
+	if err != nil {
+		log.Fatal("Failed to load settlement layouts:", err)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	settlementService := service.NewSettlementService(
+		repository.NewSettlementRepository(db),
+		repository.NewPaymentRepository(db),
+		layouts,
+		outletLocation,
+		db,
+	)
+
+	batch, reports, err := settlementService.Import(context.Background(), *layout, filepath.Base(*file), data, *date, nil)
+	if err != nil {
+		log.Fatal("Failed to import settlement file:", err)
+	}
+
+	fmt.Printf("Imported %s as batch %d: %d lines, gross %.2f, MDR %.2f, net %.2f\n",
+		batch.Filename, batch.ID, batch.LineCount, batch.GrossAmount, batch.MDRAmount, batch.NetAmount)
+	for _, report := range reports {
+		printReport(report)
+	}
+}
+
+func printReport(report dto.SettlementReport) {
+	fmt.Printf("\nSettlement %s (%s, payments of %s)\n", report.SettlementDate, report.Layout, report.TransactionDate)
+	fmt.Printf("  lines     %6d  gross %15.2f  MDR %12.2f  net %15.2f\n", report.LineCount, report.GrossAmount, report.MDRAmount, report.NetAmount)
+	fmt.Printf("  matched   %6d  amount %14.2f\n", report.MatchedCount, report.MatchedAmount)
+
+	for _, line := range report.AmountMismatches {
+		fmt.Printf("  MISMATCH  row %d %s: settled %.2f, expected %.2f (%+.2f)\n", line.RowNumber, line.Reference, line.GrossAmount, *line.ExpectedAmount, line.DifferenceAmount)
+	}
+	for _, line := range report.Unmatched {
+		note := "no payment with this reference"
+		if line.Note != nil {
+			note = *line.Note
+		}
+		fmt.Printf("  UNMATCHED row %d %s: %.2f, %s\n", line.RowNumber, line.Reference, line.GrossAmount, note)
+	}
+	for _, line := range report.Duplicates {
+		fmt.Printf("  DUPLICATE row %d %s: %.2f, %s\n", line.RowNumber, line.Reference, line.GrossAmount, *line.Note)
+	}
+	for _, payment := range report.Missing {
+		reference := "-"
+		if payment.ReferenceNumber != nil {
+			reference = *payment.ReferenceNumber
+		}
+		fmt.Printf("  MISSING   %s (ref %s): %.2f\n", payment.PaymentNumber, reference, payment.Amount)
+	}
+}
@@ -3,16 +3,31 @@ package config
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	JWT      JWTConfig
-	App      AppConfig
+	Database    DatabaseConfig
+	Server      ServerConfig
+	JWT         JWTConfig
+	App         AppConfig
+	Outlet      OutletConfig
+	Wallet      WalletConfig
+	Loyalty     LoyaltyConfig
+	Tip         TipConfig
+	Idempotency IdempotencyConfig
+	QRIS        QRISConfig
+	Gateway     GatewayConfig
+	Receipt     ReceiptConfig
+	Mail        MailConfig
+	Settlement  SettlementConfig
+	Jobs        JobsConfig
+	Invoice     InvoiceConfig
 }
 
 type DatabaseConfig struct {
@@ -38,6 +53,100 @@ type AppConfig struct {
 	Environment string
 }
 
+type WalletConfig struct {
+	GiftCardValidityDays int
+}
+
+type LoyaltyConfig struct {
+	PointsExpiryDays int
+}
+
+type ReceiptConfig struct {
+	OutletName string
+	Address    string
+	Phone      string
+	TaxID      string
+	Footer     string
+	Paper      string
+	QRURL      string
+}
+
+// FooterLines splits the configured footer into printed lines, which are
+// separated by "|".
+func (c *ReceiptConfig) FooterLines() []string {
+	return splitLines(c.Footer)
+}
+
+type MailConfig struct {
+	Transport        string
+	FileDir          string
+	SMTPHost         string
+	SMTPPort         int
+	SMTPUsername     string
+	SMTPPassword     string
+	TimeoutSeconds   int
+	FromAddress      string
+	FromName         string
+	AutoSendEReceipt bool
+}
+
+// JobsConfig controls the background jobs each server instance runs.
+type JobsConfig struct {
+	Enabled                           bool
+	PaymentExpiryIntervalSeconds      int
+	PendingPaymentTTLMinutes          int
+	InvoiceGenerationIntervalMinutes  int
+	GiftCardExpiryIntervalMinutes     int
+	LoyaltyExpiryIntervalMinutes      int
+	IdempotencyCleanupIntervalMinutes int
+}
+
+type InvoiceConfig struct {
+	PaymentInstructions string
+}
+
+// PaymentInstructionLines splits the configured payment instructions into
+// printed lines, which are separated by "|".
+func (c *InvoiceConfig) PaymentInstructionLines() []string {
+	return splitLines(c.PaymentInstructions)
+}
+
+type SettlementConfig struct {
+	LayoutsFile string
+}
+
+type GatewayConfig struct {
+	FakeSecret string
+}
+
+type QRISConfig struct {
+	AcquirerGUI      string
+	MerchantPAN      string
+	MerchantID       string
+	NMID             string
+	MerchantCriteria string
+	MCC              string
+	MerchantName     string
+	MerchantCity     string
+	PostalCode       string
+	TerminalID       string
+}
+
+type IdempotencyConfig struct {
+	KeyTTLHours int
+}
+
+type TipConfig struct {
+	SplitRule string
+}
+
+type OutletConfig struct {
+	Code              string
+	Timezone          string
+	TaxRate           float64
+	CashDenominations string
+}
+
 func Load() (*Config, error) {
 	// Load .env file if exists
 	_ = godotenv.Load()
@@ -62,6 +171,75 @@ func Load() (*Config, error) {
 		App: AppConfig{
 			Environment: getEnv("APP_ENV", "development"),
 		},
+		Outlet: OutletConfig{
+			Code:              getEnv("OUTLET_CODE", "main"),
+			Timezone:          getEnv("OUTLET_TIMEZONE", "Asia/Jakarta"),
+			TaxRate:           getEnvAsFloat("TAX_RATE_PERCENT", 0),
+			CashDenominations: getEnv("CASH_DENOMINATIONS", "100000,50000,20000,10000,5000,2000,1000,500,200,100"),
+		},
+		Wallet: WalletConfig{
+			GiftCardValidityDays: getEnvAsInt("GIFT_CARD_VALIDITY_DAYS", 365),
+		},
+		Loyalty: LoyaltyConfig{
+			PointsExpiryDays: getEnvAsInt("LOYALTY_POINTS_EXPIRY_DAYS", 365),
+		},
+		Tip: TipConfig{
+			SplitRule: getEnv("TIP_SPLIT_RULE", "equal"),
+		},
+		Idempotency: IdempotencyConfig{
+			KeyTTLHours: getEnvAsInt("IDEMPOTENCY_KEY_TTL_HOURS", 24),
+		},
+		QRIS: QRISConfig{
+			AcquirerGUI:      getEnv("QRIS_ACQUIRER_GUI", ""),
+			MerchantPAN:      getEnv("QRIS_MERCHANT_PAN", ""),
+			MerchantID:       getEnv("QRIS_MERCHANT_ID", ""),
+			NMID:             getEnv("QRIS_NMID", ""),
+			MerchantCriteria: getEnv("QRIS_MERCHANT_CRITERIA", "UMI"),
+			MCC:              getEnv("QRIS_MCC", "7542"),
+			MerchantName:     getEnv("QRIS_MERCHANT_NAME", ""),
+			MerchantCity:     getEnv("QRIS_MERCHANT_CITY", ""),
+			PostalCode:       getEnv("QRIS_POSTAL_CODE", ""),
+			TerminalID:       getEnv("QRIS_TERMINAL_ID", ""),
+		},
+		Gateway: GatewayConfig{
+			FakeSecret: getEnv("FAKE_GATEWAY_SECRET", ""),
+		},
+		Mail: MailConfig{
+			Transport:        getEnv("MAIL_TRANSPORT", "file"),
+			FileDir:          getEnv("MAIL_FILE_DIR", "storage/mail"),
+			SMTPHost:         getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:         getEnvAsInt("SMTP_PORT", 1025),
+			SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+			TimeoutSeconds:   getEnvAsInt("SMTP_TIMEOUT_SECONDS", 10),
+			FromAddress:      getEnv("MAIL_FROM_ADDRESS", "no-reply@flashlight.local"),
+			FromName:         getEnv("MAIL_FROM_NAME", "Flashlight"),
+			AutoSendEReceipt: getEnvAsBool("ERECEIPT_AUTO_SEND", true),
+		},
+		Jobs: JobsConfig{
+			Enabled:                           getEnvAsBool("JOBS_ENABLED", true),
+			PaymentExpiryIntervalSeconds:      getEnvAsInt("PAYMENT_EXPIRY_INTERVAL_SECONDS", 60),
+			PendingPaymentTTLMinutes:          getEnvAsInt("PENDING_PAYMENT_TTL_MINUTES", 60),
+			InvoiceGenerationIntervalMinutes:  getEnvAsInt("INVOICE_GENERATION_INTERVAL_MINUTES", 60),
+			GiftCardExpiryIntervalMinutes:     getEnvAsInt("GIFT_CARD_EXPIRY_INTERVAL_MINUTES", 60),
+			LoyaltyExpiryIntervalMinutes:      getEnvAsInt("LOYALTY_EXPIRY_INTERVAL_MINUTES", 60),
+			IdempotencyCleanupIntervalMinutes: getEnvAsInt("IDEMPOTENCY_CLEANUP_INTERVAL_MINUTES", 60),
+		},
+		Invoice: InvoiceConfig{
+			PaymentInstructions: getEnv("INVOICE_PAYMENT_INSTRUCTIONS", ""),
+		},
+		Settlement: SettlementConfig{
+			LayoutsFile: getEnv("SETTLEMENT_LAYOUTS_FILE", ""),
+		},
+		Receipt: ReceiptConfig{
+			OutletName: getEnv("RECEIPT_OUTLET_NAME", "Flashlight"),
+			Address:    getEnv("RECEIPT_ADDRESS", ""),
+			Phone:      getEnv("RECEIPT_PHONE", ""),
+			TaxID:      getEnv("RECEIPT_TAX_ID", ""),
+			Footer:     getEnv("RECEIPT_FOOTER", "Terima kasih atas kunjungan Anda"),
+			Paper:      getEnv("RECEIPT_PAPER", "80mm"),
+			QRURL:      getEnv("RECEIPT_QR_URL", ""),
+		},
 	}
 
 	return config, nil
@@ -74,6 +252,34 @@ func (c *DatabaseConfig) DSN() string {
 	)
 }
 
+// Location returns the outlet's time zone, used for anything that depends on
+// the local wall clock such as happy-hour pricing.
+func (c *OutletConfig) Location() (*time.Location, error) {
+	return time.LoadLocation(c.Timezone)
+}
+
+// Denominations parses the comma-separated notes and coins the drawer is
+// counted in at shift close, from largest to smallest.
+func (c *OutletConfig) Denominations() ([]float64, error) {
+	var denominations []float64
+	for _, value := range strings.Split(c.CashDenominations, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		denomination, err := strconv.ParseFloat(value, 64)
+		if err != nil || denomination <= 0 {
+			return nil, fmt.Errorf("invalid cash denomination %q", value)
+		}
+		denominations = append(denominations, denomination)
+	}
+	if len(denominations) == 0 {
+		return nil, fmt.Errorf("no cash denominations configured")
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(denominations)))
+	return denominations, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -89,3 +295,33 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// splitLines splits a configured multi-line text into its lines, which are
+// separated by "|". Blank lines are dropped.
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "|") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -6,11 +6,15 @@ toolchain go1.24.4
 
 require (
 	github.com/gin-gonic/gin v1.10.0
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/joho/godotenv v1.5.1
+	github.com/mattn/go-sqlite3 v1.14.15
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	golang.org/x/crypto v0.23.0
 	gorm.io/datatypes v1.2.0
 	gorm.io/driver/postgres v1.5.4
+	gorm.io/driver/sqlite v1.4.3
 	gorm.io/gorm v1.25.5
 )
 
@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"flashlight-go/config"
 	"flashlight-go/internal/models"
@@ -29,6 +30,12 @@ func Connect(cfg *config.Config) (*gorm.DB, error) {
 func Migrate(db *gorm.DB) error {
 	log.Println("Running database migrations...")
 
+	// Stored idempotent responses used to be jsonb, which cannot hold images
+	// or plain text. Postgres does not cast jsonb to bytea by itself
+	if err := convertIdempotencyResponses(db); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
 	err := db.AutoMigrate(
 		&models.User{},
 		&models.MembershipType{},
@@ -37,20 +44,69 @@ func Migrate(db *gorm.DB) error {
 		&models.CustomerVehicle{},
 		&models.ProductCategory{},
 		&models.Product{},
+		&models.ProductPrice{},
+		&models.PricingRule{},
+		&models.VehiclePriceTier{},
+		&models.Promo{},
 		&models.WorkOrder{},
 		&models.WorkOrderItem{},
 		&models.Payment{},
 		&models.Shift{},
+		&models.ShiftCashCount{},
+		&models.ShiftHandover{},
+		&models.GiftCard{},
+		&models.StoredValueEntry{},
+		&models.LoyaltyEarnRule{},
+		&models.LoyaltyReward{},
+		&models.LoyaltyPointEntry{},
+		&models.TipAllocation{},
+		&models.IdempotencyKey{},
+		&models.PaymentWebhookEvent{},
+		&models.UsedApprovalToken{},
+		&models.Refund{},
+		&models.ReceiptDelivery{},
+		&models.CashMovement{},
+		&models.SettlementBatch{},
+		&models.SettlementLine{},
+		&models.PaymentMethodSetting{},
+		&models.FleetAccount{},
+		&models.FleetDriver{},
+		&models.Invoice{},
+		&models.InvoiceLine{},
+		&models.AccountPayment{},
+		&models.InvoiceAllocation{},
 	)
 
 	if err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
 
+	// Payments from before fees were tracked keep all they brought in as net
+	err = db.Exec("UPDATE payments SET net_amount = amount_paid - change_amount - fee_amount WHERE net_amount = 0 AND amount_paid - change_amount - fee_amount <> 0").Error
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
 	log.Println("Database migrations completed successfully")
 	return nil
 }
 
+func convertIdempotencyResponses(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.IdempotencyKey{}) {
+		return nil
+	}
+	columns, err := db.Migrator().ColumnTypes(&models.IdempotencyKey{})
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if column.Name() == "response_body" && strings.EqualFold(column.DatabaseTypeName(), "jsonb") {
+			return db.Exec("ALTER TABLE idempotency_keys ALTER COLUMN response_body TYPE bytea USING convert_to(response_body::text, 'UTF8')").Error
+		}
+	}
+	return nil
+}
+
 func CreateIndexes(db *gorm.DB) error {
 	log.Println("Creating database indexes...")
 
@@ -68,6 +124,16 @@ func CreateIndexes(db *gorm.DB) error {
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_payments_work_order_id ON payments(work_order_id)")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_payments_payment_number ON payments(payment_number)")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_payments_shift_id ON payments(shift_id)")
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_payments_reference_number ON payments(reference_number)")
+
+	// Settlement indexes
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_settlement_lines_layout_date ON settlement_lines(layout, settlement_date)")
+
+	// Stored value indexes
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_stored_value_entries_account ON stored_value_entries(account_type, account_id)")
+
+	// Loyalty indexes
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_loyalty_point_entries_customer_type ON loyalty_point_entries(customer_user_id, entry_type)")
 
 	// Customer Vehicles indexes
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_customer_vehicles_customer_id_license ON customer_vehicles(customer_id, license_plate)")
@@ -75,6 +141,9 @@ func CreateIndexes(db *gorm.DB) error {
 	// Products indexes
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_products_category_kind_active ON products(category_id, kind, is_active)")
 
+	// Product Prices indexes
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_product_prices_product_effective ON product_prices(product_id, effective_from)")
+
 	log.Println("Database indexes created successfully")
 	return nil
 }
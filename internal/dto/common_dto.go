@@ -17,26 +17,28 @@ type UpdateMembershipTypeRequest struct {
 }
 
 type CreateProductCategoryRequest struct {
-	Name      string  `json:"name" binding:"required"`
-	IconImage *string `json:"icon_image"`
-	IsActive  *bool   `json:"is_active"`
+	Name                 string   `json:"name" binding:"required"`
+	IconImage            *string  `json:"icon_image"`
+	IsActive             *bool    `json:"is_active"`
+	MinDepositPercentage *float64 `json:"min_deposit_percentage,omitempty" binding:"omitempty,gte=0,lte=100"`
 }
 
 type UpdateProductCategoryRequest struct {
-	Name      *string `json:"name"`
-	IconImage *string `json:"icon_image"`
-	IsActive  *bool   `json:"is_active"`
+	Name                 *string  `json:"name"`
+	IconImage            *string  `json:"icon_image"`
+	IsActive             *bool    `json:"is_active"`
+	MinDepositPercentage *float64 `json:"min_deposit_percentage,omitempty" binding:"omitempty,gte=0,lte=100"`
 }
 
 type CreateProductRequest struct {
-	Name        string   `json:"name" binding:"required"`
-	Description *string  `json:"description"`
-	Price       float64  `json:"price" binding:"required,gt=0"`
-	Image       *string  `json:"image"`
-	CategoryID  uint     `json:"category_id" binding:"required"`
-	Kind        string   `json:"kind" binding:"required,oneof=service addon retail"`
-	IsActive    *bool    `json:"is_active"`
-	IsPremium   *bool    `json:"is_premium"`
+	Name        string  `json:"name" binding:"required"`
+	Description *string `json:"description"`
+	Price       float64 `json:"price" binding:"required,gt=0"`
+	Image       *string `json:"image"`
+	CategoryID  uint    `json:"category_id" binding:"required"`
+	Kind        string  `json:"kind" binding:"required,oneof=service addon retail"`
+	IsActive    *bool   `json:"is_active"`
+	IsPremium   *bool   `json:"is_premium"`
 }
 
 type UpdateProductRequest struct {
@@ -75,25 +77,157 @@ type UpdateCustomerVehicleRequest struct {
 
 type CreatePaymentRequest struct {
 	WorkOrderID     uint        `json:"work_order_id" binding:"required"`
-	Method          string      `json:"method" binding:"required,oneof=cash qris transfer e_wallet"`
+	Method          string      `json:"method" binding:"required,max=20"`
 	AmountPaid      float64     `json:"amount_paid" binding:"required,gt=0"`
+	TipAmount       float64     `json:"tip_amount" binding:"gte=0"`
+	ReferenceNumber *string     `json:"reference_number"`
+	RawPayload      interface{} `json:"raw_payload"`
+}
+
+type CheckoutTenderRequest struct {
+	Method          string      `json:"method" binding:"required,max=20"`
+	Amount          float64     `json:"amount" binding:"required,gt=0"`
+	TipAmount       float64     `json:"tip_amount" binding:"gte=0"`
 	ReferenceNumber *string     `json:"reference_number"`
 	RawPayload      interface{} `json:"raw_payload"`
 }
 
+type CheckoutRequest struct {
+	WorkOrderID uint                    `json:"work_order_id" binding:"required"`
+	Tenders     []CheckoutTenderRequest `json:"tenders" binding:"required,min=1,dive"`
+}
+
+type CreateQRISPaymentRequest struct {
+	WorkOrderID uint    `json:"work_order_id" binding:"required"`
+	TipAmount   float64 `json:"tip_amount" binding:"gte=0"`
+}
+
+type CreatePaymentMethodRequest struct {
+	Code              string  `json:"code" binding:"required,max=20"`
+	DisplayName       string  `json:"display_name" binding:"required,max=100"`
+	IsEnabled         *bool   `json:"is_enabled"`
+	RequiresReference bool    `json:"requires_reference"`
+	AllowsChange      bool    `json:"allows_change"`
+	FeePercentage     float64 `json:"fee_percentage" binding:"gte=0,lte=100"`
+	FeeFixed          float64 `json:"fee_fixed" binding:"gte=0"`
+	PendingTTLMinutes *int    `json:"pending_ttl_minutes" binding:"omitempty,gte=1"`
+	SortOrder         int     `json:"sort_order"`
+}
+
+type UpdatePaymentMethodRequest struct {
+	DisplayName       *string  `json:"display_name" binding:"omitempty,max=100"`
+	IsEnabled         *bool    `json:"is_enabled"`
+	RequiresReference *bool    `json:"requires_reference"`
+	AllowsChange      *bool    `json:"allows_change"`
+	FeePercentage     *float64 `json:"fee_percentage" binding:"omitempty,gte=0,lte=100"`
+	FeeFixed          *float64 `json:"fee_fixed" binding:"omitempty,gte=0"`
+	PendingTTLMinutes *int     `json:"pending_ttl_minutes" binding:"omitempty,gte=0"`
+	SortOrder         *int     `json:"sort_order"`
+}
+
 type UpdatePaymentRequest struct {
-	Status          *string     `json:"status,omitempty" binding:"omitempty,oneof=pending completed failed refunded"`
+	Status          *string     `json:"status,omitempty" binding:"omitempty,oneof=pending completed failed"`
 	ReferenceNumber *string     `json:"reference_number"`
 	RawPayload      interface{} `json:"raw_payload"`
 }
 
+type CreateRefundRequest struct {
+	Amount          float64 `json:"amount" binding:"required,gt=0"`
+	Reason          string  `json:"reason" binding:"required"`
+	Method          string  `json:"method" binding:"required,max=20"`
+	ReferenceNumber *string `json:"reference_number"`
+}
+
+// SupervisorApprovalRequest carries an owner's or admin's approval, either
+// their user ID and supervisor PIN or an approval token they issued.
+type SupervisorApprovalRequest struct {
+	SupervisorUserID *uint   `json:"supervisor_user_id"`
+	SupervisorPIN    *string `json:"supervisor_pin"`
+	SupervisorToken  *string `json:"supervisor_token"`
+}
+
+type SetSupervisorPINRequest struct {
+	PIN      string `json:"pin" binding:"required,numeric,min=6,max=12"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ApprovalTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type VoidPaymentRequest struct {
+	Reason string `json:"reason" binding:"required"`
+	SupervisorApprovalRequest
+}
+
+// CreateCashMovementRequest records cash put into or taken out of a drawer.
+// Without a shift ID the requester's own active shift is used.
+type CreateCashMovementRequest struct {
+	Type     string  `json:"type" binding:"required,oneof=pay_in pay_out safe_drop"`
+	Category string  `json:"category" binding:"max=50"`
+	Amount   float64 `json:"amount" binding:"required,gt=0"`
+	Note     *string `json:"note"`
+	ShiftID  *uint   `json:"shift_id"`
+	SupervisorApprovalRequest
+}
+
+type ResendReceiptRequest struct {
+	Email *string `json:"email" binding:"omitempty,email"`
+}
+
+// CreateShiftRequest opens a shift. A cashier taking over a drawer starts
+// with the handover they received and the float they counted, which becomes
+// the shift's initial cash.
 type CreateShiftRequest struct {
-	InitialCash  float64 `json:"initial_cash"`
-	ReceivedFrom *string `json:"received_from"`
+	InitialCash    float64  `json:"initial_cash" binding:"gte=0"`
+	HandoverID     *uint    `json:"handover_id"`
+	ReceivedAmount *float64 `json:"received_amount" binding:"omitempty,gte=0"`
 }
 
+// CloseShiftRequest is the blind count of the drawer at the end of a shift:
+// how many of each note and coin are in it. The expected amount is worked
+// out on the server and is not shown to the cashier beforehand.
 type CloseShiftRequest struct {
-	FinalCash float64 `json:"final_cash" binding:"required"`
+	CashCounts   []CashCountRequest    `json:"cash_counts" binding:"required,min=1,dive"`
+	VarianceNote *string               `json:"variance_note"`
+	Handover     *ShiftHandoverRequest `json:"handover"`
+}
+
+// ShiftHandoverRequest passes part of the counted drawer on to the next
+// cashier. Without an amount the whole counted drawer is handed over.
+type ShiftHandoverRequest struct {
+	ToUserID uint     `json:"to_user_id" binding:"required"`
+	Amount   *float64 `json:"amount" binding:"omitempty,gte=0"`
+	Note     *string  `json:"note"`
+}
+
+// ActiveShiftResponse is a cashier's own shift while its drawer is still to
+// be counted blind. Payments, sales and tip totals, the opening float and
+// the handover amounts are all left out, since the expected drawer could be
+// worked out from them.
+type ActiveShiftResponse struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	StartTime time.Time `json:"start_time"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PendingHandoverResponse is a handover waiting for the receiving cashier.
+// The amount handed over is left out so the float is counted blind.
+type PendingHandoverResponse struct {
+	ID           uint      `json:"id"`
+	FromShiftID  uint      `json:"from_shift_id"`
+	FromUserID   uint      `json:"from_user_id"`
+	FromUserName string    `json:"from_user_name"`
+	Note         *string   `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CashCountRequest struct {
+	Denomination float64 `json:"denomination" binding:"required,gt=0"`
+	Quantity     int     `json:"quantity" binding:"gte=0"`
 }
 
 type CreateDeviceFCMTokenRequest struct {
@@ -0,0 +1,88 @@
+package dto
+
+type CreateFleetAccountRequest struct {
+	Name            string  `json:"name" binding:"required,max=255"`
+	ContactName     *string `json:"contact_name" binding:"omitempty,max=255"`
+	BillingEmail    *string `json:"billing_email" binding:"omitempty,email"`
+	PhoneNumber     *string `json:"phone_number" binding:"omitempty,max=50"`
+	BillingAddress  *string `json:"billing_address"`
+	TaxID           *string `json:"tax_id" binding:"omitempty,max=50"`
+	CreditLimit     float64 `json:"credit_limit" binding:"gte=0"`
+	PaymentTermDays *int    `json:"payment_term_days" binding:"omitempty,gte=0,lte=365"`
+}
+
+type UpdateFleetAccountRequest struct {
+	Name            *string  `json:"name" binding:"omitempty,max=255"`
+	ContactName     *string  `json:"contact_name" binding:"omitempty,max=255"`
+	BillingEmail    *string  `json:"billing_email" binding:"omitempty,email"`
+	PhoneNumber     *string  `json:"phone_number" binding:"omitempty,max=50"`
+	BillingAddress  *string  `json:"billing_address"`
+	TaxID           *string  `json:"tax_id" binding:"omitempty,max=50"`
+	CreditLimit     *float64 `json:"credit_limit" binding:"omitempty,gte=0"`
+	PaymentTermDays *int     `json:"payment_term_days" binding:"omitempty,gte=0,lte=365"`
+	IsActive        *bool    `json:"is_active"`
+}
+
+type AddFleetVehicleRequest struct {
+	CustomerVehicleID uint `json:"customer_vehicle_id" binding:"required"`
+}
+
+type AddFleetDriverRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// GenerateInvoicesRequest runs the monthly invoicing for a month formatted
+// YYYY-MM.
+type GenerateInvoicesRequest struct {
+	Period string `json:"period" binding:"required,len=7"`
+}
+
+type InvoiceAllocationRequest struct {
+	InvoiceID uint    `json:"invoice_id" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// ReceiveAccountPaymentRequest records money received from a fleet account.
+// Without allocations it is applied to the oldest invoices first.
+type ReceiveAccountPaymentRequest struct {
+	Method          string                     `json:"method" binding:"required,max=20"`
+	Amount          float64                    `json:"amount" binding:"required,gt=0"`
+	ReferenceNumber *string                    `json:"reference_number"`
+	ReceivedDate    string                     `json:"received_date"`
+	Note            *string                    `json:"note"`
+	Allocations     []InvoiceAllocationRequest `json:"allocations" binding:"omitempty,dive"`
+}
+
+// FleetAccountBalance is what a fleet account owes.
+type FleetAccountBalance struct {
+	Unbilled float64 `json:"unbilled"`
+	Invoiced float64 `json:"invoiced"`
+	Credit   float64 `json:"credit"`
+	Total    float64 `json:"total"`
+}
+
+// ARAgingBuckets splits what is owed on invoices by how many days the
+// invoices are past their due date.
+type ARAgingBuckets struct {
+	Current       float64 `json:"current"`
+	Days1To30     float64 `json:"days_1_30"`
+	Days31To60    float64 `json:"days_31_60"`
+	Days61To90    float64 `json:"days_61_90"`
+	Over90        float64 `json:"over_90"`
+	TotalInvoiced float64 `json:"total_invoiced"`
+	Unbilled      float64 `json:"unbilled"`
+	Credit        float64 `json:"credit"`
+}
+
+// ARAgingLine is the aging of one fleet account.
+type ARAgingLine struct {
+	FleetAccountID   uint   `json:"fleet_account_id"`
+	FleetAccountName string `json:"fleet_account_name"`
+	ARAgingBuckets
+}
+
+type ARAgingResponse struct {
+	AsOf     string         `json:"as_of"`
+	Accounts []ARAgingLine  `json:"accounts"`
+	Totals   ARAgingBuckets `json:"totals"`
+}
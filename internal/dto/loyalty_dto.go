@@ -0,0 +1,59 @@
+package dto
+
+import "time"
+
+type CreateLoyaltyEarnRuleRequest struct {
+	CategoryID       *uint   `json:"category_id"`
+	MembershipTypeID *uint   `json:"membership_type_id"`
+	AmountPerPoint   float64 `json:"amount_per_point" binding:"required,gt=0"`
+	IsActive         *bool   `json:"is_active"`
+}
+
+type UpdateLoyaltyEarnRuleRequest struct {
+	CategoryID       *uint    `json:"category_id"`
+	MembershipTypeID *uint    `json:"membership_type_id"`
+	AmountPerPoint   *float64 `json:"amount_per_point,omitempty" binding:"omitempty,gt=0"`
+	IsActive         *bool    `json:"is_active"`
+}
+
+type CreateLoyaltyRewardRequest struct {
+	Name           string  `json:"name" binding:"required"`
+	PointsCost     int     `json:"points_cost" binding:"required,gt=0"`
+	RewardType     string  `json:"reward_type" binding:"required,oneof=discount free_product"`
+	DiscountAmount float64 `json:"discount_amount" binding:"gte=0"`
+	ProductID      *uint   `json:"product_id"`
+	IsActive       *bool   `json:"is_active"`
+}
+
+type UpdateLoyaltyRewardRequest struct {
+	Name           *string  `json:"name"`
+	PointsCost     *int     `json:"points_cost,omitempty" binding:"omitempty,gt=0"`
+	RewardType     *string  `json:"reward_type,omitempty" binding:"omitempty,oneof=discount free_product"`
+	DiscountAmount *float64 `json:"discount_amount,omitempty" binding:"omitempty,gte=0"`
+	ProductID      *uint    `json:"product_id"`
+	IsActive       *bool    `json:"is_active"`
+}
+
+type RedeemLoyaltyRewardRequest struct {
+	WorkOrderID uint `json:"work_order_id" binding:"required"`
+	RewardID    uint `json:"reward_id" binding:"required"`
+}
+
+type LoyaltyBalanceResponse struct {
+	CustomerUserID uint `json:"customer_user_id"`
+	Points         int  `json:"points"`
+}
+
+type LoyaltyPointEntryResponse struct {
+	ID              uint       `json:"id"`
+	CustomerUserID  uint       `json:"customer_user_id"`
+	EntryType       string     `json:"entry_type"`
+	Points          int        `json:"points"`
+	BalanceAfter    int        `json:"balance_after"`
+	WorkOrderID     *uint      `json:"work_order_id"`
+	RewardID        *uint      `json:"reward_id"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	Note            *string    `json:"note"`
+	CreatedByUserID *uint      `json:"created_by_user_id"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
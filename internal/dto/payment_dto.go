@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+type QRISPaymentResponse struct {
+	PaymentID     uint      `json:"payment_id"`
+	PaymentNumber string    `json:"payment_number"`
+	WorkOrderID   uint      `json:"work_order_id"`
+	ShiftID       *uint     `json:"shift_id"`
+	Status        string    `json:"status"`
+	AmountPaid    float64   `json:"amount_paid"`
+	TipAmount     float64   `json:"tip_amount"`
+	Amount        float64   `json:"amount"`
+	QRString      string    `json:"qr_string"`
+	QRImage       string    `json:"qr_image"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PaymentWebhookResult struct {
+	EventID       string `json:"event_id"`
+	Status        string `json:"status,omitempty"`
+	Duplicate     bool   `json:"duplicate"`
+	PaymentID     *uint  `json:"payment_id,omitempty"`
+	PaymentStatus string `json:"payment_status,omitempty"`
+}
+
+// PaymentMethodRevenueLine totals the completed payments of one method.
+type PaymentMethodRevenueLine struct {
+	Method       string  `json:"method"`
+	DisplayName  string  `json:"display_name"`
+	PaymentCount int64   `json:"payment_count"`
+	GrossAmount  float64 `json:"gross_amount"`
+	TipAmount    float64 `json:"tip_amount"`
+	FeeAmount    float64 `json:"fee_amount"`
+	RefundAmount float64 `json:"refund_amount"`
+	NetAmount    float64 `json:"net_amount"`
+}
+
+type PaymentMethodRevenueResponse struct {
+	From         string                     `json:"from"`
+	To           string                     `json:"to"`
+	Methods      []PaymentMethodRevenueLine `json:"methods"`
+	GrossAmount  float64                    `json:"gross_amount"`
+	TipAmount    float64                    `json:"tip_amount"`
+	FeeAmount    float64                    `json:"fee_amount"`
+	RefundAmount float64                    `json:"refund_amount"`
+	NetAmount    float64                    `json:"net_amount"`
+}
@@ -0,0 +1,114 @@
+package dto
+
+import "time"
+
+type CreatePricingRuleRequest struct {
+	Name            string  `json:"name" binding:"required"`
+	DaysOfWeek      []int   `json:"days_of_week" binding:"required,min=1,dive,min=0,max=6"`
+	StartTime       string  `json:"start_time" binding:"required"`
+	EndTime         string  `json:"end_time" binding:"required"`
+	ProductID       *uint   `json:"product_id"`
+	CategoryID      *uint   `json:"category_id"`
+	AdjustmentType  string  `json:"adjustment_type" binding:"required,oneof=percentage fixed"`
+	AdjustmentValue float64 `json:"adjustment_value" binding:"required"`
+	Priority        int     `json:"priority"`
+	IsActive        *bool   `json:"is_active"`
+}
+
+type UpdatePricingRuleRequest struct {
+	Name            *string  `json:"name"`
+	DaysOfWeek      []int    `json:"days_of_week,omitempty" binding:"omitempty,min=1,dive,min=0,max=6"`
+	StartTime       *string  `json:"start_time"`
+	EndTime         *string  `json:"end_time"`
+	ProductID       *uint    `json:"product_id"`
+	CategoryID      *uint    `json:"category_id"`
+	AdjustmentType  *string  `json:"adjustment_type,omitempty" binding:"omitempty,oneof=percentage fixed"`
+	AdjustmentValue *float64 `json:"adjustment_value"`
+	Priority        *int     `json:"priority"`
+	IsActive        *bool    `json:"is_active"`
+}
+
+type PricingRuleResponse struct {
+	ID              uint      `json:"id"`
+	Name            string    `json:"name"`
+	DaysOfWeek      []int     `json:"days_of_week"`
+	StartTime       string    `json:"start_time"`
+	EndTime         string    `json:"end_time"`
+	ProductID       *uint     `json:"product_id"`
+	CategoryID      *uint     `json:"category_id"`
+	AdjustmentType  string    `json:"adjustment_type"`
+	AdjustmentValue float64   `json:"adjustment_value"`
+	Priority        int       `json:"priority"`
+	IsActive        bool      `json:"is_active"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type CreateVehiclePriceTierRequest struct {
+	VehicleType     string  `json:"vehicle_type" binding:"required,max=50"`
+	ProductID       *uint   `json:"product_id"`
+	CategoryID      *uint   `json:"category_id"`
+	AdjustmentType  string  `json:"adjustment_type" binding:"required,oneof=percentage fixed"`
+	AdjustmentValue float64 `json:"adjustment_value" binding:"required"`
+	IsActive        *bool   `json:"is_active"`
+}
+
+type UpdateVehiclePriceTierRequest struct {
+	VehicleType     *string  `json:"vehicle_type" binding:"omitempty,max=50"`
+	ProductID       *uint    `json:"product_id"`
+	CategoryID      *uint    `json:"category_id"`
+	AdjustmentType  *string  `json:"adjustment_type,omitempty" binding:"omitempty,oneof=percentage fixed"`
+	AdjustmentValue *float64 `json:"adjustment_value"`
+	IsActive        *bool    `json:"is_active"`
+}
+
+type VehiclePriceTierResponse struct {
+	ID              uint      `json:"id"`
+	VehicleType     string    `json:"vehicle_type"`
+	ProductID       *uint     `json:"product_id"`
+	CategoryID      *uint     `json:"category_id"`
+	AdjustmentType  string    `json:"adjustment_type"`
+	AdjustmentValue float64   `json:"adjustment_value"`
+	IsActive        bool      `json:"is_active"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type CreatePromoRequest struct {
+	Code          string     `json:"code" binding:"required,max=50"`
+	Name          string     `json:"name" binding:"required"`
+	DiscountType  string     `json:"discount_type" binding:"required,oneof=percentage fixed"`
+	DiscountValue float64    `json:"discount_value" binding:"required,gt=0"`
+	MinSubtotal   float64    `json:"min_subtotal" binding:"gte=0"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	MaxUses       *int       `json:"max_uses" binding:"omitempty,gte=1"`
+	IsActive      *bool      `json:"is_active"`
+}
+
+type UpdatePromoRequest struct {
+	Name          *string    `json:"name"`
+	DiscountType  *string    `json:"discount_type,omitempty" binding:"omitempty,oneof=percentage fixed"`
+	DiscountValue *float64   `json:"discount_value" binding:"omitempty,gt=0"`
+	MinSubtotal   *float64   `json:"min_subtotal" binding:"omitempty,gte=0"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	MaxUses       *int       `json:"max_uses" binding:"omitempty,gte=1"`
+	IsActive      *bool      `json:"is_active"`
+}
+
+type PromoResponse struct {
+	ID            uint       `json:"id"`
+	Code          string     `json:"code"`
+	Name          string     `json:"name"`
+	DiscountType  string     `json:"discount_type"`
+	DiscountValue float64    `json:"discount_value"`
+	MinSubtotal   float64    `json:"min_subtotal"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	MaxUses       *int       `json:"max_uses"`
+	UsedCount     int        `json:"used_count"`
+	IsActive      bool       `json:"is_active"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
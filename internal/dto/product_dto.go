@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type SchedulePriceChangeRequest struct {
+	Price         float64 `json:"price" binding:"required,gt=0"`
+	EffectiveFrom *string `json:"effective_from"`
+	Note          *string `json:"note"`
+}
+
+type ProductPriceResponse struct {
+	ID              uint      `json:"id"`
+	ProductID       uint      `json:"product_id"`
+	Price           float64   `json:"price"`
+	EffectiveFrom   time.Time `json:"effective_from"`
+	Status          string    `json:"status"`
+	CreatedByUserID *uint     `json:"created_by_user_id"`
+	Note            *string   `json:"note"`
+	CreatedAt       time.Time `json:"created_at"`
+}
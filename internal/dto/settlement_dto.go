@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// SettlementReportLine is a settlement line that needs a look.
+type SettlementReportLine struct {
+	LineID           uint     `json:"line_id"`
+	BatchID          uint     `json:"batch_id"`
+	RowNumber        int      `json:"row_number"`
+	Reference        string   `json:"reference"`
+	GrossAmount      float64  `json:"gross_amount"`
+	MDRAmount        float64  `json:"mdr_amount"`
+	NetAmount        float64  `json:"net_amount"`
+	PaymentID        *uint    `json:"payment_id"`
+	PaymentNumber    *string  `json:"payment_number"`
+	ExpectedAmount   *float64 `json:"expected_amount"`
+	DifferenceAmount float64  `json:"difference_amount"`
+	Note             *string  `json:"note"`
+}
+
+// SettlementMissingPayment is a payment that should have been settled but
+// is in no settlement file.
+type SettlementMissingPayment struct {
+	PaymentID       uint       `json:"payment_id"`
+	PaymentNumber   string     `json:"payment_number"`
+	WorkOrderID     uint       `json:"work_order_id"`
+	Method          string     `json:"method"`
+	ReferenceNumber *string    `json:"reference_number"`
+	Amount          float64    `json:"amount"`
+	PaidAt          *time.Time `json:"paid_at"`
+}
+
+// SettlementReport reconciles what an acquirer settled on one date against
+// the payments taken.
+type SettlementReport struct {
+	Layout         string `json:"layout"`
+	SettlementDate string `json:"settlement_date"`
+	// TransactionDate is the day whose payments were due on SettlementDate.
+	TransactionDate  string                     `json:"transaction_date"`
+	LineCount        int                        `json:"line_count"`
+	GrossAmount      float64                    `json:"gross_amount"`
+	MDRAmount        float64                    `json:"mdr_amount"`
+	NetAmount        float64                    `json:"net_amount"`
+	MatchedCount     int                        `json:"matched_count"`
+	MatchedAmount    float64                    `json:"matched_amount"`
+	MissingAmount    float64                    `json:"missing_amount"`
+	Unmatched        []SettlementReportLine     `json:"unmatched"`
+	AmountMismatches []SettlementReportLine     `json:"amount_mismatches"`
+	Duplicates       []SettlementReportLine     `json:"duplicates"`
+	Missing          []SettlementMissingPayment `json:"missing"`
+}
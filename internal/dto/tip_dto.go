@@ -0,0 +1,17 @@
+package dto
+
+type StaffEarningsLine struct {
+	StaffUserID    uint    `json:"staff_user_id"`
+	StaffName      string  `json:"staff_name"`
+	ItemCount      int64   `json:"item_count"`
+	ServiceRevenue float64 `json:"service_revenue"`
+	Tips           float64 `json:"tips"`
+}
+
+type StaffEarningsResponse struct {
+	From           string              `json:"from"`
+	To             string              `json:"to"`
+	Staff          []StaffEarningsLine `json:"staff"`
+	UnassignedTips float64             `json:"unassigned_tips"`
+	TotalTips      float64             `json:"total_tips"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+import "time"
+
+type TopUpWalletRequest struct {
+	Amount          float64 `json:"amount" binding:"required,gt=0"`
+	Method          string  `json:"method" binding:"required"`
+	ReferenceNumber *string `json:"reference_number"`
+	Note            *string `json:"note"`
+}
+
+type IssueGiftCardRequest struct {
+	Amount          float64 `json:"amount" binding:"required,gt=0"`
+	Method          string  `json:"method" binding:"required"`
+	ExpiresAt       *string `json:"expires_at"`
+	ReferenceNumber *string `json:"reference_number"`
+}
+
+type RedeemGiftCardRequest struct {
+	Code           string `json:"code" binding:"required"`
+	CustomerUserID *uint  `json:"customer_user_id"`
+}
+
+type WalletBalanceResponse struct {
+	CustomerUserID uint    `json:"customer_user_id"`
+	Balance        float64 `json:"balance"`
+}
+
+type StoredValueEntryResponse struct {
+	ID              uint      `json:"id"`
+	AccountType     string    `json:"account_type"`
+	AccountID       uint      `json:"account_id"`
+	EntryType       string    `json:"entry_type"`
+	Amount          float64   `json:"amount"`
+	BalanceAfter    float64   `json:"balance_after"`
+	PaymentID       *uint     `json:"payment_id"`
+	GiftCardID      *uint     `json:"gift_card_id"`
+	TenderMethod    *string   `json:"tender_method"`
+	ShiftID         *uint     `json:"shift_id"`
+	ReferenceNumber *string   `json:"reference_number"`
+	Note            *string   `json:"note"`
+	CreatedByUserID *uint     `json:"created_by_user_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type GiftCardResponse struct {
+	ID               uint       `json:"id"`
+	Code             string     `json:"code"`
+	InitialAmount    float64    `json:"initial_amount"`
+	Balance          float64    `json:"balance"`
+	Status           string     `json:"status"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	RedeemedByUserID *uint      `json:"redeemed_by_user_id"`
+	RedeemedAt       *time.Time `json:"redeemed_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
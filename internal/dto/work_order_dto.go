@@ -3,12 +3,13 @@ package dto
 import "time"
 
 type CreateWorkOrderRequest struct {
-	Source              string                     `json:"source" binding:"required,oneof=kiosk cashier online"`
-	Type                string                     `json:"type" binding:"required,oneof=service retail mix"`
-	CustomerUserID      *uint                      `json:"customer_user_id"`
-	CustomerVehicleID   *uint                      `json:"customer_vehicle_id"`
-	Notes               *string                    `json:"notes"`
-	SpecialInstructions *string                    `json:"special_instructions"`
+	Source              string                       `json:"source" binding:"required,oneof=kiosk cashier online"`
+	Type                string                       `json:"type" binding:"required,oneof=service retail mix"`
+	CustomerUserID      *uint                        `json:"customer_user_id"`
+	CustomerVehicleID   *uint                        `json:"customer_vehicle_id"`
+	PromoCode           *string                      `json:"promo_code"`
+	Notes               *string                      `json:"notes"`
+	SpecialInstructions *string                      `json:"special_instructions"`
 	Items               []CreateWorkOrderItemRequest `json:"items" binding:"required,min=1"`
 }
 
@@ -19,12 +20,19 @@ type CreateWorkOrderItemRequest struct {
 	ItemNote            *string `json:"item_note"`
 }
 
+type QuoteWorkOrderRequest struct {
+	CustomerUserID    *uint                        `json:"customer_user_id"`
+	CustomerVehicleID *uint                        `json:"customer_vehicle_id"`
+	PromoCode         *string                      `json:"promo_code"`
+	Items             []CreateWorkOrderItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
 type UpdateWorkOrderRequest struct {
-	Status              *string `json:"status,omitempty" binding:"omitempty,oneof=pending confirmed in_progress ready completed cancelled"`
-	CashierUserID       *uint   `json:"cashier_user_id"`
-	ShiftID             *uint   `json:"shift_id"`
-	Notes               *string `json:"notes"`
-	SpecialInstructions *string `json:"special_instructions"`
+	Status              *string  `json:"status,omitempty" binding:"omitempty,oneof=pending confirmed in_progress ready completed cancelled"`
+	CashierUserID       *uint    `json:"cashier_user_id"`
+	ShiftID             *uint    `json:"shift_id"`
+	Notes               *string  `json:"notes"`
+	SpecialInstructions *string  `json:"special_instructions"`
 	DiscountAmount      *float64 `json:"discount_amount"`
 	TaxAmount           *float64 `json:"tax_amount"`
 }
@@ -49,21 +57,59 @@ type WorkOrderResponse struct {
 	DiscountAmount      float64                 `json:"discount_amount"`
 	TaxAmount           float64                 `json:"tax_amount"`
 	TotalAmount         float64                 `json:"total_amount"`
+	DepositRequired     float64                 `json:"deposit_required"`
+	PromoID             *uint                   `json:"promo_id"`
+	PaidAmount          float64                 `json:"paid_amount"`
+	RefundedAmount      float64                 `json:"refunded_amount"`
+	NetPaid             float64                 `json:"net_paid"`
+	OutstandingAmount   float64                 `json:"outstanding_amount"`
+	PaymentStatus       string                  `json:"payment_status"`
 	Items               []WorkOrderItemResponse `json:"items,omitempty"`
 	CreatedAt           time.Time               `json:"created_at"`
 	UpdatedAt           time.Time               `json:"updated_at"`
 }
 
 type WorkOrderItemResponse struct {
-	ID                  uint      `json:"id"`
-	WorkOrderID         uint      `json:"work_order_id"`
-	ProductID           uint      `json:"product_id"`
-	ProductNameSnapshot string    `json:"product_name_snapshot"`
-	PriceSnapshot       float64   `json:"price_snapshot"`
-	Quantity            int       `json:"quantity"`
-	Subtotal            float64   `json:"subtotal"`
-	AssignedStaffUserID *uint     `json:"assigned_staff_user_id"`
-	ItemNote            *string   `json:"item_note"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
+	ID                      uint      `json:"id"`
+	WorkOrderID             uint      `json:"work_order_id"`
+	ProductID               uint      `json:"product_id"`
+	ProductNameSnapshot     string    `json:"product_name_snapshot"`
+	BasePriceSnapshot       float64   `json:"base_price_snapshot"`
+	PriceSnapshot           float64   `json:"price_snapshot"`
+	PricingRuleID           *uint     `json:"pricing_rule_id"`
+	PricingRuleNameSnapshot *string   `json:"pricing_rule_name_snapshot"`
+	VehiclePriceTierID      *uint     `json:"vehicle_price_tier_id"`
+	Quantity                int       `json:"quantity"`
+	Subtotal                float64   `json:"subtotal"`
+	AssignedStaffUserID     *uint     `json:"assigned_staff_user_id"`
+	ItemNote                *string   `json:"item_note"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+type WorkOrderQuoteResponse struct {
+	Items               []WorkOrderQuoteItemResponse `json:"items"`
+	Subtotal            float64                      `json:"subtotal"`
+	DiscountAmount      float64                      `json:"discount_amount"`
+	DiscountDescription *string                      `json:"discount_description"`
+	PromoCode           *string                      `json:"promo_code"`
+	PromoDiscountAmount float64                      `json:"promo_discount_amount"`
+	VehicleType         *string                      `json:"vehicle_type"`
+	TaxRate             float64                      `json:"tax_rate"`
+	TaxAmount           float64                      `json:"tax_amount"`
+	TotalAmount         float64                      `json:"total_amount"`
+	DepositRequired     float64                      `json:"deposit_required"`
+	PricedAt            time.Time                    `json:"priced_at"`
+}
+
+type WorkOrderQuoteItemResponse struct {
+	ProductID       uint    `json:"product_id"`
+	ProductName     string  `json:"product_name"`
+	BasePrice       float64 `json:"base_price"`
+	UnitPrice       float64 `json:"unit_price"`
+	PricingRuleID   *uint   `json:"pricing_rule_id"`
+	PricingRuleName *string `json:"pricing_rule_name"`
+	VehicleTierID   *uint   `json:"vehicle_price_tier_id"`
+	Quantity        int     `json:"quantity"`
+	Subtotal        float64 `json:"subtotal"`
 }
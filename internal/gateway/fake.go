@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakePayload is the webhook body of the fake provider.
+type FakePayload struct {
+	EventID       string  `json:"event_id"`
+	PaymentNumber string  `json:"payment_number"`
+	TransactionID string  `json:"transaction_id"`
+	Status        string  `json:"status"`
+	Amount        float64 `json:"amount"`
+}
+
+// FakeAdapter is a stand-in provider for local development and testing.
+// Deliveries are signed with a hex HMAC-SHA256 of the body in the
+// X-Fake-Signature header.
+type FakeAdapter struct {
+	secret []byte
+}
+
+func NewFakeAdapter(secret string) *FakeAdapter {
+	return &FakeAdapter{secret: []byte(secret)}
+}
+
+func (a *FakeAdapter) Name() string {
+	return "fake"
+}
+
+// Sign returns the signature the fake provider sends with a body.
+func (a *FakeAdapter) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (a *FakeAdapter) Verify(header http.Header, body []byte) error {
+	signature, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write(body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (a *FakeAdapter) Parse(body []byte) (*Notification, error) {
+	var payload FakePayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.EventID == "" || payload.PaymentNumber == "" {
+		return nil, errors.New("event_id and payment_number are required")
+	}
+
+	var status NotificationStatus
+	switch payload.Status {
+	case "paid", "settlement":
+		status = NotificationPaid
+	case "failed", "expired", "cancelled":
+		status = NotificationFailed
+	default:
+		return nil, errors.New("unsupported status " + payload.Status)
+	}
+
+	return &Notification{
+		EventID:           payload.EventID,
+		PaymentNumber:     payload.PaymentNumber,
+		ProviderReference: payload.TransactionID,
+		Status:            status,
+		Amount:            payload.Amount,
+	}, nil
+}
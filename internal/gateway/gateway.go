@@ -0,0 +1,72 @@
+// Package gateway turns payment provider webhooks into notifications the
+// payment service understands. Each provider gets an Adapter that verifies
+// the delivery's signature and maps the provider's payload.
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+var (
+	ErrUnknownProvider  = errors.New("unknown payment provider")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+type NotificationStatus string
+
+const (
+	NotificationPaid   NotificationStatus = "paid"
+	NotificationFailed NotificationStatus = "failed"
+)
+
+// Notification is a provider-neutral report about one payment.
+type Notification struct {
+	// EventID identifies the delivery at the provider; redeliveries of the
+	// same event carry the same ID.
+	EventID string
+	// PaymentNumber is our payment number, sent to the provider as the
+	// transaction reference.
+	PaymentNumber string
+	// ProviderReference is the provider's own transaction ID.
+	ProviderReference string
+	Status            NotificationStatus
+	Amount            float64
+}
+
+// Adapter handles the webhooks of one provider.
+type Adapter interface {
+	// Name is the provider key used in the webhook URL.
+	Name() string
+	// Verify checks that the delivery was signed by the provider.
+	Verify(header http.Header, body []byte) error
+	// Parse maps a verified delivery to a notification.
+	Parse(body []byte) (*Notification, error)
+}
+
+// Registry holds the adapters of all enabled providers.
+type Registry struct {
+	mu       sync.RWMutex
+	adapters map[string]Adapter
+}
+
+func NewRegistry() *Registry {
+	return &Registry{adapters: make(map[string]Adapter)}
+}
+
+func (r *Registry) Register(adapter Adapter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adapters[adapter.Name()] = adapter
+}
+
+func (r *Registry) Get(name string) (Adapter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	adapter, ok := r.adapters[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return adapter, nil
+}
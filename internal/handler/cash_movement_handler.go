@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CashMovementHandler struct {
+	cashMovementService *service.CashMovementService
+	shiftService        *service.ShiftService
+	supervisorService   *service.SupervisorService
+}
+
+func NewCashMovementHandler(cashMovementService *service.CashMovementService, shiftService *service.ShiftService, supervisorService *service.SupervisorService) *CashMovementHandler {
+	return &CashMovementHandler{
+		cashMovementService: cashMovementService,
+		shiftService:        shiftService,
+		supervisorService:   supervisorService,
+	}
+}
+
+func (h *CashMovementHandler) Create(c *gin.Context) {
+	var req dto.CreateCashMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	approval, err := h.supervisorService.Approve(c.Request.Context(), currentUserID(c), req.SupervisorApprovalRequest)
+	if err != nil {
+		if errors.Is(err, service.ErrApprovalLocked) {
+			c.JSON(http.StatusTooManyRequests, dto.ErrorResponse("Supervisor approval failed", err))
+			return
+		}
+		c.JSON(http.StatusForbidden, dto.ErrorResponse("Supervisor approval failed", err))
+		return
+	}
+
+	movement, err := h.cashMovementService.Create(c.Request.Context(), req, currentUserID(c), approval)
+	if err != nil {
+		if errors.Is(err, service.ErrNoActiveShift) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse("No active shift", err))
+			return
+		}
+		if errors.Is(err, service.ErrInvalidApproval) {
+			c.JSON(http.StatusForbidden, dto.ErrorResponse("Supervisor approval failed", err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to record cash movement", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Cash movement recorded successfully", movement))
+}
+
+func (h *CashMovementHandler) GetByShift(c *gin.Context) {
+	shiftID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid shift ID", err))
+		return
+	}
+
+	shift, err := h.shiftService.GetByID(c.Request.Context(), uint(shiftID))
+	if err != nil || !canAccessShift(c, shift) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Shift not found", errors.New("shift not found")))
+		return
+	}
+	// Pay-outs and safe drops would give the blind count away
+	if countsBlind(c, shift) {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse("Insufficient permissions", errors.New("cash movements are shown once the shift is closed")))
+		return
+	}
+
+	movements, err := h.cashMovementService.GetByShift(c.Request.Context(), uint(shiftID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve cash movements", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Cash movements retrieved successfully", movements))
+}
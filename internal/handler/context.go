@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID returns the ID of the authenticated user, or nil when the
+// request is not authenticated.
+func currentUserID(c *gin.Context) *uint {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return nil
+	}
+	uid := userID.(uint)
+	return &uid
+}
+
+// currentUserRole returns the role of the authenticated user.
+func currentUserRole(c *gin.Context) string {
+	role, _ := c.Get("user_role")
+	roleName, _ := role.(string)
+	return roleName
+}
+
+// canAccessCustomer reports whether the caller may see data belonging to the
+// given customer. Customers can only see their own; staff roles see everyone.
+func canAccessCustomer(c *gin.Context, customerID uint) bool {
+	if currentUserRole(c) != "customer" {
+		return true
+	}
+	userID := currentUserID(c)
+	return userID != nil && *userID == customerID
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FleetAccountHandler struct {
+	fleetAccountService *service.FleetAccountService
+}
+
+func NewFleetAccountHandler(fleetAccountService *service.FleetAccountService) *FleetAccountHandler {
+	return &FleetAccountHandler{fleetAccountService: fleetAccountService}
+}
+
+func (h *FleetAccountHandler) Create(c *gin.Context) {
+	var req dto.CreateFleetAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	account, err := h.fleetAccountService.Create(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to create fleet account", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Fleet account created successfully", account))
+}
+
+func (h *FleetAccountHandler) GetAll(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	accounts, meta, err := h.fleetAccountService.GetAll(c.Request.Context(), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve fleet accounts", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedSuccessResponse("Fleet accounts retrieved successfully", accounts, *meta))
+}
+
+func (h *FleetAccountHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	account, err := h.fleetAccountService.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Fleet account not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Fleet account retrieved successfully", account))
+}
+
+func (h *FleetAccountHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.UpdateFleetAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	account, err := h.fleetAccountService.Update(c.Request.Context(), uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to update fleet account", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Fleet account updated successfully", account))
+}
+
+func (h *FleetAccountHandler) AddVehicle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.AddFleetVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	vehicle, err := h.fleetAccountService.AddVehicle(c.Request.Context(), uint(id), req.CustomerVehicleID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to add vehicle", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Vehicle added successfully", vehicle))
+}
+
+func (h *FleetAccountHandler) RemoveVehicle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+	vehicleID, err := strconv.ParseUint(c.Param("vehicleId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid vehicle ID", err))
+		return
+	}
+
+	if err := h.fleetAccountService.RemoveVehicle(c.Request.Context(), uint(id), uint(vehicleID)); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to remove vehicle", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Vehicle removed successfully", nil))
+}
+
+func (h *FleetAccountHandler) AddDriver(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.AddFleetDriverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	driver, err := h.fleetAccountService.AddDriver(c.Request.Context(), uint(id), req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to add driver", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Driver added successfully", driver))
+}
+
+func (h *FleetAccountHandler) RemoveDriver(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid user ID", err))
+		return
+	}
+
+	if err := h.fleetAccountService.RemoveDriver(c.Request.Context(), uint(id), uint(userID)); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to remove driver", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Driver removed successfully", nil))
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler struct {
+	invoiceService *service.InvoiceService
+}
+
+func NewInvoiceHandler(invoiceService *service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{invoiceService: invoiceService}
+}
+
+// Generate invoices the fleet accounts for a month ahead of the background
+// job, e.g. after correcting charges.
+func (h *InvoiceHandler) Generate(c *gin.Context) {
+	var req dto.GenerateInvoicesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	invoices, err := h.invoiceService.Generate(c.Request.Context(), req.Period, currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to generate invoices", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Invoices generated successfully", invoices))
+}
+
+// GetAll lists invoices, optionally of one fleet account or with one status.
+func (h *InvoiceHandler) GetAll(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	var accountID *uint
+	if value := c.Query("fleet_account_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid fleet account ID", err))
+			return
+		}
+		uid := uint(id)
+		accountID = &uid
+	}
+
+	invoices, meta, err := h.invoiceService.GetAll(c.Request.Context(), page, perPage, accountID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve invoices", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedSuccessResponse("Invoices retrieved successfully", invoices, *meta))
+}
+
+func (h *InvoiceHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	invoice, err := h.invoiceService.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Invoice not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Invoice retrieved successfully", invoice))
+}
+
+func (h *InvoiceHandler) PDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	data, invoice, err := h.invoiceService.PDF(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Failed to render invoice", err))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%s.pdf", invoice.InvoiceNumber))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// ReceivePayment records money received from a fleet account against its
+// invoices.
+func (h *InvoiceHandler) ReceivePayment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.ReceiveAccountPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	payment, err := h.invoiceService.ReceivePayment(c.Request.Context(), uint(id), req, currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to record payment", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Payment recorded successfully", payment))
+}
+
+func (h *InvoiceHandler) GetPayments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	payments, err := h.invoiceService.GetPayments(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve payments", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Payments retrieved successfully", payments))
+}
+
+// GetAgingReport shows what fleet accounts owe, by how long it is overdue.
+func (h *InvoiceHandler) GetAgingReport(c *gin.Context) {
+	report, err := h.invoiceService.GetAging(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to build accounts receivable report", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Accounts receivable report retrieved successfully", report))
+}
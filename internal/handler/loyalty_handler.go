@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoyaltyHandler struct {
+	loyaltyService *service.LoyaltyService
+}
+
+func NewLoyaltyHandler(loyaltyService *service.LoyaltyService) *LoyaltyHandler {
+	return &LoyaltyHandler{loyaltyService: loyaltyService}
+}
+
+func (h *LoyaltyHandler) GetBalance(c *gin.Context) {
+	customerID, err := strconv.ParseUint(c.Param("customerId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid customer ID", err))
+		return
+	}
+
+	if !canAccessCustomer(c, uint(customerID)) {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse("Insufficient permissions", errors.New("customers can only view their own points")))
+		return
+	}
+
+	balance, err := h.loyaltyService.GetBalance(c.Request.Context(), uint(customerID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Customer not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Loyalty balance retrieved successfully", balance))
+}
+
+func (h *LoyaltyHandler) GetHistory(c *gin.Context) {
+	customerID, err := strconv.ParseUint(c.Param("customerId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid customer ID", err))
+		return
+	}
+
+	if !canAccessCustomer(c, uint(customerID)) {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse("Insufficient permissions", errors.New("customers can only view their own points")))
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	entries, meta, err := h.loyaltyService.GetHistory(c.Request.Context(), uint(customerID), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve points history", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedSuccessResponse("Points history retrieved successfully", entries, *meta))
+}
+
+func (h *LoyaltyHandler) Redeem(c *gin.Context) {
+	var req dto.RedeemLoyaltyRewardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	// Customers may only spend points on their own orders
+	userID := currentUserID(c)
+	var customerOnly *uint
+	if currentUserRole(c) == "customer" {
+		customerOnly = userID
+	}
+
+	entry, err := h.loyaltyService.Redeem(c.Request.Context(), req, userID, customerOnly)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to redeem reward", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Reward redeemed successfully", entry))
+}
+
+func (h *LoyaltyHandler) GetActiveRewards(c *gin.Context) {
+	rewards, err := h.loyaltyService.GetRewards(c.Request.Context(), true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve rewards", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Rewards retrieved successfully", rewards))
+}
+
+func (h *LoyaltyHandler) GetAllRewards(c *gin.Context) {
+	rewards, err := h.loyaltyService.GetRewards(c.Request.Context(), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve rewards", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Rewards retrieved successfully", rewards))
+}
+
+func (h *LoyaltyHandler) CreateReward(c *gin.Context) {
+	var req dto.CreateLoyaltyRewardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	reward, err := h.loyaltyService.CreateReward(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to create reward", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Reward created successfully", reward))
+}
+
+func (h *LoyaltyHandler) UpdateReward(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.UpdateLoyaltyRewardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	reward, err := h.loyaltyService.UpdateReward(c.Request.Context(), uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to update reward", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Reward updated successfully", reward))
+}
+
+func (h *LoyaltyHandler) DeleteReward(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	if err := h.loyaltyService.DeleteReward(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to delete reward", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Reward deleted successfully", nil))
+}
+
+func (h *LoyaltyHandler) GetEarnRules(c *gin.Context) {
+	rules, err := h.loyaltyService.GetEarnRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve earn rules", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Earn rules retrieved successfully", rules))
+}
+
+func (h *LoyaltyHandler) CreateEarnRule(c *gin.Context) {
+	var req dto.CreateLoyaltyEarnRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	rule, err := h.loyaltyService.CreateEarnRule(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to create earn rule", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Earn rule created successfully", rule))
+}
+
+func (h *LoyaltyHandler) UpdateEarnRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.UpdateLoyaltyEarnRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	rule, err := h.loyaltyService.UpdateEarnRule(c.Request.Context(), uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to update earn rule", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Earn rule updated successfully", rule))
+}
+
+func (h *LoyaltyHandler) DeleteEarnRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	if err := h.loyaltyService.DeleteEarnRule(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to delete earn rule", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Earn rule deleted successfully", nil))
+}
+
+func (h *LoyaltyHandler) ExpirePoints(c *gin.Context) {
+	checked, err := h.loyaltyService.ExpireAll(c.Request.Context(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to expire points", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Expired points written off successfully", gin.H{"customers_checked": checked}))
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentService    *service.PaymentService
+	supervisorService *service.SupervisorService
+}
+
+func NewPaymentHandler(paymentService *service.PaymentService, supervisorService *service.SupervisorService) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService:    paymentService,
+		supervisorService: supervisorService,
+	}
+}
+
+func (h *PaymentHandler) Create(c *gin.Context) {
+	var req dto.CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	payment, err := h.paymentService.Create(c.Request.Context(), req, currentUserID(c))
+	if err != nil {
+		if errors.Is(err, service.ErrNoActiveShift) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse("No active shift", err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to create payment", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Payment created successfully", payment))
+}
+
+func (h *PaymentHandler) Checkout(c *gin.Context) {
+	var req dto.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	result, err := h.paymentService.Checkout(c.Request.Context(), req, currentUserID(c))
+	if err != nil {
+		if errors.Is(err, service.ErrNoActiveShift) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse("No active shift", err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to check out work order", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Checkout completed successfully", result))
+}
+
+func (h *PaymentHandler) CreateQRIS(c *gin.Context) {
+	var req dto.CreateQRISPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	qris, err := h.paymentService.CreateQRIS(c.Request.Context(), req, currentUserID(c))
+	if err != nil {
+		if errors.Is(err, service.ErrNoActiveShift) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse("No active shift", err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to create QRIS payment", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("QRIS payment created successfully", qris))
+}
+
+func (h *PaymentHandler) GetQRISImage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	image, err := h.paymentService.GetQRISImage(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("QRIS code not found", err))
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", image)
+}
+
+func (h *PaymentHandler) GetAll(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	payments, meta, err := h.paymentService.GetAll(c.Request.Context(), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve payments", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedSuccessResponse("Payments retrieved successfully", payments, *meta))
+}
+
+func (h *PaymentHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	payment, err := h.paymentService.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Payment not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Payment retrieved successfully", payment))
+}
+
+func (h *PaymentHandler) GetByWorkOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	payments, err := h.paymentService.GetByWorkOrder(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve payments", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Payments retrieved successfully", payments))
+}
+
+func (h *PaymentHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.UpdatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	payment, err := h.paymentService.Update(c.Request.Context(), uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to update payment", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Payment updated successfully", payment))
+}
+
+func (h *PaymentHandler) Void(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.VoidPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	approval, err := h.supervisorService.Approve(c.Request.Context(), currentUserID(c), req.SupervisorApprovalRequest)
+	if err != nil {
+		if errors.Is(err, service.ErrApprovalLocked) {
+			c.JSON(http.StatusTooManyRequests, dto.ErrorResponse("Supervisor approval failed", err))
+			return
+		}
+		c.JSON(http.StatusForbidden, dto.ErrorResponse("Supervisor approval failed", err))
+		return
+	}
+
+	payment, err := h.paymentService.Void(c.Request.Context(), uint(id), req.Reason, currentUserID(c), approval)
+	if err != nil {
+		// The approval token was used up by another request meanwhile
+		if errors.Is(err, service.ErrInvalidApproval) {
+			c.JSON(http.StatusForbidden, dto.ErrorResponse("Supervisor approval failed", err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to void payment", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Payment voided successfully", payment))
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentMethodHandler struct {
+	paymentMethodService *service.PaymentMethodService
+}
+
+func NewPaymentMethodHandler(paymentMethodService *service.PaymentMethodService) *PaymentMethodHandler {
+	return &PaymentMethodHandler{paymentMethodService: paymentMethodService}
+}
+
+// GetAvailable lists the methods the outlet accepts, for the register.
+func (h *PaymentMethodHandler) GetAvailable(c *gin.Context) {
+	methods, err := h.paymentMethodService.GetAvailable(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve payment methods", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Payment methods retrieved successfully", methods))
+}
+
+func (h *PaymentMethodHandler) GetAll(c *gin.Context) {
+	methods, err := h.paymentMethodService.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve payment methods", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Payment methods retrieved successfully", methods))
+}
+
+func (h *PaymentMethodHandler) Create(c *gin.Context) {
+	var req dto.CreatePaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	method, err := h.paymentMethodService.Create(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to create payment method", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Payment method created successfully", method))
+}
+
+func (h *PaymentMethodHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.UpdatePaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	method, err := h.paymentMethodService.Update(c.Request.Context(), uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to update payment method", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Payment method updated successfully", method))
+}
+
+func (h *PaymentMethodHandler) GetRevenueReport(c *gin.Context) {
+	report, err := h.paymentMethodService.GetRevenueReport(c.Request.Context(), c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to build payment method revenue report", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Payment method revenue retrieved successfully", report))
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/gateway"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBodySize caps how much of a webhook delivery is read.
+const maxWebhookBodySize = 1 << 20
+
+type PaymentWebhookHandler struct {
+	webhookService *service.PaymentWebhookService
+}
+
+func NewPaymentWebhookHandler(webhookService *service.PaymentWebhookService) *PaymentWebhookHandler {
+	return &PaymentWebhookHandler{webhookService: webhookService}
+}
+
+func (h *PaymentWebhookHandler) Receive(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to read webhook body", err))
+		return
+	}
+
+	result, err := h.webhookService.Handle(c.Request.Context(), c.Param("provider"), c.Request.Header, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, gateway.ErrUnknownProvider):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse("Unknown payment provider", err))
+		case errors.Is(err, gateway.ErrInvalidSignature):
+			c.JSON(http.StatusUnauthorized, dto.ErrorResponse("Invalid signature", err))
+		default:
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to process webhook", err))
+		}
+		return
+	}
+
+	if result.Duplicate {
+		c.JSON(http.StatusOK, dto.SuccessResponse("Webhook already processed", result))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Webhook processed successfully", result))
+}
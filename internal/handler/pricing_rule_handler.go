@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingRuleHandler struct {
+	pricingService *service.PricingService
+}
+
+func NewPricingRuleHandler(pricingService *service.PricingService) *PricingRuleHandler {
+	return &PricingRuleHandler{pricingService: pricingService}
+}
+
+func (h *PricingRuleHandler) Create(c *gin.Context) {
+	var req dto.CreatePricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	rule, err := h.pricingService.CreateRule(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to create pricing rule", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Pricing rule created successfully", rule))
+}
+
+func (h *PricingRuleHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	rule, err := h.pricingService.GetRuleByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Pricing rule not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Pricing rule retrieved successfully", rule))
+}
+
+func (h *PricingRuleHandler) GetAll(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	rules, meta, err := h.pricingService.GetAllRules(c.Request.Context(), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve pricing rules", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedSuccessResponse("Pricing rules retrieved successfully", rules, *meta))
+}
+
+func (h *PricingRuleHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.UpdatePricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	rule, err := h.pricingService.UpdateRule(c.Request.Context(), uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to update pricing rule", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Pricing rule updated successfully", rule))
+}
+
+func (h *PricingRuleHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	if err := h.pricingService.DeleteRule(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to delete pricing rule", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Pricing rule deleted successfully", nil))
+}
+
+func (h *PricingRuleHandler) CreateVehicleTier(c *gin.Context) {
+	var req dto.CreateVehiclePriceTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	tier, err := h.pricingService.CreateVehicleTier(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to create vehicle price tier", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Vehicle price tier created successfully", tier))
+}
+
+func (h *PricingRuleHandler) GetVehicleTierByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	tier, err := h.pricingService.GetVehicleTierByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Vehicle price tier not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Vehicle price tier retrieved successfully", tier))
+}
+
+func (h *PricingRuleHandler) GetAllVehicleTiers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	tiers, meta, err := h.pricingService.GetAllVehicleTiers(c.Request.Context(), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve vehicle price tiers", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedSuccessResponse("Vehicle price tiers retrieved successfully", tiers, *meta))
+}
+
+func (h *PricingRuleHandler) UpdateVehicleTier(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.UpdateVehiclePriceTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	tier, err := h.pricingService.UpdateVehicleTier(c.Request.Context(), uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to update vehicle price tier", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Vehicle price tier updated successfully", tier))
+}
+
+func (h *PricingRuleHandler) DeleteVehicleTier(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	if err := h.pricingService.DeleteVehicleTier(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to delete vehicle price tier", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Vehicle price tier deleted successfully", nil))
+}
+
+func (h *PricingRuleHandler) CreatePromo(c *gin.Context) {
+	var req dto.CreatePromoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	promo, err := h.pricingService.CreatePromo(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to create promo", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Promo created successfully", promo))
+}
+
+func (h *PricingRuleHandler) GetPromoByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	promo, err := h.pricingService.GetPromoByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Promo not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Promo retrieved successfully", promo))
+}
+
+func (h *PricingRuleHandler) GetAllPromos(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	promos, meta, err := h.pricingService.GetAllPromos(c.Request.Context(), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve promos", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedSuccessResponse("Promos retrieved successfully", promos, *meta))
+}
+
+func (h *PricingRuleHandler) UpdatePromo(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.UpdatePromoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	promo, err := h.pricingService.UpdatePromo(c.Request.Context(), uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to update promo", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Promo updated successfully", promo))
+}
+
+func (h *PricingRuleHandler) DeletePromo(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	if err := h.pricingService.DeletePromo(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to delete promo", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Promo deleted successfully", nil))
+}
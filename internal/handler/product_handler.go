@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductHandler struct {
+	productService *service.ProductService
+}
+
+func NewProductHandler(productService *service.ProductService) *ProductHandler {
+	return &ProductHandler{productService: productService}
+}
+
+func (h *ProductHandler) GetPriceHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	prices, err := h.productService.GetPriceHistory(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Product not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Price history retrieved successfully", prices))
+}
+
+func (h *ProductHandler) SchedulePriceChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.SchedulePriceChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	price, err := h.productService.SchedulePriceChange(c.Request.Context(), uint(id), req, currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to schedule price change", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Price change scheduled successfully", price))
+}
+
+func (h *ProductHandler) CancelPriceChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	priceID, err := strconv.ParseUint(c.Param("priceId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid price ID", err))
+		return
+	}
+
+	if err := h.productService.CancelPriceChange(c.Request.Context(), uint(id), uint(priceID)); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to cancel price change", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Price change cancelled successfully", nil))
+}
+
+func (h *ProductHandler) GetCategories(c *gin.Context) {
+	categories, err := h.productService.GetCategories(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve product categories", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Product categories retrieved successfully", categories))
+}
+
+func (h *ProductHandler) UpdateCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.UpdateProductCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	category, err := h.productService.UpdateCategory(c.Request.Context(), uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to update product category", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Product category updated successfully", category))
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/receipt"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReceiptHandler struct {
+	receiptService  *service.ReceiptService
+	deliveryService *service.ReceiptDeliveryService
+}
+
+func NewReceiptHandler(receiptService *service.ReceiptService, deliveryService *service.ReceiptDeliveryService) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptService:  receiptService,
+		deliveryService: deliveryService,
+	}
+}
+
+func (h *ReceiptHandler) GetReceipt(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	paper := c.Query("paper")
+
+	var data []byte
+	var contentType, extension string
+	switch format := c.DefaultQuery("format", "pdf"); format {
+	case "pdf":
+		data, err = h.receiptService.PDF(c.Request.Context(), uint(id), paper)
+		contentType, extension = "application/pdf", "pdf"
+	case "escpos":
+		openDrawer := c.DefaultQuery("open_drawer", "true") != "false"
+		data, err = h.receiptService.ESCPOS(c.Request.Context(), uint(id), paper, openDrawer)
+		contentType, extension = "application/octet-stream", "bin"
+	default:
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid format", fmt.Errorf("unsupported receipt format %q", format)))
+		return
+	}
+	if err != nil {
+		if errors.Is(err, receipt.ErrUnsupportedPaper) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid paper size", err))
+			return
+		}
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Failed to render receipt", err))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=receipt-%d.%s", id, extension))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func (h *ReceiptHandler) Resend(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	// The body is optional; without it the receipt goes to the customer
+	var req dto.ResendReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	delivery, err := h.deliveryService.Resend(c.Request.Context(), uint(id), req.Email, currentUserID(c))
+	if err != nil {
+		if delivery != nil {
+			c.JSON(http.StatusBadGateway, dto.ErrorResponse("Failed to send receipt", err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to send receipt", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Receipt sent successfully", delivery))
+}
+
+func (h *ReceiptHandler) GetDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	deliveries, err := h.deliveryService.GetByWorkOrder(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve receipt deliveries", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Receipt deliveries retrieved successfully", deliveries))
+}
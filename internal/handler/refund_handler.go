@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RefundHandler struct {
+	refundService *service.RefundService
+}
+
+func NewRefundHandler(refundService *service.RefundService) *RefundHandler {
+	return &RefundHandler{refundService: refundService}
+}
+
+func (h *RefundHandler) Create(c *gin.Context) {
+	paymentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid payment ID", err))
+		return
+	}
+
+	var req dto.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	// Only owners and admins reach this handler; they approve their own refunds
+	refund, err := h.refundService.Create(c.Request.Context(), uint(paymentID), req, currentUserID(c))
+	if err != nil {
+		if errors.Is(err, service.ErrNoActiveShift) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse("No active shift", err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to create refund", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Refund created successfully", refund))
+}
+
+func (h *RefundHandler) GetByPayment(c *gin.Context) {
+	paymentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid payment ID", err))
+		return
+	}
+
+	refunds, err := h.refundService.GetByPayment(c.Request.Context(), uint(paymentID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve refunds", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Refunds retrieved successfully", refunds))
+}
+
+func (h *RefundHandler) GetByWorkOrder(c *gin.Context) {
+	workOrderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	refunds, err := h.refundService.GetByWorkOrder(c.Request.Context(), uint(workOrderID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve refunds", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Refunds retrieved successfully", refunds))
+}
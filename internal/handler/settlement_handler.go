@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSettlementFileSize caps uploaded settlement files at 10 MB.
+const maxSettlementFileSize = 10 << 20
+
+type SettlementHandler struct {
+	settlementService *service.SettlementService
+}
+
+func NewSettlementHandler(settlementService *service.SettlementService) *SettlementHandler {
+	return &SettlementHandler{settlementService: settlementService}
+}
+
+// Import reads a settlement file uploaded as multipart form data, with the
+// layout name and, for layouts without a date column, the settlement date.
+func (h *SettlementHandler) Import(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+	if file.Size > maxSettlementFileSize {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", fmt.Errorf("settlement file is larger than %d MB", maxSettlementFileSize>>20)))
+		return
+	}
+	layout := c.PostForm("layout")
+	if layout == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", errors.New("layout is required")))
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	batch, reports, err := h.settlementService.Import(c.Request.Context(), layout, file.Filename, data, c.PostForm("settlement_date"), currentUserID(c))
+	if err != nil {
+		if errors.Is(err, service.ErrSettlementImported) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse("Settlement file already imported", err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to import settlement file", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Settlement file imported successfully", gin.H{"batch": batch, "reports": reports}))
+}
+
+func (h *SettlementHandler) GetLayouts(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.SuccessResponse("Settlement layouts retrieved successfully", h.settlementService.GetLayouts()))
+}
+
+func (h *SettlementHandler) GetReport(c *gin.Context) {
+	report, err := h.settlementService.GetReport(c.Request.Context(), c.Query("layout"), c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to build settlement report", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Settlement report retrieved successfully", report))
+}
+
+func (h *SettlementHandler) GetAll(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	batches, meta, err := h.settlementService.GetAll(c.Request.Context(), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve settlement batches", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedSuccessResponse("Settlement batches retrieved successfully", batches, *meta))
+}
+
+func (h *SettlementHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	batch, err := h.settlementService.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Settlement batch not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Settlement batch retrieved successfully", batch))
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShiftHandler struct {
+	shiftService *service.ShiftService
+}
+
+func NewShiftHandler(shiftService *service.ShiftService) *ShiftHandler {
+	return &ShiftHandler{shiftService: shiftService}
+}
+
+// Start opens a shift for the requester, acknowledging the drawer handed
+// over to them if there is one.
+func (h *ShiftHandler) Start(c *gin.Context) {
+	var req dto.CreateShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse("Unauthorized", errors.New("missing authenticated user")))
+		return
+	}
+
+	shift, err := h.shiftService.Start(c.Request.Context(), *userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to start shift", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Shift started successfully", blindShift(c, shift)))
+}
+
+// GetCurrent returns the requester's open shift.
+func (h *ShiftHandler) GetCurrent(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse("Unauthorized", errors.New("missing authenticated user")))
+		return
+	}
+
+	shift, err := h.shiftService.GetActiveByUser(c.Request.Context(), *userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve shift", err))
+		return
+	}
+	if shift == nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("No active shift", service.ErrNoActiveShift))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Shift retrieved successfully", blindShift(c, shift)))
+}
+
+func (h *ShiftHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	shift, err := h.shiftService.GetByID(c.Request.Context(), uint(id))
+	if err != nil || !canAccessShift(c, shift) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Shift not found", errors.New("shift not found")))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Shift retrieved successfully", blindShift(c, shift)))
+}
+
+func (h *ShiftHandler) GetSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	summary, err := h.shiftService.GetSummary(c.Request.Context(), uint(id))
+	if err != nil || !canAccessShift(c, summary["shift"].(*models.Shift)) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Shift not found", errors.New("shift not found")))
+		return
+	}
+
+	// The drawer is counted blind, so cashiers do not see what it should
+	// hold, or any total it could be worked out from, until their shift is
+	// closed
+	if shift := summary["shift"].(*models.Shift); countsBlind(c, shift) {
+		summary = map[string]interface{}{
+			"shift":        blindShift(c, shift),
+			"total_orders": summary["total_orders"],
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Shift summary retrieved successfully", summary))
+}
+
+// GetPendingHandovers lists the drawers handed over to the requester that
+// they still have to acknowledge.
+func (h *ShiftHandler) GetPendingHandovers(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse("Unauthorized", errors.New("missing authenticated user")))
+		return
+	}
+
+	handovers, err := h.shiftService.GetPendingHandovers(c.Request.Context(), *userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve handovers", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Handovers retrieved successfully", handovers))
+}
+
+// GetHandovers lists handovers, optionally only those with a status or
+// flagged with a discrepancy.
+func (h *ShiftHandler) GetHandovers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	flaggedOnly := c.Query("flagged") == "true"
+
+	handovers, meta, err := h.shiftService.GetHandovers(c.Request.Context(), page, perPage, c.Query("status"), flaggedOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve handovers", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedSuccessResponse("Handovers retrieved successfully", handovers, *meta))
+}
+
+// GetDenominations lists the notes and coins the drawer is counted in.
+func (h *ShiftHandler) GetDenominations(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.SuccessResponse("Cash denominations retrieved successfully", h.shiftService.Denominations()))
+}
+
+// Close ends a shift with a blind count of its drawer by denomination.
+func (h *ShiftHandler) Close(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", err))
+		return
+	}
+
+	var req dto.CloseShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	existing, err := h.shiftService.GetByID(c.Request.Context(), uint(id))
+	if err != nil || !canAccessShift(c, existing) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Shift not found", errors.New("shift not found")))
+		return
+	}
+
+	shift, err := h.shiftService.Close(c.Request.Context(), uint(id), req, currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to close shift", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Shift closed successfully", shift))
+}
+
+// canAccessShift reports whether the caller may see or close a shift.
+// Cashiers only have access to their own shifts; owners and admins to all.
+func canAccessShift(c *gin.Context, shift *models.Shift) bool {
+	if currentUserRole(c) != "cashier" {
+		return true
+	}
+	userID := currentUserID(c)
+	return userID != nil && *userID == shift.UserID
+}
+
+// countsBlind reports whether the caller is a cashier who still has to
+// count the drawer of a shift blind.
+func countsBlind(c *gin.Context, shift *models.Shift) bool {
+	return currentUserRole(c) == "cashier" && shift.Status == models.ShiftStatusActive
+}
+
+// blindShift returns the shift as the caller may see it: a cashier still
+// to count its drawer gets an ActiveShiftResponse instead.
+func blindShift(c *gin.Context, shift *models.Shift) interface{} {
+	if !countsBlind(c, shift) {
+		return shift
+	}
+
+	return dto.ActiveShiftResponse{
+		ID:        shift.ID,
+		UserID:    shift.UserID,
+		StartTime: shift.StartTime,
+		Status:    string(shift.Status),
+		CreatedAt: shift.CreatedAt,
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SupervisorHandler struct {
+	supervisorService *service.SupervisorService
+}
+
+func NewSupervisorHandler(supervisorService *service.SupervisorService) *SupervisorHandler {
+	return &SupervisorHandler{supervisorService: supervisorService}
+}
+
+// SetPIN sets the supervisor PIN of the signed-in owner or admin.
+func (h *SupervisorHandler) SetPIN(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse("Unauthorized", errors.New("user not authenticated")))
+		return
+	}
+
+	var req dto.SetSupervisorPINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	if err := h.supervisorService.SetPIN(c.Request.Context(), *userID, req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to set supervisor PIN", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Supervisor PIN set successfully", nil))
+}
+
+// IssueApprovalToken gives the signed-in owner or admin a short-lived token
+// to approve an operation on a cashier's register.
+func (h *SupervisorHandler) IssueApprovalToken(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse("Unauthorized", errors.New("user not authenticated")))
+		return
+	}
+
+	token, err := h.supervisorService.IssueApprovalToken(c.Request.Context(), *userID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse("Failed to issue approval token", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Approval token issued successfully", token))
+}
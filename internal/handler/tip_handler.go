@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TipHandler struct {
+	tipService *service.TipService
+}
+
+func NewTipHandler(tipService *service.TipService) *TipHandler {
+	return &TipHandler{tipService: tipService}
+}
+
+func (h *TipHandler) GetStaffEarnings(c *gin.Context) {
+	var staffUserID *uint
+	if staffID := c.Query("staff_user_id"); staffID != "" {
+		id, err := strconv.ParseUint(staffID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid staff user ID", err))
+			return
+		}
+		uid := uint(id)
+		staffUserID = &uid
+	}
+
+	// Staff only ever see their own earnings
+	if currentUserRole(c) == "staff" {
+		staffUserID = currentUserID(c)
+	}
+
+	report, err := h.tipService.GetStaffEarnings(c.Request.Context(), c.Query("from"), c.Query("to"), staffUserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to build staff earnings report", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Staff earnings retrieved successfully", report))
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WalletHandler struct {
+	walletService *service.WalletService
+}
+
+func NewWalletHandler(walletService *service.WalletService) *WalletHandler {
+	return &WalletHandler{walletService: walletService}
+}
+
+func (h *WalletHandler) GetBalance(c *gin.Context) {
+	customerID, err := strconv.ParseUint(c.Param("customerId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid customer ID", err))
+		return
+	}
+
+	if !canAccessCustomer(c, uint(customerID)) {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse("Insufficient permissions", errors.New("customers can only view their own wallet")))
+		return
+	}
+
+	balance, err := h.walletService.GetBalance(c.Request.Context(), uint(customerID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Wallet not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Wallet balance retrieved successfully", balance))
+}
+
+func (h *WalletHandler) GetEntries(c *gin.Context) {
+	customerID, err := strconv.ParseUint(c.Param("customerId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid customer ID", err))
+		return
+	}
+
+	if !canAccessCustomer(c, uint(customerID)) {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse("Insufficient permissions", errors.New("customers can only view their own wallet")))
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	entries, meta, err := h.walletService.GetEntries(c.Request.Context(), uint(customerID), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to retrieve wallet entries", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedSuccessResponse("Wallet entries retrieved successfully", entries, *meta))
+}
+
+func (h *WalletHandler) TopUp(c *gin.Context) {
+	customerID, err := strconv.ParseUint(c.Param("customerId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid customer ID", err))
+		return
+	}
+
+	var req dto.TopUpWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	entry, err := h.walletService.TopUp(c.Request.Context(), uint(customerID), req, currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to top up wallet", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Wallet topped up successfully", entry))
+}
+
+func (h *WalletHandler) IssueGiftCard(c *gin.Context) {
+	var req dto.IssueGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	card, err := h.walletService.IssueGiftCard(c.Request.Context(), req, currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to issue gift card", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse("Gift card issued successfully", card))
+}
+
+func (h *WalletHandler) GetGiftCard(c *gin.Context) {
+	card, err := h.walletService.GetGiftCard(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Gift card not found", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Gift card retrieved successfully", card))
+}
+
+func (h *WalletHandler) RedeemGiftCard(c *gin.Context) {
+	var req dto.RedeemGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	// Customers redeem into their own wallet; staff must say whose wallet
+	userID := currentUserID(c)
+	customerID := req.CustomerUserID
+	if currentUserRole(c) == "customer" {
+		customerID = userID
+	}
+	if customerID == nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", errors.New("customer_user_id is required")))
+		return
+	}
+
+	card, err := h.walletService.RedeemGiftCard(c.Request.Context(), req.Code, *customerID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to redeem gift card", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Gift card redeemed successfully", card))
+}
+
+func (h *WalletHandler) ExpireGiftCards(c *gin.Context) {
+	expired, err := h.walletService.ExpireGiftCards(c.Request.Context(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to expire gift cards", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Gift cards expired successfully", gin.H{"expired": expired}))
+}
@@ -41,6 +41,22 @@ func (h *WorkOrderHandler) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, dto.SuccessResponse("Work order created successfully", workOrder))
 }
 
+func (h *WorkOrderHandler) Quote(c *gin.Context) {
+	var req dto.QuoteWorkOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request", err))
+		return
+	}
+
+	quote, err := h.workOrderService.Quote(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to quote work order", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Work order quoted successfully", quote))
+}
+
 func (h *WorkOrderHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
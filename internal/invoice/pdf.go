@@ -0,0 +1,214 @@
+// Package invoice renders fleet account invoices as A4 PDF documents.
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"flashlight-go/internal/receipt"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Party is who an invoice is billed to.
+type Party struct {
+	Name    string
+	Contact string
+	Address string
+	TaxID   string
+}
+
+// Line is one order billed on the invoice.
+type Line struct {
+	Date         time.Time
+	OrderNumber  string
+	LicensePlate string
+	Driver       string
+	Amount       float64
+}
+
+// Invoice holds everything printed on an invoice.
+type Invoice struct {
+	Header    receipt.Header
+	Number    string
+	Period    string
+	IssueDate time.Time
+	DueDate   time.Time
+	BillTo    Party
+
+	Lines       []Line
+	TotalAmount float64
+	PaidAmount  float64
+
+	// PaymentInstructions are printed below the totals, e.g. the bank
+	// account to transfer to.
+	PaymentInstructions []string
+}
+
+// BalanceDue is what is left to pay on the invoice.
+func (inv *Invoice) BalanceDue() float64 {
+	return inv.TotalAmount - inv.PaidAmount
+}
+
+const (
+	pageWidth  = 210
+	margin     = 20
+	fontSize   = 9
+	titleSize  = 16
+	lineHeight = 5.5
+)
+
+// column widths of the line table, in millimetres, adding up to the width
+// between the margins
+var columns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"Date", 22, "L"},
+	{"Order", 44, "L"},
+	{"Vehicle", 28, "L"},
+	{"Driver", 46, "L"},
+	{"Amount", 30, "R"},
+}
+
+// PDF renders the invoice on A4 paper.
+func PDF(inv *Invoice) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	contentWidth := float64(pageWidth - 2*margin)
+	lh := lineHeight
+
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.AddPage()
+
+	pair := func(label, value string, labelWidth float64) {
+		pdf.CellFormat(labelWidth, lh, tr(label), "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentWidth-labelWidth, lh, tr(value), "", 1, "L", false, 0, "")
+	}
+	total := func(label, value string) {
+		pdf.CellFormat(contentWidth-40, lh, tr(label), "", 0, "R", false, 0, "")
+		pdf.CellFormat(40, lh, tr(value), "", 1, "R", false, 0, "")
+	}
+
+	// Outlet header
+	if inv.Header.OutletName != "" {
+		pdf.SetFont("Helvetica", "B", titleSize)
+		pdf.CellFormat(contentWidth, lh*1.6, tr(inv.Header.OutletName), "", 1, "L", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "", fontSize)
+	for _, line := range []string{inv.Header.Address, inv.Header.Phone} {
+		if line != "" {
+			pdf.MultiCell(contentWidth, lh, tr(line), "", "L", false)
+		}
+	}
+	if inv.Header.TaxID != "" {
+		pdf.CellFormat(contentWidth, lh, tr("NPWP "+inv.Header.TaxID), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(lh)
+
+	// Invoice details
+	pdf.SetFont("Helvetica", "B", titleSize)
+	pdf.CellFormat(contentWidth, lh*1.6, "INVOICE", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", fontSize)
+	pair("Number", inv.Number, 30)
+	pair("Period", inv.Period, 30)
+	pair("Issue date", inv.IssueDate.Format("02/01/2006"), 30)
+	pair("Due date", inv.DueDate.Format("02/01/2006"), 30)
+	pdf.Ln(lh)
+
+	// Bill to
+	pdf.SetFont("Helvetica", "B", fontSize)
+	pdf.CellFormat(contentWidth, lh, "Bill to", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", fontSize)
+	pdf.CellFormat(contentWidth, lh, tr(inv.BillTo.Name), "", 1, "L", false, 0, "")
+	if inv.BillTo.Contact != "" {
+		pdf.CellFormat(contentWidth, lh, tr("Attn. "+inv.BillTo.Contact), "", 1, "L", false, 0, "")
+	}
+	if inv.BillTo.Address != "" {
+		pdf.MultiCell(contentWidth, lh, tr(inv.BillTo.Address), "", "L", false)
+	}
+	if inv.BillTo.TaxID != "" {
+		pdf.CellFormat(contentWidth, lh, tr("NPWP "+inv.BillTo.TaxID), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(lh)
+
+	// Lines, with the table header repeated on every page
+	header := func() {
+		pdf.SetFont("Helvetica", "B", fontSize)
+		for _, column := range columns {
+			pdf.CellFormat(column.width, lh+1, column.title, "B", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", fontSize)
+	}
+	header()
+	_, pageHeight := pdf.GetPageSize()
+	for _, line := range inv.Lines {
+		if pdf.GetY()+lh > pageHeight-margin {
+			pdf.AddPage()
+			header()
+		}
+		values := []string{
+			line.Date.Format("02/01/2006"),
+			line.OrderNumber,
+			line.LicensePlate,
+			line.Driver,
+			receipt.FormatAmount(line.Amount),
+		}
+		for i, column := range columns {
+			pdf.CellFormat(column.width, lh, tr(fit(pdf, values[i], column.width)), "", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	y := pdf.GetY() + 1
+	pdf.Line(margin, y, pageWidth-margin, y)
+	pdf.Ln(2)
+
+	// Totals
+	total("Orders", fmt.Sprintf("%d", len(inv.Lines)))
+	pdf.SetFont("Helvetica", "B", fontSize)
+	total("Total", receipt.FormatAmount(inv.TotalAmount))
+	pdf.SetFont("Helvetica", "", fontSize)
+	if inv.PaidAmount > 0 {
+		total("Paid", "-"+receipt.FormatAmount(inv.PaidAmount))
+		pdf.SetFont("Helvetica", "B", fontSize)
+		total("Balance due", receipt.FormatAmount(inv.BalanceDue()))
+		pdf.SetFont("Helvetica", "", fontSize)
+	}
+
+	// Payment instructions and footer
+	if len(inv.PaymentInstructions) > 0 {
+		pdf.Ln(lh)
+		for _, line := range inv.PaymentInstructions {
+			pdf.MultiCell(contentWidth, lh, tr(line), "", "L", false)
+		}
+	}
+	if len(inv.Header.Footer) > 0 {
+		pdf.Ln(lh)
+		for _, line := range inv.Header.Footer {
+			pdf.MultiCell(contentWidth, lh, tr(line), "", "C", false)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit shortens text with an ellipsis until it fits in a table cell.
+func fit(pdf *fpdf.Fpdf, text string, width float64) string {
+	available := width - 2*pdf.GetCellMargin()
+	if pdf.GetStringWidth(text) <= available {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > available {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,93 @@
+// Package jobs runs periodic background work inside the server. Every run
+// of a job holds a Postgres advisory lock named after it, so when several
+// server instances share a database only one of them runs a job at a time.
+package jobs
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Job is work repeated at a fixed interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Runner struct {
+	db   *gorm.DB
+	jobs []Job
+}
+
+func NewRunner(db *gorm.DB) *Runner {
+	return &Runner{db: db}
+}
+
+// Add registers a job. Jobs added after Start are not run.
+func (r *Runner) Add(job Job) {
+	r.jobs = append(r.jobs, job)
+}
+
+// Start runs every job once right away and then at its interval, each in
+// its own goroutine, until ctx is cancelled.
+func (r *Runner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		if job.Interval <= 0 {
+			log.Printf("Job %s not started: interval must be positive", job.Name)
+			continue
+		}
+		go r.loop(ctx, job)
+	}
+}
+
+func (r *Runner) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce runs a job unless another instance is running it. The lock is a
+// transaction-level advisory lock, released when the transaction ends even
+// if the instance dies halfway.
+func (r *Runner) runOnce(ctx context.Context, job Job) {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		log.Printf("Job %s: %v", job.Name, tx.Error)
+		return
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", lockKey(job.Name)).Scan(&locked).Error; err != nil {
+		log.Printf("Job %s: %v", job.Name, err)
+		return
+	}
+	if !locked {
+		return
+	}
+
+	if err := job.Run(ctx); err != nil {
+		log.Printf("Job %s failed: %v", job.Name, err)
+	}
+}
+
+// lockKey turns a job name into an advisory lock key.
+func lockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("jobs:" + name))
+	return int64(hash.Sum64())
+}
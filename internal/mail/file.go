@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var unsafeFilename = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// FileTransport writes every message to an .eml file in a directory and
+// logs it, instead of sending it. It is meant for development, where the
+// files can be opened with any mail client.
+type FileTransport struct {
+	Dir string
+}
+
+func (t *FileTransport) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml",
+		time.Now().Format("20060102-150405.000000"),
+		unsafeFilename.ReplaceAllString(strings.Join(msg.To, "_"), "_"),
+	)
+	path := filepath.Join(t.Dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+
+	log.Printf("Mail to %s (%q) written to %s", strings.Join(msg.To, ", "), msg.Subject, path)
+	return nil
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileTransport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	transport := &FileTransport{Dir: dir}
+	if err := transport.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("got files %v (%v), want one .eml file", files, err)
+	}
+	if !strings.HasSuffix(files[0], "-budi_example.com.eml") {
+		t.Errorf("file %s is not named after the recipient", files[0])
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if _, parts := readParts(t, data); len(parts) != 3 {
+		t.Errorf("written message has %d parts, want 3", len(parts))
+	}
+}
@@ -0,0 +1,177 @@
+// Package mail composes MIME messages and delivers them through a pluggable
+// transport: SMTP in production, or files on disk during development.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Transport names used in configuration.
+const (
+	TransportSMTP = "smtp"
+	TransportFile = "file"
+)
+
+// Transport delivers a composed message.
+type Transport interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Attachment is a file sent along with a message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is an email with a plain text body, an optional HTML alternative
+// and optional attachments.
+type Message struct {
+	From        mail.Address
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Bytes renders the message as RFC 5322 data ready to hand to an SMTP
+// server or write to an .eml file.
+func (m *Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", m.From.String())
+	header.Set("To", strings.Join(m.To, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID(m.From.Address))
+	header.Set("MIME-Version", "1.0")
+
+	// text, or text and HTML as alternatives, wrapped in a mixed part when
+	// there are attachments
+	body := &bytes.Buffer{}
+	contentType, err := writeBody(body, m)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.Attachments) == 0 {
+		header.Set("Content-Type", contentType)
+		if !strings.HasPrefix(contentType, "multipart/") {
+			header.Set("Content-Transfer-Encoding", "quoted-printable")
+		}
+		writeHeader(&buf, header)
+		buf.Write(body.Bytes())
+		return buf.Bytes(), nil
+	}
+
+	mixed := &bytes.Buffer{}
+	writer := multipart.NewWriter(mixed)
+	header.Set("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+	writeHeader(&buf, header)
+
+	partHeader := textproto.MIMEHeader{}
+	partHeader.Set("Content-Type", contentType)
+	if !strings.HasPrefix(contentType, "multipart/") {
+		partHeader.Set("Content-Transfer-Encoding", "quoted-printable")
+	}
+	part, err := writer.CreatePart(partHeader)
+	if err != nil {
+		return nil, err
+	}
+	part.Write(body.Bytes())
+
+	for _, attachment := range m.Attachments {
+		attachmentHeader := textproto.MIMEHeader{}
+		attachmentHeader.Set("Content-Type", attachment.ContentType)
+		attachmentHeader.Set("Content-Transfer-Encoding", "base64")
+		attachmentHeader.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+		part, err := writer.CreatePart(attachmentHeader)
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, attachment.Data)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	buf.Write(mixed.Bytes())
+	return buf.Bytes(), nil
+}
+
+// writeBody writes the text and HTML parts and returns their content type.
+func writeBody(w *bytes.Buffer, m *Message) (string, error) {
+	if m.HTML == "" {
+		writeQuotedPrintable(w, m.Text)
+		return "text/plain; charset=utf-8", nil
+	}
+
+	writer := multipart.NewWriter(w)
+	for _, alternative := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", alternative.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return "", err
+		}
+		writeQuotedPrintable(part, alternative.content)
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return "multipart/alternative; boundary=" + writer.Boundary(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w io.Writer, content string) {
+	qp := quotedprintable.NewWriter(w)
+	qp.Write([]byte(content))
+	qp.Close()
+}
+
+// writeBase64 writes data base64 encoded in lines of 76 characters.
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	random := make([]byte, 12)
+	rand.Read(random)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(random), domain)
+}
@@ -0,0 +1,153 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func testMessage() *Message {
+	return &Message{
+		From:    mail.Address{Name: "Flashlight Car Wash", Address: "receipts@example.com"},
+		To:      []string{"budi@example.com"},
+		Subject: "Struk WO-20240115-0001 – Flashlight",
+		Text:    "Terima kasih, Budi.",
+		HTML:    "<p>Terima kasih, <b>Budi</b>.</p>",
+		Attachments: []Attachment{{
+			Filename:    "receipt-WO-20240115-0001.pdf",
+			ContentType: "application/pdf",
+			// Long enough to be wrapped over several base64 lines
+			Data: bytes.Repeat([]byte("%PDF-1.4 receipt "), 20),
+		}},
+	}
+}
+
+// part is a decoded leaf part of a message.
+type part struct {
+	contentType string
+	filename    string
+	body        []byte
+}
+
+// readParts parses a message and returns its leaf parts in order.
+func readParts(t *testing.T, data []byte) (*mail.Message, []part) {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+
+	var parts []part
+	var walk func(contentType, encoding, disposition string, body io.Reader)
+	walk = func(contentType, encoding, disposition string, body io.Reader) {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatalf("ParseMediaType(%q): %v", contentType, err)
+		}
+		if strings.HasPrefix(mediaType, "multipart/") {
+			reader := multipart.NewReader(body, params["boundary"])
+			for {
+				p, err := reader.NextRawPart()
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					t.Fatalf("NextRawPart: %v", err)
+				}
+				walk(p.Header.Get("Content-Type"), p.Header.Get("Content-Transfer-Encoding"), p.Header.Get("Content-Disposition"), p)
+			}
+		}
+
+		switch encoding {
+		case "quoted-printable":
+			body = quotedprintable.NewReader(body)
+		case "base64":
+			body = base64.NewDecoder(base64.StdEncoding, body)
+		}
+		decoded, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("read %s part: %v", mediaType, err)
+		}
+
+		var filename string
+		if disposition != "" {
+			_, params, err := mime.ParseMediaType(disposition)
+			if err != nil {
+				t.Fatalf("ParseMediaType(%q): %v", disposition, err)
+			}
+			filename = params["filename"]
+		}
+		parts = append(parts, part{contentType: mediaType, filename: filename, body: decoded})
+	}
+	walk(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), "", msg.Body)
+	return msg, parts
+}
+
+func TestMessageBytes(t *testing.T) {
+	want := testMessage()
+	data, err := want.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+
+	msg, parts := readParts(t, data)
+	if from := msg.Header.Get("From"); from != want.From.String() {
+		t.Errorf("From = %q, want %q", from, want.From.String())
+	}
+	if to := msg.Header.Get("To"); to != "budi@example.com" {
+		t.Errorf("To = %q, want budi@example.com", to)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != want.Subject {
+		t.Errorf("Subject decodes to %q (%v), want %q", subject, err, want.Subject)
+	}
+	if msg.Header.Get("Message-ID") == "" || msg.Header.Get("Date") == "" {
+		t.Errorf("Message-ID and Date headers are required")
+	}
+
+	if len(parts) != 3 {
+		t.Fatalf("got %d parts, want text, HTML and attachment", len(parts))
+	}
+	if parts[0].contentType != "text/plain" || string(parts[0].body) != want.Text {
+		t.Errorf("first part is %s %q, want text/plain %q", parts[0].contentType, parts[0].body, want.Text)
+	}
+	if parts[1].contentType != "text/html" || string(parts[1].body) != want.HTML {
+		t.Errorf("second part is %s %q, want text/html %q", parts[1].contentType, parts[1].body, want.HTML)
+	}
+	attachment := want.Attachments[0]
+	if parts[2].contentType != attachment.ContentType || parts[2].filename != attachment.Filename {
+		t.Errorf("attachment is %s %q, want %s %q", parts[2].contentType, parts[2].filename, attachment.ContentType, attachment.Filename)
+	}
+	if !bytes.Equal(parts[2].body, attachment.Data) {
+		t.Errorf("attachment data does not round trip")
+	}
+
+	for _, line := range strings.Split(string(data), "\r\n") {
+		if len(line) > 998 {
+			t.Errorf("line of %d characters exceeds the RFC 5322 limit", len(line))
+		}
+	}
+}
+
+func TestMessageBytesTextOnly(t *testing.T) {
+	data, err := (&Message{
+		From:    mail.Address{Address: "receipts@example.com"},
+		To:      []string{"budi@example.com"},
+		Subject: "Struk",
+		Text:    "Total: Rp 50.000",
+	}).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+
+	_, parts := readParts(t, data)
+	if len(parts) != 1 || parts[0].contentType != "text/plain" || string(parts[0].body) != "Total: Rp 50.000" {
+		t.Errorf("got parts %+v, want a single text/plain body", parts)
+	}
+}
@@ -0,0 +1,166 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSession is what the test server received in one session.
+type smtpSession struct {
+	auth       string
+	from       string
+	recipients []string
+	data       string
+}
+
+// startSMTPServer runs a minimal SMTP server for one session on a local
+// port. Recipients listed in reject are refused.
+func startSMTPServer(t *testing.T, reject ...string) (port int, sessions <-chan smtpSession) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	done := make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var session smtpSession
+		reply("220 localhost ESMTP test")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				done <- session
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+			switch {
+			case command == "EHLO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case command == "AUTH":
+				fields := strings.Fields(line)
+				decoded, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+				session.auth = string(decoded)
+				reply("235 2.7.0 Authentication successful")
+			case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+				session.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+				recipient := strings.Trim(line[len("RCPT TO:"):], "<>")
+				rejected := false
+				for _, r := range reject {
+					rejected = rejected || r == recipient
+				}
+				if rejected {
+					reply("550 5.1.1 No such user")
+					continue
+				}
+				session.recipients = append(session.recipients, recipient)
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(line, "."))
+				}
+				session.data = data.String()
+				reply("250 OK queued")
+			case command == "QUIT":
+				reply("221 Bye")
+				done <- session
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, done
+}
+
+func receive(t *testing.T, sessions <-chan smtpSession) smtpSession {
+	t.Helper()
+	select {
+	case session := <-sessions:
+		return session
+	case <-time.After(10 * time.Second):
+		t.Fatalf("SMTP session did not finish")
+		return smtpSession{}
+	}
+}
+
+func TestSMTPTransportSend(t *testing.T) {
+	port, sessions := startSMTPServer(t)
+	transport := &SMTPTransport{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: "mailer",
+		Password: "secret",
+		Timeout:  5 * time.Second,
+	}
+
+	msg := testMessage()
+	if err := transport.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	session := receive(t, sessions)
+	if session.auth != "\x00mailer\x00secret" {
+		t.Errorf("AUTH PLAIN sent %q, want the configured credentials", session.auth)
+	}
+	if session.from != msg.From.Address {
+		t.Errorf("MAIL FROM %q, want %q", session.from, msg.From.Address)
+	}
+	if strings.Join(session.recipients, ",") != strings.Join(msg.To, ",") {
+		t.Errorf("RCPT TO %v, want %v", session.recipients, msg.To)
+	}
+	if _, parts := readParts(t, []byte(session.data)); len(parts) != 3 {
+		t.Errorf("delivered message has %d parts, want 3", len(parts))
+	}
+}
+
+func TestSMTPTransportRejectedRecipient(t *testing.T) {
+	port, _ := startSMTPServer(t, "budi@example.com")
+	transport := &SMTPTransport{Host: "127.0.0.1", Port: port, Timeout: 5 * time.Second}
+
+	err := transport.Send(context.Background(), testMessage())
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Errorf("got error %v, want the server's 550 rejection", err)
+	}
+}
+
+func TestSMTPTransportUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	transport := &SMTPTransport{Host: "127.0.0.1", Port: port, Timeout: time.Second}
+	if err := transport.Send(context.Background(), testMessage()); err == nil {
+		t.Errorf("Send to a closed port succeeded, want an error")
+	}
+}
@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Renderer renders the bodies of a message from a pair of templates,
+// "<name>.txt.tmpl" and "<name>.html.tmpl", embedded in the binary.
+type Renderer struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// NewRenderer parses the embedded templates. funcs are made available to
+// both the text and HTML templates.
+func NewRenderer(funcs map[string]any) (*Renderer, error) {
+	text, err := texttemplate.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.txt.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.html.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{text: text, html: html}, nil
+}
+
+// Render executes the text and HTML templates called name with data.
+func (r *Renderer) Render(name string, data any) (text, html string, err error) {
+	var textBuf, htmlBuf bytes.Buffer
+	if err := r.text.ExecuteTemplate(&textBuf, name+".txt.tmpl", data); err != nil {
+		return "", "", err
+	}
+	if err := r.html.ExecuteTemplate(&htmlBuf, name+".html.tmpl", data); err != nil {
+		return "", "", err
+	}
+	return textBuf.String(), htmlBuf.String(), nil
+}
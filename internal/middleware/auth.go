@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -34,6 +35,12 @@ func AuthMiddleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		// Approval tokens only vouch for a single supervisor approval
+		if claims.Purpose != "" {
+			c.JSON(http.StatusUnauthorized, dto.ErrorResponse("Invalid or expired token", errors.New("not a login token")))
+			c.Abort()
+			return
+		}
 
 		// Set user info in context
 		c.Set("user_id", claims.UserID)
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// defaultReplayContentType is used for responses stored before their content
+// type was, which were all JSON.
+const defaultReplayContentType = "application/json; charset=utf-8"
+
+// responseRecorder keeps a copy of everything written to the response.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware makes a route safe to retry. Requests carrying an
+// Idempotency-Key header are fingerprinted and their response stored; a
+// retry with the same key and body gets the stored response back, while a
+// different body under the same key is rejected. Requests without the
+// header pass straight through.
+func IdempotencyMiddleware(repo *repository.IdempotencyKeyRepository, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid idempotency key", errors.New("idempotency key must be at most 255 characters")))
+			c.Abort()
+			return
+		}
+
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, dto.ErrorResponse("Idempotency key requires authentication", errors.New("user not found in context")))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to read request body", err))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.FullPath() + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		ctx := c.Request.Context()
+		now := time.Now()
+		newRecord := func() *models.IdempotencyKey {
+			return &models.IdempotencyKey{
+				Key:         key,
+				UserID:      userID.(uint),
+				Method:      c.Request.Method,
+				Path:        c.FullPath(),
+				RequestHash: fingerprint,
+				ExpiresAt:   now.Add(ttl),
+			}
+		}
+
+		record := newRecord()
+		reserved, err := repo.Reserve(ctx, record)
+
+		// An expired key is treated as never used
+		if err == nil && !reserved && record.ExpiresAt.Before(now) {
+			if err = repo.Release(ctx, record.ID); err == nil {
+				record = newRecord()
+				reserved, err = repo.Reserve(ctx, record)
+			}
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to check idempotency key", err))
+			c.Abort()
+			return
+		}
+
+		if !reserved {
+			switch {
+			case record.RequestHash != fingerprint:
+				c.JSON(http.StatusUnprocessableEntity, dto.ErrorResponse("Idempotency key reused", errors.New("idempotency key was already used with a different request")))
+			case record.CompletedAt == nil:
+				c.JSON(http.StatusConflict, dto.ErrorResponse("Request in progress", errors.New("a request with this idempotency key is still being processed")))
+			default:
+				contentType := record.ContentType
+				if contentType == "" {
+					contentType = defaultReplayContentType
+				}
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(record.StatusCode, contentType, record.ResponseBody)
+			}
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+
+		// A panicking handler unwinds straight past c.Next. Free the key on
+		// the way out so the request can be retried, and leave the panic to
+		// the recovery middleware
+		defer func() {
+			if r := recover(); r != nil {
+				if err := repo.Release(ctx, record.ID); err != nil {
+					log.Printf("Failed to release idempotency key %q: %v", key, err)
+				}
+				panic(r)
+			}
+		}()
+
+		c.Next()
+
+		// Server errors are not replayed so the client can simply retry
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			if err := repo.Release(ctx, record.ID); err != nil {
+				log.Printf("Failed to release idempotency key %q: %v", key, err)
+			}
+			return
+		}
+
+		if err := repo.Complete(ctx, record.ID, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			log.Printf("Failed to store response for idempotency key %q: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestIdempotencyReplaysNonJSONResponses(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.IdempotencyKey{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0xff}
+	calls := 0
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", uint(1)) })
+	router.Use(IdempotencyMiddleware(repository.NewIdempotencyKeyRepository(db), time.Hour))
+	router.POST("/image", func(c *gin.Context) {
+		calls++
+		c.Data(http.StatusOK, "image/png", png)
+	})
+	router.POST("/text", func(c *gin.Context) {
+		calls++
+		c.String(http.StatusBadRequest, "not a JSON body")
+	})
+
+	for _, tc := range []struct {
+		path        string
+		status      int
+		contentType string
+		body        []byte
+	}{
+		{"/image", http.StatusOK, "image/png", png},
+		{"/text", http.StatusBadRequest, "text/plain; charset=utf-8", []byte("not a JSON body")},
+	} {
+		calls = 0
+		for attempt := 1; attempt <= 2; attempt++ {
+			req := httptest.NewRequest(http.MethodPost, tc.path, bytes.NewReader([]byte(`{}`)))
+			req.Header.Set(IdempotencyKeyHeader, "key"+tc.path)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tc.status {
+				t.Errorf("%s attempt %d: status %d, want %d", tc.path, attempt, recorder.Code, tc.status)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tc.contentType {
+				t.Errorf("%s attempt %d: content type %q, want %q", tc.path, attempt, got, tc.contentType)
+			}
+			if !bytes.Equal(recorder.Body.Bytes(), tc.body) {
+				t.Errorf("%s attempt %d: body %q, want %q", tc.path, attempt, recorder.Body.Bytes(), tc.body)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("%s: handler ran %d times, want once", tc.path, calls)
+		}
+	}
+}
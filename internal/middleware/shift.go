@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActiveShiftMiddleware blocks cashiers from register operations until they
+// have opened a shift. Owners and admins pass through; services that account
+// money to a shift still require one of them.
+func ActiveShiftMiddleware(shiftRepo *repository.ShiftRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := c.Get("user_role"); role != "cashier" {
+			c.Next()
+			return
+		}
+
+		userID, _ := c.Get("user_id")
+		uid, ok := userID.(uint)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, dto.ErrorResponse("User not found in context", errors.New("missing authenticated user")))
+			c.Abort()
+			return
+		}
+
+		shift, err := shiftRepo.FindActiveShiftByUser(c.Request.Context(), uid)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to look up active shift", err))
+			c.Abort()
+			return
+		}
+		if shift == nil {
+			c.JSON(http.StatusConflict, dto.ErrorResponse("No active shift", errors.New("open a shift before using the register")))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// UsedApprovalToken records a supervisor approval token that has approved
+// an operation, so the same token cannot approve another.
+type UsedApprovalToken struct {
+	TokenID          string    `gorm:"type:varchar(64);primaryKey" json:"token_id"`
+	SupervisorUserID uint      `gorm:"not null;index" json:"supervisor_user_id"`
+	ExpiresAt        time.Time `gorm:"not null;index" json:"expires_at"`
+	UsedAt           time.Time `gorm:"not null" json:"used_at"`
+}
+
+func (UsedApprovalToken) TableName() string {
+	return "used_approval_tokens"
+}
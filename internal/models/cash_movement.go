@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+type CashMovementType string
+
+const (
+	// CashMovementPayIn is cash put into the drawer that is not a sale, such
+	// as extra change brought from the safe.
+	CashMovementPayIn CashMovementType = "pay_in"
+	// CashMovementPayOut is petty cash taken from the drawer to pay for
+	// something, such as soap or a parking attendant.
+	CashMovementPayOut CashMovementType = "pay_out"
+	// CashMovementSafeDrop is cash moved from the drawer to the safe so the
+	// drawer does not hold too much.
+	CashMovementSafeDrop CashMovementType = "safe_drop"
+)
+
+// CashMovement records cash entering or leaving a shift's drawer other than
+// through payments and refunds, so the cash counted at close can be matched.
+type CashMovement struct {
+	ID               uint             `gorm:"primaryKey" json:"id"`
+	ShiftID          uint             `gorm:"not null;index" json:"shift_id"`
+	Type             CashMovementType `gorm:"type:varchar(20);not null" json:"type"`
+	Category         string           `gorm:"type:varchar(50);not null" json:"category"`
+	Amount           float64          `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Note             *string          `gorm:"type:text" json:"note"`
+	RecordedByUserID *uint            `gorm:"index" json:"recorded_by_user_id"`
+	ApprovedByUserID uint             `gorm:"not null;index" json:"approved_by_user_id"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+
+	// Relations
+	Shift      *Shift `gorm:"foreignKey:ShiftID" json:"shift,omitempty"`
+	RecordedBy *User  `gorm:"foreignKey:RecordedByUserID" json:"recorded_by,omitempty"`
+	ApprovedBy *User  `gorm:"foreignKey:ApprovedByUserID" json:"approved_by,omitempty"`
+}
+
+func (CashMovement) TableName() string {
+	return "cash_movements"
+}
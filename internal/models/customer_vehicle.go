@@ -7,13 +7,14 @@ import (
 )
 
 type CustomerVehicle struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	CustomerID   uint           `gorm:"not null;index" json:"customer_id"`
-	VehicleID    uint           `gorm:"not null;index" json:"vehicle_id"`
-	LicensePlate string         `gorm:"type:varchar(50);not null" json:"license_plate"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	CustomerID     uint           `gorm:"not null;index" json:"customer_id"`
+	VehicleID      uint           `gorm:"not null;index" json:"vehicle_id"`
+	LicensePlate   string         `gorm:"type:varchar(50);not null" json:"license_plate"`
+	FleetAccountID *uint          `gorm:"index" json:"fleet_account_id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
 	// Relations
 	Customer   User        `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
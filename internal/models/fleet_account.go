@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FleetAccount is a company, such as a rental or ride-hailing fleet, whose
+// vehicles are washed on account and paid for monthly by invoice. The
+// account owns its vehicles through CustomerVehicle.FleetAccountID, and only
+// its authorised drivers may charge orders to it.
+type FleetAccount struct {
+	ID             uint    `gorm:"primaryKey" json:"id"`
+	Name           string  `gorm:"type:varchar(255);not null" json:"name"`
+	ContactName    *string `gorm:"type:varchar(255)" json:"contact_name"`
+	BillingEmail   *string `gorm:"type:varchar(255)" json:"billing_email"`
+	PhoneNumber    *string `gorm:"type:varchar(50)" json:"phone_number"`
+	BillingAddress *string `gorm:"type:text" json:"billing_address"`
+	TaxID          *string `gorm:"type:varchar(50)" json:"tax_id"`
+	// CreditLimit caps what the account may owe, invoiced or not. Zero
+	// means no limit.
+	CreditLimit     float64        `gorm:"type:decimal(15,2);default:0" json:"credit_limit"`
+	PaymentTermDays int            `gorm:"not null;default:30" json:"payment_term_days"`
+	IsActive        bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Vehicles []CustomerVehicle `gorm:"foreignKey:FleetAccountID" json:"vehicles,omitempty"`
+	Drivers  []FleetDriver     `gorm:"foreignKey:FleetAccountID" json:"drivers,omitempty"`
+}
+
+func (FleetAccount) TableName() string {
+	return "fleet_accounts"
+}
+
+// FleetDriver authorises a user to charge orders to a fleet account.
+type FleetDriver struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	FleetAccountID uint      `gorm:"not null;uniqueIndex:idx_fleet_drivers_account_user" json:"fleet_account_id"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_fleet_drivers_account_user;index" json:"user_id"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (FleetDriver) TableName() string {
+	return "fleet_drivers"
+}
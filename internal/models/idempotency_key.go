@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// IdempotencyKey remembers the outcome of a request sent with an
+// Idempotency-Key header so that retries get the original response instead
+// of repeating the side effects. Keys are scoped to the user and the route.
+// The response is kept byte for byte with its content type, as not every
+// response is JSON.
+type IdempotencyKey struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Key          string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_scope" json:"key"`
+	UserID       uint       `gorm:"not null;uniqueIndex:idx_idempotency_keys_scope" json:"user_id"`
+	Method       string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_idempotency_keys_scope" json:"method"`
+	Path         string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_scope" json:"path"`
+	RequestHash  string     `gorm:"type:varchar(64);not null" json:"request_hash"`
+	StatusCode   int        `json:"status_code"`
+	ContentType  string     `gorm:"type:varchar(255)" json:"content_type"`
+	ResponseBody []byte     `gorm:"type:bytea" json:"response_body"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
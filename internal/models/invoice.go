@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+)
+
+type InvoiceStatus string
+
+const (
+	InvoiceStatusOpen          InvoiceStatus = "open"
+	InvoiceStatusPartiallyPaid InvoiceStatus = "partially_paid"
+	InvoiceStatusPaid          InvoiceStatus = "paid"
+)
+
+// Invoice bills a fleet account for the orders charged to it up to the end
+// of a month. Each account gets at most one invoice per period.
+type Invoice struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	InvoiceNumber  string `gorm:"type:varchar(50);uniqueIndex;not null" json:"invoice_number"`
+	FleetAccountID uint   `gorm:"not null;uniqueIndex:idx_invoices_account_period" json:"fleet_account_id"`
+	// Period is the billed month, formatted YYYY-MM.
+	Period            string        `gorm:"type:varchar(7);not null;uniqueIndex:idx_invoices_account_period" json:"period"`
+	IssueDate         time.Time     `gorm:"not null" json:"issue_date"`
+	DueDate           time.Time     `gorm:"not null;index" json:"due_date"`
+	TotalAmount       float64       `gorm:"type:decimal(15,2);not null" json:"total_amount"`
+	PaidAmount        float64       `gorm:"type:decimal(15,2);default:0" json:"paid_amount"`
+	Status            InvoiceStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	GeneratedByUserID *uint         `gorm:"index" json:"generated_by_user_id"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+
+	// Relations
+	FleetAccount *FleetAccount       `gorm:"foreignKey:FleetAccountID" json:"fleet_account,omitempty"`
+	Lines        []InvoiceLine       `gorm:"foreignKey:InvoiceID" json:"lines,omitempty"`
+	Allocations  []InvoiceAllocation `gorm:"foreignKey:InvoiceID" json:"allocations,omitempty"`
+}
+
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// InvoiceLine is one on-account charge billed on an invoice, with the order
+// details as they were when it was invoiced.
+type InvoiceLine struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	InvoiceID    uint      `gorm:"not null;index" json:"invoice_id"`
+	PaymentID    uint      `gorm:"not null;uniqueIndex" json:"payment_id"`
+	WorkOrderID  uint      `gorm:"not null;index" json:"work_order_id"`
+	OrderNumber  string    `gorm:"type:varchar(100);not null" json:"order_number"`
+	LicensePlate string    `gorm:"type:varchar(50)" json:"license_plate"`
+	DriverName   string    `gorm:"type:varchar(255)" json:"driver_name"`
+	ChargedAt    time.Time `gorm:"not null" json:"charged_at"`
+	Amount       float64   `gorm:"type:decimal(15,2);not null" json:"amount"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (InvoiceLine) TableName() string {
+	return "invoice_lines"
+}
+
+// AccountPayment is money received from a fleet account. It is allocated to
+// the account's invoices; whatever is left stays on the account as credit
+// for the next invoice.
+type AccountPayment struct {
+	ID                uint          `gorm:"primaryKey" json:"id"`
+	PaymentNumber     string        `gorm:"type:varchar(100);uniqueIndex;not null" json:"payment_number"`
+	FleetAccountID    uint          `gorm:"not null;index" json:"fleet_account_id"`
+	Method            PaymentMethod `gorm:"type:varchar(20);not null" json:"method"`
+	Amount            float64       `gorm:"type:decimal(15,2);not null" json:"amount"`
+	UnallocatedAmount float64       `gorm:"type:decimal(15,2);default:0" json:"unallocated_amount"`
+	ReferenceNumber   *string       `gorm:"type:varchar(255)" json:"reference_number"`
+	ReceivedAt        time.Time     `gorm:"not null" json:"received_at"`
+	Note              *string       `gorm:"type:text" json:"note"`
+	RecordedByUserID  *uint         `gorm:"index" json:"recorded_by_user_id"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+
+	// Relations
+	RecordedBy  *User               `gorm:"foreignKey:RecordedByUserID" json:"recorded_by,omitempty"`
+	Allocations []InvoiceAllocation `gorm:"foreignKey:AccountPaymentID" json:"allocations,omitempty"`
+}
+
+func (AccountPayment) TableName() string {
+	return "account_payments"
+}
+
+// InvoiceAllocation applies part of an account payment to an invoice.
+type InvoiceAllocation struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	AccountPaymentID uint      `gorm:"not null;index" json:"account_payment_id"`
+	InvoiceID        uint      `gorm:"not null;index" json:"invoice_id"`
+	Amount           float64   `gorm:"type:decimal(15,2);not null" json:"amount"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (InvoiceAllocation) TableName() string {
+	return "invoice_allocations"
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoyaltyEntryType string
+type LoyaltyRewardType string
+
+const (
+	LoyaltyEntryEarn           LoyaltyEntryType = "earn"
+	LoyaltyEntryRedeem         LoyaltyEntryType = "redeem"
+	LoyaltyEntryEarnReversal   LoyaltyEntryType = "earn_reversal"
+	LoyaltyEntryRedeemReversal LoyaltyEntryType = "redeem_reversal"
+	LoyaltyEntryExpiry         LoyaltyEntryType = "expiry"
+
+	RewardTypeDiscount    LoyaltyRewardType = "discount"
+	RewardTypeFreeProduct LoyaltyRewardType = "free_product"
+)
+
+// LoyaltyEarnRule sets how much a customer has to spend to earn one point.
+// A rule can be scoped to a product category, a membership tier, both, or
+// neither (the default rule); the most specific matching rule wins.
+type LoyaltyEarnRule struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	CategoryID       *uint          `gorm:"index" json:"category_id"`
+	MembershipTypeID *uint          `gorm:"index" json:"membership_type_id"`
+	AmountPerPoint   float64        `gorm:"type:decimal(15,2);not null" json:"amount_per_point"`
+	IsActive         bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Category       *ProductCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	MembershipType *MembershipType  `gorm:"foreignKey:MembershipTypeID" json:"membership_type,omitempty"`
+}
+
+func (LoyaltyEarnRule) TableName() string {
+	return "loyalty_earn_rules"
+}
+
+// LoyaltyReward is something points can be exchanged for on a work order:
+// either a fixed discount or a free product.
+type LoyaltyReward struct {
+	ID             uint              `gorm:"primaryKey" json:"id"`
+	Name           string            `gorm:"type:varchar(255);not null" json:"name"`
+	PointsCost     int               `gorm:"not null" json:"points_cost"`
+	RewardType     LoyaltyRewardType `gorm:"type:varchar(20);not null" json:"reward_type"`
+	DiscountAmount float64           `gorm:"type:decimal(15,2);default:0" json:"discount_amount"`
+	ProductID      *uint             `gorm:"index" json:"product_id"`
+	IsActive       bool              `gorm:"default:true" json:"is_active"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt    `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (LoyaltyReward) TableName() string {
+	return "loyalty_rewards"
+}
+
+// LoyaltyPointEntry is one movement on a customer's points balance. Entries
+// are append-only. Earned points carry an ExpiresAt and are consumed oldest
+// first, so expiry only removes points that were not redeemed in time.
+type LoyaltyPointEntry struct {
+	ID              uint             `gorm:"primaryKey" json:"id"`
+	CustomerUserID  uint             `gorm:"not null;index" json:"customer_user_id"`
+	EntryType       LoyaltyEntryType `gorm:"type:varchar(20);not null" json:"entry_type"`
+	Points          int              `gorm:"not null" json:"points"`
+	BalanceAfter    int              `gorm:"not null" json:"balance_after"`
+	WorkOrderID     *uint            `gorm:"index" json:"work_order_id"`
+	RewardID        *uint            `gorm:"index" json:"reward_id"`
+	ExpiresAt       *time.Time       `json:"expires_at"`
+	Note            *string          `gorm:"type:text" json:"note"`
+	CreatedByUserID *uint            `gorm:"index" json:"created_by_user_id"`
+	CreatedAt       time.Time        `json:"created_at"`
+
+	// Relations
+	CustomerUser User           `gorm:"foreignKey:CustomerUserID" json:"customer_user,omitempty"`
+	WorkOrder    *WorkOrder     `gorm:"foreignKey:WorkOrderID" json:"work_order,omitempty"`
+	Reward       *LoyaltyReward `gorm:"foreignKey:RewardID" json:"reward,omitempty"`
+}
+
+func (LoyaltyPointEntry) TableName() string {
+	return "loyalty_point_entries"
+}
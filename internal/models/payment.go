@@ -10,37 +10,57 @@ type PaymentMethod string
 type PaymentStatus string
 
 const (
-	MethodCash    PaymentMethod = "cash"
-	MethodQRIS    PaymentMethod = "qris"
+	MethodCash     PaymentMethod = "cash"
+	MethodQRIS     PaymentMethod = "qris"
 	MethodTransfer PaymentMethod = "transfer"
 	MethodEWallet  PaymentMethod = "e_wallet"
+	MethodWallet   PaymentMethod = "wallet"
+	MethodAccount  PaymentMethod = "account"
 
 	PaymentStatusPending   PaymentStatus = "pending"
 	PaymentStatusCompleted PaymentStatus = "completed"
 	PaymentStatusFailed    PaymentStatus = "failed"
 	PaymentStatusRefunded  PaymentStatus = "refunded"
+	PaymentStatusVoided    PaymentStatus = "voided"
 )
 
 type Payment struct {
-	ID              uint           `gorm:"primaryKey" json:"id"`
-	WorkOrderID     uint           `gorm:"not null;index" json:"work_order_id"`
-	CashierUserID   *uint          `gorm:"index" json:"cashier_user_id"`
-	ShiftID         *uint          `gorm:"index" json:"shift_id"`
-	PaymentNumber   string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"payment_number"`
-	Method          PaymentMethod  `gorm:"type:varchar(20);not null" json:"method"`
-	Status          PaymentStatus  `gorm:"type:varchar(20);not null" json:"status"`
-	AmountPaid      float64        `gorm:"type:decimal(15,2);not null" json:"amount_paid"`
-	ChangeAmount    float64        `gorm:"type:decimal(15,2);default:0" json:"change_amount"`
-	ReferenceNumber *string        `gorm:"type:varchar(255)" json:"reference_number"`
-	RawPayload      datatypes.JSON `gorm:"type:jsonb" json:"raw_payload"`
-	PaidAt          *time.Time     `json:"paid_at"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
+	ID                   uint           `gorm:"primaryKey" json:"id"`
+	WorkOrderID          uint           `gorm:"not null;index" json:"work_order_id"`
+	CashierUserID        *uint          `gorm:"index" json:"cashier_user_id"`
+	ShiftID              *uint          `gorm:"index" json:"shift_id"`
+	FleetAccountID       *uint          `gorm:"index" json:"fleet_account_id,omitempty"`
+	InvoiceID            *uint          `gorm:"index" json:"invoice_id,omitempty"`
+	PaymentNumber        string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"payment_number"`
+	Method               PaymentMethod  `gorm:"type:varchar(20);not null" json:"method"`
+	Status               PaymentStatus  `gorm:"type:varchar(20);not null" json:"status"`
+	AmountPaid           float64        `gorm:"type:decimal(15,2);not null" json:"amount_paid"`
+	ChangeAmount         float64        `gorm:"type:decimal(15,2);default:0" json:"change_amount"`
+	TipAmount            float64        `gorm:"type:decimal(15,2);default:0" json:"tip_amount"`
+	RefundedAmount       float64        `gorm:"type:decimal(15,2);default:0" json:"refunded_amount"`
+	FeeAmount            float64        `gorm:"type:decimal(15,2);default:0" json:"fee_amount"`
+	NetAmount            float64        `gorm:"type:decimal(15,2);default:0" json:"net_amount"`
+	ReferenceNumber      *string        `gorm:"type:varchar(255)" json:"reference_number"`
+	RawPayload           datatypes.JSON `gorm:"type:jsonb" json:"raw_payload"`
+	QRPayload            *string        `gorm:"type:text" json:"qr_payload,omitempty"`
+	PaidAt               *time.Time     `json:"paid_at"`
+	FailureReason        *string        `gorm:"type:text" json:"failure_reason,omitempty"`
+	RefundRequired       bool           `gorm:"default:false" json:"refund_required"`
+	VoidedAt             *time.Time     `json:"voided_at,omitempty"`
+	VoidReason           *string        `gorm:"type:text" json:"void_reason,omitempty"`
+	VoidedByUserID       *uint          `gorm:"index" json:"voided_by_user_id,omitempty"`
+	VoidApprovedByUserID *uint          `gorm:"index" json:"void_approved_by_user_id,omitempty"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
 
 	// Relations
-	WorkOrder   WorkOrder `gorm:"foreignKey:WorkOrderID" json:"work_order,omitempty"`
-	CashierUser *User     `gorm:"foreignKey:CashierUserID" json:"cashier_user,omitempty"`
-	Shift       *Shift    `gorm:"foreignKey:ShiftID" json:"shift,omitempty"`
+	WorkOrder      WorkOrder       `gorm:"foreignKey:WorkOrderID" json:"work_order,omitempty"`
+	CashierUser    *User           `gorm:"foreignKey:CashierUserID" json:"cashier_user,omitempty"`
+	Shift          *Shift          `gorm:"foreignKey:ShiftID" json:"shift,omitempty"`
+	Tips           []TipAllocation `gorm:"foreignKey:PaymentID" json:"tips,omitempty"`
+	Refunds        []Refund        `gorm:"foreignKey:PaymentID" json:"refunds,omitempty"`
+	VoidedBy       *User           `gorm:"foreignKey:VoidedByUserID" json:"voided_by,omitempty"`
+	VoidApprovedBy *User           `gorm:"foreignKey:VoidApprovedByUserID" json:"void_approved_by,omitempty"`
 }
 
 func (Payment) TableName() string {
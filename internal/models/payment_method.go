@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// PaymentMethodSetting configures a payment method at an outlet. The codes
+// cash, wallet and qris keep their own behaviour: cash goes through the
+// drawer, wallet draws from the customer's stored value and qris issues
+// dynamic QR codes. Any other code is taken as a plain non-cash method.
+type PaymentMethodSetting struct {
+	ID                uint          `gorm:"primaryKey" json:"id"`
+	OutletCode        string        `gorm:"type:varchar(50);not null;uniqueIndex:idx_payment_methods_outlet_code" json:"outlet_code"`
+	Code              PaymentMethod `gorm:"type:varchar(20);not null;uniqueIndex:idx_payment_methods_outlet_code" json:"code"`
+	DisplayName       string        `gorm:"type:varchar(100);not null" json:"display_name"`
+	IsEnabled         bool          `gorm:"default:true" json:"is_enabled"`
+	RequiresReference bool          `gorm:"default:false" json:"requires_reference"`
+	AllowsChange      bool          `gorm:"default:false" json:"allows_change"`
+	// The fee charged on every payment is FeePercentage of the amount
+	// charged plus FeeFixed.
+	FeePercentage float64 `gorm:"type:decimal(5,2);default:0" json:"fee_percentage"`
+	FeeFixed      float64 `gorm:"type:decimal(15,2);default:0" json:"fee_fixed"`
+	// PendingTTLMinutes is how long a payment may stay pending before it
+	// expires; nil uses the server's default.
+	PendingTTLMinutes *int      `json:"pending_ttl_minutes"`
+	SortOrder         int       `gorm:"default:0" json:"sort_order"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func (PaymentMethodSetting) TableName() string {
+	return "payment_methods"
+}
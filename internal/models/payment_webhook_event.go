@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type WebhookEventStatus string
+
+const (
+	WebhookEventProcessed      WebhookEventStatus = "processed"
+	WebhookEventIgnored        WebhookEventStatus = "ignored"
+	WebhookEventUnmatched      WebhookEventStatus = "unmatched"
+	WebhookEventRejected       WebhookEventStatus = "rejected"
+	WebhookEventRefundRequired WebhookEventStatus = "refund_required"
+)
+
+// PaymentWebhookEvent records every verified delivery from a payment
+// provider. The unique provider/event pair makes redeliveries harmless.
+type PaymentWebhookEvent struct {
+	ID         uint               `gorm:"primaryKey" json:"id"`
+	Provider   string             `gorm:"type:varchar(50);not null;uniqueIndex:idx_payment_webhook_events_provider_event" json:"provider"`
+	EventID    string             `gorm:"type:varchar(255);not null;uniqueIndex:idx_payment_webhook_events_provider_event" json:"event_id"`
+	PaymentID  *uint              `gorm:"index" json:"payment_id"`
+	Status     WebhookEventStatus `gorm:"type:varchar(20);not null" json:"status"`
+	Message    *string            `gorm:"type:text" json:"message"`
+	RawBody    datatypes.JSON     `gorm:"type:jsonb" json:"raw_body"`
+	ReceivedAt time.Time          `gorm:"not null" json:"received_at"`
+
+	// Relations
+	Payment *Payment `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+}
+
+func (PaymentWebhookEvent) TableName() string {
+	return "payment_webhook_events"
+}
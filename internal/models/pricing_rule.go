@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PricingAdjustmentType string
+
+const (
+	AdjustmentPercentage PricingAdjustmentType = "percentage"
+	AdjustmentFixed      PricingAdjustmentType = "fixed"
+)
+
+// PricingRule adjusts the price of a product or a whole category during a
+// recurring time window, e.g. a weekday-morning happy hour or a weekend
+// surcharge. Negative adjustment values are discounts, positive ones are
+// surcharges. DaysOfWeek is a comma-separated list of weekdays with Sunday as
+// 0, and StartTime/EndTime are "HH:MM" in the outlet's time zone.
+type PricingRule struct {
+	ID              uint                  `gorm:"primaryKey" json:"id"`
+	Name            string                `gorm:"type:varchar(255);not null" json:"name"`
+	DaysOfWeek      string                `gorm:"type:varchar(20);not null" json:"days_of_week"`
+	StartTime       string                `gorm:"type:varchar(5);not null" json:"start_time"`
+	EndTime         string                `gorm:"type:varchar(5);not null" json:"end_time"`
+	ProductID       *uint                 `gorm:"index" json:"product_id"`
+	CategoryID      *uint                 `gorm:"index" json:"category_id"`
+	AdjustmentType  PricingAdjustmentType `gorm:"type:varchar(20);not null" json:"adjustment_type"`
+	AdjustmentValue float64               `gorm:"type:decimal(15,2);not null" json:"adjustment_value"`
+	Priority        int                   `gorm:"default:0" json:"priority"`
+	IsActive        bool                  `gorm:"default:true" json:"is_active"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt        `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Product  *Product         `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Category *ProductCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
+
+func (PricingRule) TableName() string {
+	return "pricing_rules"
+}
@@ -14,6 +14,9 @@ const (
 	ProductKindRetail  ProductKind = "retail"
 )
 
+// Product is a service, add-on or retail item. Price is the price it was
+// created with and is only used until the product has a price history; the
+// price in effect at any time comes from its ProductPrice entries.
 type Product struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
 	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
@@ -31,6 +34,7 @@ type Product struct {
 	// Relations
 	Category       ProductCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	WorkOrderItems []WorkOrderItem `gorm:"foreignKey:ProductID" json:"work_order_items,omitempty"`
+	Prices         []ProductPrice  `gorm:"foreignKey:ProductID" json:"prices,omitempty"`
 }
 
 func (Product) TableName() string {
@@ -7,13 +7,14 @@ import (
 )
 
 type ProductCategory struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Name      string         `gorm:"type:varchar(255);not null" json:"name"`
-	IconImage *string        `gorm:"type:varchar(255)" json:"icon_image"`
-	IsActive  bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	ID                   uint           `gorm:"primaryKey" json:"id"`
+	Name                 string         `gorm:"type:varchar(255);not null" json:"name"`
+	IconImage            *string        `gorm:"type:varchar(255)" json:"icon_image"`
+	IsActive             bool           `gorm:"default:true" json:"is_active"`
+	MinDepositPercentage float64        `gorm:"type:decimal(5,2);default:0" json:"min_deposit_percentage"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
 	// Relations
 	Products []Product `gorm:"foreignKey:CategoryID" json:"products,omitempty"`
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// ProductPrice is one entry in a product's price history. The entry with the
+// latest EffectiveFrom that is not in the future is the product's current price.
+type ProductPrice struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ProductID       uint      `gorm:"not null;index" json:"product_id"`
+	Price           float64   `gorm:"type:decimal(15,2);not null" json:"price"`
+	EffectiveFrom   time.Time `gorm:"not null;index" json:"effective_from"`
+	CreatedByUserID *uint     `gorm:"index" json:"created_by_user_id"`
+	Note            *string   `gorm:"type:text" json:"note"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	// Relations
+	Product       Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	CreatedByUser *User   `gorm:"foreignKey:CreatedByUserID" json:"created_by_user,omitempty"`
+}
+
+func (ProductPrice) TableName() string {
+	return "product_prices"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Promo is a promo code customers enter at the kiosk, in the app or at the
+// register. Its discount is taken off the order after the membership
+// discount and before tax. A percentage promo takes DiscountValue percent
+// off; a fixed one takes off DiscountValue, but never more than the order.
+type Promo struct {
+	ID            uint                  `gorm:"primaryKey" json:"id"`
+	Code          string                `gorm:"type:varchar(50);uniqueIndex;not null" json:"code"`
+	Name          string                `gorm:"type:varchar(255);not null" json:"name"`
+	DiscountType  PricingAdjustmentType `gorm:"type:varchar(20);not null" json:"discount_type"`
+	DiscountValue float64               `gorm:"type:decimal(15,2);not null" json:"discount_value"`
+	MinSubtotal   float64               `gorm:"type:decimal(15,2);default:0" json:"min_subtotal"`
+	StartsAt      *time.Time            `json:"starts_at"`
+	EndsAt        *time.Time            `json:"ends_at"`
+	MaxUses       *int                  `json:"max_uses"`
+	UsedCount     int                   `gorm:"default:0" json:"used_count"`
+	IsActive      bool                  `gorm:"default:true" json:"is_active"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt        `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (Promo) TableName() string {
+	return "promos"
+}
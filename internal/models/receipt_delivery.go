@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+type ReceiptDeliveryStatus string
+type ReceiptDeliveryTrigger string
+
+const (
+	ReceiptDeliverySent   ReceiptDeliveryStatus = "sent"
+	ReceiptDeliveryFailed ReceiptDeliveryStatus = "failed"
+
+	// Sent automatically once the order is paid, or on request by staff
+	ReceiptTriggerAuto   ReceiptDeliveryTrigger = "auto"
+	ReceiptTriggerResend ReceiptDeliveryTrigger = "resend"
+)
+
+// ReceiptDelivery logs one attempt to email a work order's e-receipt.
+type ReceiptDelivery struct {
+	ID                uint                   `gorm:"primaryKey" json:"id"`
+	WorkOrderID       uint                   `gorm:"not null;index" json:"work_order_id"`
+	Channel           string                 `gorm:"type:varchar(20);not null;default:'email'" json:"channel"`
+	Recipient         string                 `gorm:"type:varchar(255);not null" json:"recipient"`
+	Trigger           ReceiptDeliveryTrigger `gorm:"type:varchar(20);not null" json:"trigger"`
+	Status            ReceiptDeliveryStatus  `gorm:"type:varchar(20);not null" json:"status"`
+	Error             *string                `gorm:"type:text" json:"error"`
+	RequestedByUserID *uint                  `gorm:"index" json:"requested_by_user_id"`
+	SentAt            *time.Time             `json:"sent_at"`
+	CreatedAt         time.Time              `json:"created_at"`
+
+	// Relations
+	WorkOrder   *WorkOrder `gorm:"foreignKey:WorkOrderID" json:"work_order,omitempty"`
+	RequestedBy *User      `gorm:"foreignKey:RequestedByUserID" json:"requested_by,omitempty"`
+}
+
+func (ReceiptDelivery) TableName() string {
+	return "receipt_deliveries"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// Refund gives back part or all of a completed payment. The payment itself
+// is never rewritten; its RefundedAmount keeps the running total.
+type Refund struct {
+	ID               uint          `gorm:"primaryKey" json:"id"`
+	RefundNumber     string        `gorm:"type:varchar(100);uniqueIndex;not null" json:"refund_number"`
+	PaymentID        uint          `gorm:"not null;index" json:"payment_id"`
+	WorkOrderID      uint          `gorm:"not null;index" json:"work_order_id"`
+	ShiftID          *uint         `gorm:"index" json:"shift_id"`
+	Method           PaymentMethod `gorm:"type:varchar(20);not null" json:"method"`
+	Amount           float64       `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Reason           string        `gorm:"type:text;not null" json:"reason"`
+	ReferenceNumber  *string       `gorm:"type:varchar(255)" json:"reference_number"`
+	ApprovedByUserID *uint         `gorm:"index" json:"approved_by_user_id"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+
+	// Relations
+	Payment    Payment    `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+	WorkOrder  *WorkOrder `gorm:"foreignKey:WorkOrderID" json:"work_order,omitempty"`
+	Shift      *Shift     `gorm:"foreignKey:ShiftID" json:"shift,omitempty"`
+	ApprovedBy *User      `gorm:"foreignKey:ApprovedByUserID" json:"approved_by,omitempty"`
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+type SettlementLineStatus string
+
+const (
+	// SettlementLineMatched lines settle a payment for its expected amount.
+	SettlementLineMatched SettlementLineStatus = "matched"
+	// SettlementLineAmountMismatch lines settle a payment for a different
+	// amount than it was taken for.
+	SettlementLineAmountMismatch SettlementLineStatus = "amount_mismatch"
+	// SettlementLineUnmatched lines have no settleable payment with their
+	// reference.
+	SettlementLineUnmatched SettlementLineStatus = "unmatched"
+	// SettlementLineDuplicate lines settle a payment another line already
+	// settled.
+	SettlementLineDuplicate SettlementLineStatus = "duplicate"
+)
+
+// SettlementBatch is one imported settlement file.
+type SettlementBatch struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	Layout           string    `gorm:"type:varchar(50);not null;index" json:"layout"`
+	Filename         string    `gorm:"type:varchar(255);not null" json:"filename"`
+	FileHash         string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"file_hash"`
+	LineCount        int       `gorm:"not null" json:"line_count"`
+	GrossAmount      float64   `gorm:"type:decimal(15,2);not null" json:"gross_amount"`
+	MDRAmount        float64   `gorm:"type:decimal(15,2);not null" json:"mdr_amount"`
+	NetAmount        float64   `gorm:"type:decimal(15,2);not null" json:"net_amount"`
+	ImportedByUserID *uint     `gorm:"index" json:"imported_by_user_id"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	// Relations
+	ImportedBy *User            `gorm:"foreignKey:ImportedByUserID" json:"imported_by,omitempty"`
+	Lines      []SettlementLine `gorm:"foreignKey:BatchID" json:"lines,omitempty"`
+}
+
+func (SettlementBatch) TableName() string {
+	return "settlement_batches"
+}
+
+// SettlementLine is one transaction of a settlement file and the payment it
+// was matched to.
+type SettlementLine struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	BatchID   uint   `gorm:"not null;index" json:"batch_id"`
+	Layout    string `gorm:"type:varchar(50);not null" json:"layout"`
+	RowNumber int    `gorm:"not null" json:"row_number"`
+	// SettlementDate is formatted YYYY-MM-DD.
+	SettlementDate   string               `gorm:"type:varchar(10);not null" json:"settlement_date"`
+	Reference        string               `gorm:"type:varchar(255);not null;index" json:"reference"`
+	GrossAmount      float64              `gorm:"type:decimal(15,2);not null" json:"gross_amount"`
+	MDRAmount        float64              `gorm:"type:decimal(15,2);not null" json:"mdr_amount"`
+	NetAmount        float64              `gorm:"type:decimal(15,2);not null" json:"net_amount"`
+	PaymentID        *uint                `gorm:"index" json:"payment_id"`
+	ExpectedAmount   *float64             `gorm:"type:decimal(15,2)" json:"expected_amount"`
+	DifferenceAmount float64              `gorm:"type:decimal(15,2);default:0" json:"difference_amount"`
+	Status           SettlementLineStatus `gorm:"type:varchar(20);not null" json:"status"`
+	Note             *string              `gorm:"type:text" json:"note"`
+	CreatedAt        time.Time            `json:"created_at"`
+
+	// Relations
+	Payment *Payment `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+}
+
+func (SettlementLine) TableName() string {
+	return "settlement_lines"
+}
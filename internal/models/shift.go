@@ -13,24 +13,91 @@ const (
 )
 
 type Shift struct {
-	ID           uint        `gorm:"primaryKey" json:"id"`
-	UserID       uint        `gorm:"not null;index" json:"user_id"`
-	StartTime    time.Time   `gorm:"not null" json:"start_time"`
-	EndTime      *time.Time  `json:"end_time"`
-	InitialCash  float64     `gorm:"type:decimal(15,2);default:0" json:"initial_cash"`
-	FinalCash    float64     `gorm:"type:decimal(15,2);default:0" json:"final_cash"`
-	TotalSales   float64     `gorm:"type:decimal(15,2);default:0" json:"total_sales"`
-	Status       ShiftStatus `gorm:"type:varchar(20);not null" json:"status"`
-	ReceivedFrom *string     `gorm:"type:varchar(255)" json:"received_from"`
-	CreatedAt    time.Time   `json:"created_at"`
-	UpdatedAt    time.Time   `json:"updated_at"`
+	ID          uint        `gorm:"primaryKey" json:"id"`
+	UserID      uint        `gorm:"not null;index" json:"user_id"`
+	StartTime   time.Time   `gorm:"not null" json:"start_time"`
+	EndTime     *time.Time  `json:"end_time"`
+	InitialCash float64     `gorm:"type:decimal(15,2);default:0" json:"initial_cash"`
+	FinalCash   float64     `gorm:"type:decimal(15,2);default:0" json:"final_cash"`
+	TotalSales  float64     `gorm:"type:decimal(15,2);default:0" json:"total_sales"`
+	TotalTips   float64     `gorm:"type:decimal(15,2);default:0" json:"total_tips"`
+	Status      ShiftStatus `gorm:"type:varchar(20);not null" json:"status"`
+
+	// Set when the shift is closed. FinalCash is the counted drawer, and
+	// CashVariance how far it is over (positive) or short (negative) of
+	// ExpectedCash
+	CashSales      float64 `gorm:"type:decimal(15,2);default:0" json:"cash_sales"`
+	NonCashSales   float64 `gorm:"type:decimal(15,2);default:0" json:"non_cash_sales"`
+	ExpectedCash   float64 `gorm:"type:decimal(15,2);default:0" json:"expected_cash"`
+	CashVariance   float64 `gorm:"type:decimal(15,2);default:0" json:"cash_variance"`
+	VarianceNote   *string `gorm:"type:text" json:"variance_note"`
+	ClosedByUserID *uint   `json:"closed_by_user_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 
 	// Relations
-	User       User        `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	WorkOrders []WorkOrder `gorm:"foreignKey:ShiftID" json:"work_orders,omitempty"`
-	Payments   []Payment   `gorm:"foreignKey:ShiftID" json:"payments,omitempty"`
+	User       User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	WorkOrders []WorkOrder      `gorm:"foreignKey:ShiftID" json:"work_orders,omitempty"`
+	Payments   []Payment        `gorm:"foreignKey:ShiftID" json:"payments,omitempty"`
+	CashCounts []ShiftCashCount `gorm:"foreignKey:ShiftID" json:"cash_counts,omitempty"`
+	// The float received from the previous cashier and the one handed to
+	// the next
+	HandoverIn  *ShiftHandover `gorm:"foreignKey:ToShiftID" json:"handover_in,omitempty"`
+	HandoverOut *ShiftHandover `gorm:"foreignKey:FromShiftID" json:"handover_out,omitempty"`
 }
 
 func (Shift) TableName() string {
 	return "shifts"
 }
+
+// ShiftCashCount is how many of one note or coin were counted in the drawer
+// when a shift was closed.
+type ShiftCashCount struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ShiftID      uint      `gorm:"not null;index" json:"shift_id"`
+	Denomination float64   `gorm:"type:decimal(15,2);not null" json:"denomination"`
+	Quantity     int       `gorm:"not null" json:"quantity"`
+	Amount       float64   `gorm:"type:decimal(15,2);not null" json:"amount"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (ShiftCashCount) TableName() string {
+	return "shift_cash_counts"
+}
+
+type ShiftHandoverStatus string
+
+const (
+	HandoverStatusPending      ShiftHandoverStatus = "pending"
+	HandoverStatusAcknowledged ShiftHandoverStatus = "acknowledged"
+)
+
+// ShiftHandover is a drawer float passed from a closing shift to the next
+// cashier. It stays pending until the receiving cashier counts the float and
+// starts their shift with it; a received amount that differs from the
+// amount handed over is flagged as a discrepancy.
+type ShiftHandover struct {
+	ID               uint                `gorm:"primaryKey" json:"id"`
+	FromShiftID      uint                `gorm:"not null;uniqueIndex" json:"from_shift_id"`
+	FromUserID       uint                `gorm:"not null;index" json:"from_user_id"`
+	ToUserID         uint                `gorm:"not null;index" json:"to_user_id"`
+	ToShiftID        *uint               `gorm:"uniqueIndex" json:"to_shift_id"`
+	HandedOverAmount float64             `gorm:"type:decimal(15,2);not null" json:"handed_over_amount"`
+	ReceivedAmount   *float64            `gorm:"type:decimal(15,2)" json:"received_amount"`
+	Discrepancy      float64             `gorm:"type:decimal(15,2);default:0" json:"discrepancy"`
+	IsFlagged        bool                `gorm:"default:false;index" json:"is_flagged"`
+	Status           ShiftHandoverStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Note             *string             `gorm:"type:text" json:"note"`
+	AcknowledgedAt   *time.Time          `json:"acknowledged_at"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+
+	// Relations
+	FromUser *User `gorm:"foreignKey:FromUserID" json:"from_user,omitempty"`
+	ToUser   *User `gorm:"foreignKey:ToUserID" json:"to_user,omitempty"`
+}
+
+func (ShiftHandover) TableName() string {
+	return "shift_handovers"
+}
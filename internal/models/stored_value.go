@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+)
+
+type StoredValueAccountType string
+type StoredValueEntryType string
+type GiftCardStatus string
+
+const (
+	AccountWallet   StoredValueAccountType = "wallet"
+	AccountGiftCard StoredValueAccountType = "gift_card"
+
+	EntryTopUp              StoredValueEntryType = "top_up"
+	EntryGiftCardIssue      StoredValueEntryType = "gift_card_issue"
+	EntryGiftCardRedemption StoredValueEntryType = "gift_card_redemption"
+	EntryPayment            StoredValueEntryType = "payment"
+	EntryRefund             StoredValueEntryType = "refund"
+	EntryVoid               StoredValueEntryType = "void"
+	EntryExpiry             StoredValueEntryType = "expiry"
+
+	GiftCardStatusActive   GiftCardStatus = "active"
+	GiftCardStatusRedeemed GiftCardStatus = "redeemed"
+	GiftCardStatusExpired  GiftCardStatus = "expired"
+)
+
+// StoredValueEntry is one movement on a stored-value account. Entries are
+// append-only: the balance of an account is the sum of its entries, and
+// BalanceAfter records the running balance at the time of writing. For
+// wallet accounts AccountID is the customer's user ID, for gift card
+// accounts it is the gift card ID. Top-ups and gift card sales record the
+// method the customer paid with and the shift the money was taken in.
+type StoredValueEntry struct {
+	ID              uint                   `gorm:"primaryKey" json:"id"`
+	AccountType     StoredValueAccountType `gorm:"type:varchar(20);not null" json:"account_type"`
+	AccountID       uint                   `gorm:"not null" json:"account_id"`
+	EntryType       StoredValueEntryType   `gorm:"type:varchar(30);not null" json:"entry_type"`
+	Amount          float64                `gorm:"type:decimal(15,2);not null" json:"amount"`
+	BalanceAfter    float64                `gorm:"type:decimal(15,2);not null" json:"balance_after"`
+	PaymentID       *uint                  `gorm:"index" json:"payment_id"`
+	GiftCardID      *uint                  `gorm:"index" json:"gift_card_id"`
+	TenderMethod    *PaymentMethod         `gorm:"type:varchar(20)" json:"tender_method"`
+	ShiftID         *uint                  `gorm:"index" json:"shift_id"`
+	ReferenceNumber *string                `gorm:"type:varchar(255)" json:"reference_number"`
+	Note            *string                `gorm:"type:text" json:"note"`
+	CreatedByUserID *uint                  `gorm:"index" json:"created_by_user_id"`
+	CreatedAt       time.Time              `json:"created_at"`
+
+	// Relations
+	Payment       *Payment  `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+	GiftCard      *GiftCard `gorm:"foreignKey:GiftCardID" json:"gift_card,omitempty"`
+	Shift         *Shift    `gorm:"foreignKey:ShiftID" json:"shift,omitempty"`
+	CreatedByUser *User     `gorm:"foreignKey:CreatedByUserID" json:"created_by_user,omitempty"`
+}
+
+func (StoredValueEntry) TableName() string {
+	return "stored_value_entries"
+}
+
+// GiftCard is a redeemable code carrying a stored value. Redeeming a card
+// moves its whole balance into the customer's wallet.
+type GiftCard struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	Code             string         `gorm:"type:varchar(32);uniqueIndex;not null" json:"code"`
+	InitialAmount    float64        `gorm:"type:decimal(15,2);not null" json:"initial_amount"`
+	Status           GiftCardStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	ExpiresAt        *time.Time     `json:"expires_at"`
+	IssuedByUserID   *uint          `gorm:"index" json:"issued_by_user_id"`
+	RedeemedByUserID *uint          `gorm:"index" json:"redeemed_by_user_id"`
+	RedeemedAt       *time.Time     `json:"redeemed_at"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+
+	// Relations
+	IssuedByUser   *User `gorm:"foreignKey:IssuedByUserID" json:"issued_by_user,omitempty"`
+	RedeemedByUser *User `gorm:"foreignKey:RedeemedByUserID" json:"redeemed_by_user,omitempty"`
+}
+
+func (GiftCard) TableName() string {
+	return "gift_cards"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+type TipSplitRule string
+
+const (
+	TipSplitEqual       TipSplitRule = "equal"
+	TipSplitByItemValue TipSplitRule = "by_item_value"
+)
+
+// TipAllocation is one staff member's share of the tip left on a payment.
+// Tips on orders without assigned staff are kept with a nil StaffUserID.
+type TipAllocation struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	PaymentID   uint      `gorm:"not null;index" json:"payment_id"`
+	WorkOrderID uint      `gorm:"not null;index" json:"work_order_id"`
+	StaffUserID *uint     `gorm:"index" json:"staff_user_id"`
+	ShiftID     *uint     `gorm:"index" json:"shift_id"`
+	Amount      float64   `gorm:"type:decimal(15,2);not null" json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Relations
+	Payment   Payment `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+	StaffUser *User   `gorm:"foreignKey:StaffUserID" json:"staff_user,omitempty"`
+}
+
+func (TipAllocation) TableName() string {
+	return "tip_allocations"
+}
@@ -36,18 +36,21 @@ type User struct {
 	FCMToken            *string        `gorm:"type:varchar(255)" json:"fcm_token"`
 	EmailVerifiedAt     *time.Time     `json:"email_verified_at"`
 	RememberToken       *string        `gorm:"type:varchar(100)" json:"-"`
+	SupervisorPINHash   *string        `gorm:"type:varchar(255)" json:"-"`
+	PINFailures         int            `gorm:"default:0" json:"-"`
+	PINLockedUntil      *time.Time     `json:"-"`
 	CreatedAt           time.Time      `json:"created_at"`
 	UpdatedAt           time.Time      `json:"updated_at"`
 	DeletedAt           gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
 	// Relations
-	MembershipType    *MembershipType    `gorm:"foreignKey:MembershipTypeID" json:"membership_type,omitempty"`
-	DeviceFCMTokens   []DeviceFCMToken   `gorm:"foreignKey:UserID" json:"device_fcm_tokens,omitempty"`
-	CustomerVehicles  []CustomerVehicle  `gorm:"foreignKey:CustomerID" json:"customer_vehicles,omitempty"`
-	WorkOrders        []WorkOrder        `gorm:"foreignKey:CustomerUserID" json:"work_orders,omitempty"`
-	HandledWorkOrders []WorkOrder        `gorm:"foreignKey:CashierUserID" json:"handled_work_orders,omitempty"`
-	Payments          []Payment          `gorm:"foreignKey:CashierUserID" json:"payments,omitempty"`
-	Shifts            []Shift            `gorm:"foreignKey:UserID" json:"shifts,omitempty"`
+	MembershipType    *MembershipType   `gorm:"foreignKey:MembershipTypeID" json:"membership_type,omitempty"`
+	DeviceFCMTokens   []DeviceFCMToken  `gorm:"foreignKey:UserID" json:"device_fcm_tokens,omitempty"`
+	CustomerVehicles  []CustomerVehicle `gorm:"foreignKey:CustomerID" json:"customer_vehicles,omitempty"`
+	WorkOrders        []WorkOrder       `gorm:"foreignKey:CustomerUserID" json:"work_orders,omitempty"`
+	HandledWorkOrders []WorkOrder       `gorm:"foreignKey:CashierUserID" json:"handled_work_orders,omitempty"`
+	Payments          []Payment         `gorm:"foreignKey:CashierUserID" json:"payments,omitempty"`
+	Shifts            []Shift           `gorm:"foreignKey:UserID" json:"shifts,omitempty"`
 }
 
 func (User) TableName() string {
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VehiclePriceTier adjusts the price of a product or a whole category for a
+// vehicle type, e.g. a surcharge for SUVs on a full wash. It is applied to
+// the product's price before any time-based pricing rule. Negative
+// adjustment values are discounts, positive ones are surcharges.
+type VehiclePriceTier struct {
+	ID              uint                  `gorm:"primaryKey" json:"id"`
+	VehicleType     string                `gorm:"type:varchar(50);not null;index" json:"vehicle_type"`
+	ProductID       *uint                 `gorm:"index" json:"product_id"`
+	CategoryID      *uint                 `gorm:"index" json:"category_id"`
+	AdjustmentType  PricingAdjustmentType `gorm:"type:varchar(20);not null" json:"adjustment_type"`
+	AdjustmentValue float64               `gorm:"type:decimal(15,2);not null" json:"adjustment_value"`
+	IsActive        bool                  `gorm:"default:true" json:"is_active"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt        `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Product  *Product         `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Category *ProductCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
+
+func (VehiclePriceTier) TableName() string {
+	return "vehicle_price_tiers"
+}
@@ -0,0 +1,265 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ESC/POS command bytes.
+var (
+	escInit        = []byte{0x1B, 0x40}
+	escAlignLeft   = []byte{0x1B, 0x61, 0x00}
+	escAlignCenter = []byte{0x1B, 0x61, 0x01}
+	escBoldOn      = []byte{0x1B, 0x45, 0x01}
+	escBoldOff     = []byte{0x1B, 0x45, 0x00}
+	escDoubleSize  = []byte{0x1D, 0x21, 0x11}
+	escNormalSize  = []byte{0x1D, 0x21, 0x00}
+	// Pulse drawer pin 2 for 50ms on, 500ms off
+	escDrawerKick = []byte{0x1B, 0x70, 0x00, 0x19, 0xFA}
+	// Feed and partial cut
+	escCut = []byte{0x1D, 0x56, 0x42, 0x00}
+)
+
+// Columns returns how many characters of the standard font fit on a line of
+// the given thermal paper.
+func Columns(paper string) (int, error) {
+	switch paper {
+	case Paper58mm:
+		return 32, nil
+	case Paper80mm:
+		return 48, nil
+	default:
+		return 0, ErrUnsupportedPaper
+	}
+}
+
+// ESCPOS renders the receipt as an ESC/POS byte stream for a 58mm or 80mm
+// thermal printer. Text outside ASCII is replaced, as printers default to a
+// single-byte code page.
+func ESCPOS(r *Receipt, paper string) ([]byte, error) {
+	width, err := Columns(paper)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &escposWriter{width: width}
+	w.write(escInit)
+
+	// Outlet header
+	w.write(escAlignCenter)
+	if r.Header.OutletName != "" {
+		w.write(escBoldOn, escDoubleSize)
+		w.text(truncateText(asciiOnly(r.Header.OutletName), width/2))
+		w.write(escNormalSize, escBoldOff)
+	}
+	for _, line := range []string{r.Header.Address, r.Header.Phone} {
+		if line != "" {
+			w.wrapped(line)
+		}
+	}
+	if r.Header.TaxID != "" {
+		w.text("NPWP " + r.Header.TaxID)
+	}
+	w.write(escAlignLeft)
+	w.rule()
+
+	// Order details
+	w.pair("No", r.OrderNumber)
+	if r.QueueNumber != nil {
+		w.pair("Queue", fmt.Sprintf("%d", *r.QueueNumber))
+	}
+	w.pair("Date", r.IssuedAt.Format("02/01/2006 15:04"))
+	if r.Cashier != "" {
+		w.pair("Cashier", r.Cashier)
+	}
+	if r.Customer != "" {
+		w.pair("Customer", r.Customer)
+	}
+	if r.Vehicle != "" {
+		w.pair("Vehicle", r.Vehicle)
+	}
+	w.rule()
+
+	// Items
+	for _, line := range r.Lines {
+		w.wrapped(line.Name)
+		w.pair(fmt.Sprintf("  %d x %s", line.Quantity, FormatAmount(line.UnitPrice)), FormatAmount(line.Subtotal))
+		if line.Note != "" {
+			w.indented(line.Note, 2)
+		}
+	}
+	w.rule()
+
+	// Totals and tax breakdown
+	w.pair("Subtotal", FormatAmount(r.Subtotal))
+	if r.DiscountAmount > 0 {
+		w.pair("Discount", "-"+FormatAmount(r.DiscountAmount))
+	}
+	if r.TaxAmount > 0 {
+		w.pair("DPP", FormatAmount(r.TaxBase()))
+		w.pair("PPN "+formatRate(r.TaxRate)+"%", FormatAmount(r.TaxAmount))
+	}
+	w.write(escBoldOn)
+	w.pair("TOTAL", FormatAmount(r.TotalAmount))
+	w.write(escBoldOff)
+	w.rule()
+
+	// Payments
+	for _, tender := range r.Tenders {
+		w.pair(methodLabel(tender.Method), FormatAmount(tender.Amount))
+		if tender.Tip > 0 {
+			w.pair("  Tip", FormatAmount(tender.Tip))
+		}
+	}
+	if r.ChangeAmount > 0 {
+		w.pair("Change", FormatAmount(r.ChangeAmount))
+	}
+	if r.RefundedAmount > 0 {
+		w.pair("Refunded", "-"+FormatAmount(r.RefundedAmount))
+	}
+	if r.OutstandingAmount > 0 {
+		w.write(escBoldOn)
+		w.pair("Outstanding", FormatAmount(r.OutstandingAmount))
+		w.write(escBoldOff)
+	}
+
+	// Footer and QR code
+	w.write(escAlignCenter)
+	if len(r.Header.Footer) > 0 {
+		w.rule()
+		for _, line := range r.Header.Footer {
+			w.wrapped(line)
+		}
+	}
+	if r.QRContent != "" {
+		w.feed(1)
+		w.qr(r.QRContent)
+	}
+	w.write(escAlignLeft)
+	w.feed(3)
+	w.write(escCut)
+
+	if r.OpenDrawer {
+		w.write(escDrawerKick)
+	}
+
+	return w.buf.Bytes(), nil
+}
+
+type escposWriter struct {
+	buf   bytes.Buffer
+	width int
+}
+
+func (w *escposWriter) write(commands ...[]byte) {
+	for _, command := range commands {
+		w.buf.Write(command)
+	}
+}
+
+func (w *escposWriter) text(line string) {
+	w.buf.WriteString(asciiOnly(line))
+	w.buf.WriteByte('\n')
+}
+
+func (w *escposWriter) feed(lines int) {
+	w.buf.WriteString(strings.Repeat("\n", lines))
+}
+
+func (w *escposWriter) rule() {
+	w.text(strings.Repeat("-", w.width))
+}
+
+// pair prints a label on the left and a value on the right of one line.
+// Values too long for the line are cut to leave room for at least the
+// label's first character and a space.
+func (w *escposWriter) pair(label, value string) {
+	label, value = asciiOnly(label), asciiOnly(value)
+	value = truncateText(value, w.width-2)
+	label = truncateText(label, w.width-len(value)-1)
+	padding := w.width - len(label) - len(value)
+	if padding < 1 {
+		padding = 1
+	}
+	w.text(label + strings.Repeat(" ", padding) + value)
+}
+
+// wrapped prints text over as many lines as it needs.
+func (w *escposWriter) wrapped(text string) {
+	for _, line := range wrapText(asciiOnly(text), w.width) {
+		w.text(line)
+	}
+}
+
+// indented prints wrapped text with every line indented.
+func (w *escposWriter) indented(text string, indent int) {
+	prefix := strings.Repeat(" ", indent)
+	for _, line := range wrapText(asciiOnly(text), w.width-indent) {
+		w.text(prefix + line)
+	}
+}
+
+// qr prints a QR code with the printer's built-in model 2 generator.
+func (w *escposWriter) qr(content string) {
+	data := []byte(content)
+	size := len(data) + 3
+	w.write(
+		[]byte{0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00}, // model 2
+		[]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, 0x06},       // module size
+		[]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, 0x31},       // error correction M
+		[]byte{0x1D, 0x28, 0x6B, byte(size % 256), byte(size / 256), 0x31, 0x50, 0x30},
+		data,
+		[]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30}, // print
+	)
+	w.buf.WriteByte('\n')
+}
+
+func asciiOnly(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if r < 0x20 || r > 0x7E {
+			b.WriteByte('?')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func truncateText(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	return text[:max]
+}
+
+// wrapText breaks text into lines of at most width characters, splitting on
+// spaces where possible.
+func wrapText(text string, width int) []string {
+	var lines []string
+	var current string
+	for _, word := range strings.Fields(text) {
+		for len(word) > width {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			lines = append(lines, word[:width])
+			word = word[width:]
+		}
+		switch {
+		case current == "":
+			current = word
+		case len(current)+1+len(word) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
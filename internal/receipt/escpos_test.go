@@ -0,0 +1,71 @@
+package receipt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestESCPOSLongValues(t *testing.T) {
+	longName := "Raden Mas Bagus Hadiningrat Kusumawardhana Prawirodirjo Notonegoro"
+	longPlate := "B 1234 ABC / D 5678 EFG / F 9012 HIJ / Toyota Kijang Innova"
+
+	for _, paper := range []string{Paper58mm, Paper80mm} {
+		width, err := Columns(paper)
+		if err != nil {
+			t.Fatalf("Columns(%s): %v", paper, err)
+		}
+
+		r := &Receipt{
+			OrderNumber: strings.Repeat("WO-20240115-0001", 4),
+			IssuedAt:    time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+			Cashier:     longName,
+			Customer:    longName,
+			Vehicle:     longPlate,
+			Lines: []Line{
+				{Name: "Premium Wash", Quantity: 1, UnitPrice: 50000, Subtotal: 50000},
+			},
+			Subtotal:    50000,
+			TotalAmount: 50000,
+		}
+		output, err := ESCPOS(r, paper)
+		if err != nil {
+			t.Fatalf("ESCPOS(%s): %v", paper, err)
+		}
+
+		// Each value is cut to leave a character of its label and a space
+		for _, value := range []string{r.OrderNumber, longName, longPlate} {
+			line := findLine(string(output), value[:width-2])
+			if line == "" {
+				t.Errorf("%s: %q is not printed", paper, value[:width-2])
+				continue
+			}
+			if len(line) != width || line[1] != ' ' {
+				t.Errorf("%s: got line %q, want %d characters with the label cut to one", paper, line, width)
+			}
+		}
+	}
+}
+
+func TestESCPOSPairFitsWidth(t *testing.T) {
+	w := &escposWriter{width: 32}
+	values := []string{"", "x", strings.Repeat("v", 30), strings.Repeat("v", 31), strings.Repeat("v", 32), strings.Repeat("v", 80)}
+	for _, value := range values {
+		w.buf.Reset()
+		w.pair("Customer", value)
+		line := strings.TrimSuffix(w.buf.String(), "\n")
+		if len(line) != 32 {
+			t.Errorf("pair(Customer, %d chars) printed %d characters, want 32: %q", len(value), len(line), line)
+		}
+	}
+}
+
+// findLine returns the first printed line containing text.
+func findLine(output, text string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, text) {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// pdfLayout holds the dimensions of a PDF receipt, in millimetres and points.
+type pdfLayout struct {
+	width      float64
+	margin     float64
+	fontSize   float64
+	titleSize  float64
+	lineHeight float64
+	qrSize     float64
+}
+
+var pdfLayouts = map[string]pdfLayout{
+	PaperA4:   {width: 210, margin: 20, fontSize: 10, titleSize: 16, lineHeight: 5.5, qrSize: 35},
+	Paper80mm: {width: 80, margin: 4, fontSize: 8, titleSize: 12, lineHeight: 4, qrSize: 30},
+}
+
+// thermalPageHeight is the height of the scratch page a thermal receipt is
+// first laid out on to measure how long it is.
+const thermalPageHeight = 2000
+
+// PDF renders the receipt as a PDF document on A4 paper or on an 80mm roll.
+// Roll receipts are a single page exactly as long as their content.
+func PDF(r *Receipt, paper string) ([]byte, error) {
+	layout, ok := pdfLayouts[paper]
+	if !ok {
+		return nil, ErrUnsupportedPaper
+	}
+
+	var qrImage []byte
+	if r.QRContent != "" {
+		image, err := qrcode.Encode(r.QRContent, qrcode.Medium, 256)
+		if err != nil {
+			return nil, err
+		}
+		qrImage = image
+	}
+
+	if paper == PaperA4 {
+		pdf := fpdf.New("P", "mm", "A4", "")
+		drawPDF(pdf, layout, r, qrImage)
+		return outputPDF(pdf)
+	}
+
+	// Lay the roll out once on a long page to find its length, then again on
+	// a page cut to size
+	scratch := newRollPDF(layout, thermalPageHeight)
+	drawPDF(scratch, layout, r, qrImage)
+	if err := scratch.Error(); err != nil {
+		return nil, err
+	}
+
+	pdf := newRollPDF(layout, scratch.GetY()+layout.margin)
+	drawPDF(pdf, layout, r, qrImage)
+	return outputPDF(pdf)
+}
+
+func newRollPDF(layout pdfLayout, height float64) *fpdf.Fpdf {
+	return fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: layout.width, Ht: height},
+	})
+}
+
+func outputPDF(pdf *fpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawPDF(pdf *fpdf.Fpdf, layout pdfLayout, r *Receipt, qrImage []byte) {
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	contentWidth := layout.width - 2*layout.margin
+	lh := layout.lineHeight
+
+	pdf.SetMargins(layout.margin, layout.margin, layout.margin)
+	pdf.SetAutoPageBreak(true, layout.margin)
+	pdf.AddPage()
+
+	rule := func() {
+		y := pdf.GetY() + lh/2
+		pdf.Line(layout.margin, y, layout.width-layout.margin, y)
+		pdf.Ln(lh)
+	}
+	pair := func(label, value string) {
+		pdf.CellFormat(contentWidth*0.6, lh, tr(label), "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentWidth*0.4, lh, tr(value), "", 1, "R", false, 0, "")
+	}
+	centered := func(text string) {
+		pdf.MultiCell(contentWidth, lh, tr(text), "", "C", false)
+	}
+
+	// Outlet header
+	if r.Header.OutletName != "" {
+		pdf.SetFont("Helvetica", "B", layout.titleSize)
+		pdf.MultiCell(contentWidth, lh*1.6, tr(r.Header.OutletName), "", "C", false)
+	}
+	pdf.SetFont("Helvetica", "", layout.fontSize)
+	for _, line := range []string{r.Header.Address, r.Header.Phone} {
+		if line != "" {
+			centered(line)
+		}
+	}
+	if r.Header.TaxID != "" {
+		centered("NPWP " + r.Header.TaxID)
+	}
+	rule()
+
+	// Order details
+	pair("No", r.OrderNumber)
+	if r.QueueNumber != nil {
+		pair("Queue", fmt.Sprintf("%d", *r.QueueNumber))
+	}
+	pair("Date", r.IssuedAt.Format("02/01/2006 15:04"))
+	if r.Cashier != "" {
+		pair("Cashier", r.Cashier)
+	}
+	if r.Customer != "" {
+		pair("Customer", r.Customer)
+	}
+	if r.Vehicle != "" {
+		pair("Vehicle", r.Vehicle)
+	}
+	rule()
+
+	// Items
+	for _, line := range r.Lines {
+		pdf.MultiCell(contentWidth, lh, tr(line.Name), "", "L", false)
+		pair(fmt.Sprintf("   %d x %s", line.Quantity, FormatAmount(line.UnitPrice)), FormatAmount(line.Subtotal))
+		if line.Note != "" {
+			pdf.SetFont("Helvetica", "I", layout.fontSize)
+			pdf.MultiCell(contentWidth, lh, tr("   "+line.Note), "", "L", false)
+			pdf.SetFont("Helvetica", "", layout.fontSize)
+		}
+	}
+	rule()
+
+	// Totals and tax breakdown
+	pair("Subtotal", FormatAmount(r.Subtotal))
+	if r.DiscountAmount > 0 {
+		pair("Discount", "-"+FormatAmount(r.DiscountAmount))
+	}
+	if r.TaxAmount > 0 {
+		pair("DPP", FormatAmount(r.TaxBase()))
+		pair("PPN "+formatRate(r.TaxRate)+"%", FormatAmount(r.TaxAmount))
+	}
+	pdf.SetFont("Helvetica", "B", layout.fontSize)
+	pair("TOTAL", FormatAmount(r.TotalAmount))
+	pdf.SetFont("Helvetica", "", layout.fontSize)
+	rule()
+
+	// Payments
+	for _, tender := range r.Tenders {
+		pair(methodLabel(tender.Method)+" "+tender.PaymentNumber, FormatAmount(tender.Amount))
+		if tender.Tip > 0 {
+			pair("   Tip", FormatAmount(tender.Tip))
+		}
+	}
+	if r.ChangeAmount > 0 {
+		pair("Change", FormatAmount(r.ChangeAmount))
+	}
+	if r.RefundedAmount > 0 {
+		pair("Refunded", "-"+FormatAmount(r.RefundedAmount))
+	}
+	if r.OutstandingAmount > 0 {
+		pdf.SetFont("Helvetica", "B", layout.fontSize)
+		pair("Outstanding", FormatAmount(r.OutstandingAmount))
+		pdf.SetFont("Helvetica", "", layout.fontSize)
+	}
+
+	// Footer and QR code
+	if len(r.Header.Footer) > 0 {
+		rule()
+		for _, line := range r.Header.Footer {
+			centered(line)
+		}
+	}
+	if qrImage != nil {
+		pdf.Ln(lh / 2)
+		options := fpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader("receipt-qr", options, bytes.NewReader(qrImage))
+		x := (layout.width - layout.qrSize) / 2
+		pdf.ImageOptions("receipt-qr", x, pdf.GetY(), layout.qrSize, layout.qrSize, true, options, 0, "")
+	}
+}
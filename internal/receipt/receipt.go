@@ -0,0 +1,135 @@
+// Package receipt renders customer receipts for a work order, either as an
+// ESC/POS byte stream for thermal printers or as a PDF document.
+package receipt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Paper sizes a receipt can be rendered on.
+const (
+	Paper58mm = "58mm"
+	Paper80mm = "80mm"
+	PaperA4   = "a4"
+)
+
+var ErrUnsupportedPaper = errors.New("unsupported paper size")
+
+// Header is the outlet information printed at the top and bottom of every
+// receipt.
+type Header struct {
+	OutletName string
+	Address    string
+	Phone      string
+	TaxID      string
+	Footer     []string
+}
+
+// Line is one item on the receipt, taken from the work order item snapshots.
+type Line struct {
+	Name      string
+	Quantity  int
+	UnitPrice float64
+	Subtotal  float64
+	Note      string
+}
+
+// Tender is one completed payment on the receipt.
+type Tender struct {
+	Method        string
+	PaymentNumber string
+	Amount        float64
+	Change        float64
+	Tip           float64
+}
+
+// Receipt holds everything printed on a receipt.
+type Receipt struct {
+	Header      Header
+	OrderNumber string
+	QueueNumber *int
+	IssuedAt    time.Time
+	Cashier     string
+	Customer    string
+	Vehicle     string
+
+	Lines          []Line
+	Subtotal       float64
+	DiscountAmount float64
+	TaxRate        float64
+	TaxAmount      float64
+	TotalAmount    float64
+
+	Tenders           []Tender
+	PaidAmount        float64
+	ChangeAmount      float64
+	TipAmount         float64
+	RefundedAmount    float64
+	OutstandingAmount float64
+
+	// QRContent is encoded as a QR code at the bottom of the receipt. Nothing
+	// is printed when it is empty.
+	QRContent string
+	// OpenDrawer kicks the cash drawer when the ESC/POS stream is printed.
+	OpenDrawer bool
+}
+
+// TaxBase is the amount tax was charged on.
+func (r *Receipt) TaxBase() float64 {
+	return r.Subtotal - r.DiscountAmount
+}
+
+// FormatAmount formats an amount the Indonesian way, with dots between
+// thousands and a decimal comma only when there are cents.
+func FormatAmount(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	cents := int64(math.Round(amount * 100))
+	whole := fmt.Sprintf("%d", cents/100)
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if fraction := cents % 100; fraction != 0 {
+		return fmt.Sprintf("%s%s,%02d", sign, grouped.String(), fraction)
+	}
+	return sign + grouped.String()
+}
+
+// methodLabel is how a payment method is printed.
+func methodLabel(method string) string {
+	switch method {
+	case "cash":
+		return "Cash"
+	case "qris":
+		return "QRIS"
+	case "transfer":
+		return "Transfer"
+	case "e_wallet":
+		return "E-Wallet"
+	case "wallet":
+		return "Wallet"
+	case "account":
+		return "On Account"
+	default:
+		return method
+	}
+}
+
+// formatRate prints a tax rate without trailing zeros, e.g. 11 or 12.5.
+func formatRate(rate float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", rate), "0"), ".")
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UsedApprovalTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUsedApprovalTokenRepository(db *gorm.DB) *UsedApprovalTokenRepository {
+	return &UsedApprovalTokenRepository{db: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *UsedApprovalTokenRepository) WithTx(tx *gorm.DB) *UsedApprovalTokenRepository {
+	return &UsedApprovalTokenRepository{db: tx}
+}
+
+// Record marks a token as used unless it was used before. It reports
+// whether the token was still unused.
+func (r *UsedApprovalTokenRepository) Record(ctx context.Context, token *models.UsedApprovalToken) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(token)
+	return result.RowsAffected > 0, result.Error
+}
+
+// IsUsed reports whether a token has approved an operation already.
+func (r *UsedApprovalTokenRepository) IsUsed(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.UsedApprovalToken{}).
+		Where("token_id = ?", tokenID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CashMovementRepository struct {
+	*BaseRepository[models.CashMovement]
+}
+
+func NewCashMovementRepository(db *gorm.DB) *CashMovementRepository {
+	return &CashMovementRepository{
+		BaseRepository: NewBaseRepository[models.CashMovement](db),
+	}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *CashMovementRepository) WithTx(tx *gorm.DB) *CashMovementRepository {
+	return NewCashMovementRepository(tx)
+}
+
+func (r *CashMovementRepository) FindByShift(ctx context.Context, shiftID uint) ([]models.CashMovement, error) {
+	var movements []models.CashMovement
+	err := r.DB().WithContext(ctx).
+		Where("shift_id = ?", shiftID).
+		Preload("RecordedBy").
+		Preload("ApprovedBy").
+		Order("created_at ASC").
+		Find(&movements).Error
+	return movements, err
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CustomerVehicleRepository struct {
+	*BaseRepository[models.CustomerVehicle]
+}
+
+func NewCustomerVehicleRepository(db *gorm.DB) *CustomerVehicleRepository {
+	return &CustomerVehicleRepository{
+		BaseRepository: NewBaseRepository[models.CustomerVehicle](db),
+	}
+}
+
+func (r *CustomerVehicleRepository) FindWithVehicle(ctx context.Context, id uint) (*models.CustomerVehicle, error) {
+	var customerVehicle models.CustomerVehicle
+	err := r.DB().WithContext(ctx).Preload("Vehicle").First(&customerVehicle, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &customerVehicle, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FleetAccountRepository struct {
+	*BaseRepository[models.FleetAccount]
+}
+
+func NewFleetAccountRepository(db *gorm.DB) *FleetAccountRepository {
+	return &FleetAccountRepository{
+		BaseRepository: NewBaseRepository[models.FleetAccount](db),
+	}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *FleetAccountRepository) WithTx(tx *gorm.DB) *FleetAccountRepository {
+	return NewFleetAccountRepository(tx)
+}
+
+// FindByIDForUpdate loads a fleet account and locks its row until the
+// surrounding transaction ends, serialising charges and invoicing against it.
+func (r *FleetAccountRepository) FindByIDForUpdate(ctx context.Context, id uint) (*models.FleetAccount, error) {
+	var account models.FleetAccount
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&account, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *FleetAccountRepository) FindWithDetails(ctx context.Context, id uint) (*models.FleetAccount, error) {
+	var account models.FleetAccount
+	err := r.DB().WithContext(ctx).
+		Preload("Vehicles").
+		Preload("Vehicles.Vehicle").
+		Preload("Drivers").
+		Preload("Drivers.User").
+		First(&account, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindByIDs returns the accounts with the given IDs, by name.
+func (r *FleetAccountRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.FleetAccount, error) {
+	var accounts []models.FleetAccount
+	err := r.DB().WithContext(ctx).
+		Where("id IN ?", ids).
+		Order("name ASC").
+		Find(&accounts).Error
+	return accounts, err
+}
+
+// FindDriver returns the authorisation of a user to charge an account, or
+// nil when the user is not one of its drivers.
+func (r *FleetAccountRepository) FindDriver(ctx context.Context, accountID, userID uint) (*models.FleetDriver, error) {
+	var driver models.FleetDriver
+	err := r.DB().WithContext(ctx).
+		Where("fleet_account_id = ? AND user_id = ?", accountID, userID).
+		First(&driver).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &driver, nil
+}
+
+func (r *FleetAccountRepository) CreateDriver(ctx context.Context, driver *models.FleetDriver) error {
+	return r.DB().WithContext(ctx).Create(driver).Error
+}
+
+func (r *FleetAccountRepository) DeleteDriver(ctx context.Context, accountID, userID uint) (bool, error) {
+	result := r.DB().WithContext(ctx).
+		Where("fleet_account_id = ? AND user_id = ?", accountID, userID).
+		Delete(&models.FleetDriver{})
+	return result.RowsAffected > 0, result.Error
+}
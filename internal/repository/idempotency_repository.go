@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{db: db}
+}
+
+// Reserve claims a key for a request. It returns true when the key was
+// free; otherwise the existing record for the key is loaded into record.
+func (r *IdempotencyKeyRepository) Reserve(ctx context.Context, record *models.IdempotencyKey) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Where("key = ? AND user_id = ? AND method = ? AND path = ?", record.Key, record.UserID, record.Method, record.Path).
+		First(record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The holder released the key in the meantime
+		return r.Reserve(ctx, record)
+	}
+	return false, err
+}
+
+// Complete stores the response to replay for a key.
+func (r *IdempotencyKeyRepository) Complete(ctx context.Context, id uint, statusCode int, contentType string, body []byte) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": body,
+			"completed_at":  now,
+		}).Error
+}
+
+// Release frees a key so the request can be retried, e.g. after a server
+// error left nothing worth replaying.
+func (r *IdempotencyKeyRepository) Release(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.IdempotencyKey{}, id).Error
+}
+
+// DeleteExpired removes keys past their expiry, which are treated as never
+// used anyway, and returns how many were removed.
+func (r *IdempotencyKeyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ?", now).
+		Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InvoiceRepository keeps fleet account invoices and the payments received
+// against them.
+type InvoiceRepository struct {
+	*BaseRepository[models.Invoice]
+}
+
+func NewInvoiceRepository(db *gorm.DB) *InvoiceRepository {
+	return &InvoiceRepository{
+		BaseRepository: NewBaseRepository[models.Invoice](db),
+	}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *InvoiceRepository) WithTx(tx *gorm.DB) *InvoiceRepository {
+	return NewInvoiceRepository(tx)
+}
+
+// GenerateInvoiceNumber numbers invoices per billed month, e.g.
+// INV-202401-0001 for the first invoice of January 2024.
+func (r *InvoiceRepository) GenerateInvoiceNumber(ctx context.Context, period string) (string, error) {
+	prefix := fmt.Sprintf("INV-%s", strings.ReplaceAll(period, "-", ""))
+
+	var count int64
+	err := r.DB().WithContext(ctx).Model(&models.Invoice{}).
+		Where("invoice_number LIKE ?", prefix+"%").
+		Count(&count).Error
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%04d", prefix, count+1), nil
+}
+
+// FindByAccountPeriod returns an account's invoice for a month, or nil when
+// it has not been invoiced yet.
+func (r *InvoiceRepository) FindByAccountPeriod(ctx context.Context, accountID uint, period string) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.DB().WithContext(ctx).
+		Where("fleet_account_id = ? AND period = ?", accountID, period).
+		First(&invoice).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *InvoiceRepository) FindWithDetails(ctx context.Context, id uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.DB().WithContext(ctx).
+		Preload("FleetAccount").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("charged_at ASC, id ASC")
+		}).
+		Preload("Allocations").
+		First(&invoice, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// FindFiltered returns a page of invoices, newest first, optionally only
+// those of one account or with one status.
+func (r *InvoiceRepository) FindFiltered(ctx context.Context, page, perPage int, accountID *uint, status string) ([]models.Invoice, int64, error) {
+	query := r.DB().WithContext(ctx).Model(&models.Invoice{})
+	if accountID != nil {
+		query = query.Where("fleet_account_id = ?", *accountID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var invoices []models.Invoice
+	err := query.
+		Preload("FleetAccount").
+		Order("issue_date DESC, id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&invoices).Error
+	return invoices, total, err
+}
+
+// FindUnpaid returns every invoice not yet paid in full, oldest due first.
+func (r *InvoiceRepository) FindUnpaid(ctx context.Context) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.DB().WithContext(ctx).
+		Where("status <> ?", models.InvoiceStatusPaid).
+		Order("due_date ASC, id ASC").
+		Find(&invoices).Error
+	return invoices, err
+}
+
+// FindUnpaidByAccountForUpdate returns an account's invoices not yet paid in
+// full, oldest due first, and locks them until the surrounding transaction
+// ends.
+func (r *InvoiceRepository) FindUnpaidByAccountForUpdate(ctx context.Context, accountID uint) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("fleet_account_id = ? AND status <> ?", accountID, models.InvoiceStatusPaid).
+		Order("due_date ASC, id ASC").
+		Find(&invoices).Error
+	return invoices, err
+}
+
+// SumUnpaidByAccount returns what an account still owes on its invoices.
+func (r *InvoiceRepository) SumUnpaidByAccount(ctx context.Context, accountID uint) (float64, error) {
+	var total float64
+	err := r.DB().WithContext(ctx).Model(&models.Invoice{}).
+		Where("fleet_account_id = ? AND status <> ?", accountID, models.InvoiceStatusPaid).
+		Select("COALESCE(SUM(total_amount - paid_amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *InvoiceRepository) GenerateAccountPaymentNumber(ctx context.Context) (string, error) {
+	now := time.Now()
+	prefix := fmt.Sprintf("ACP-%s", now.Format("20060102"))
+
+	var count int64
+	err := r.DB().WithContext(ctx).Model(&models.AccountPayment{}).
+		Where("payment_number LIKE ?", prefix+"%").
+		Count(&count).Error
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%04d", prefix, count+1), nil
+}
+
+// FindAccountPayments returns the payments received from an account with
+// their allocations, newest first.
+func (r *InvoiceRepository) FindAccountPayments(ctx context.Context, accountID uint) ([]models.AccountPayment, error) {
+	var payments []models.AccountPayment
+	err := r.DB().WithContext(ctx).
+		Where("fleet_account_id = ?", accountID).
+		Preload("RecordedBy").
+		Preload("Allocations").
+		Order("received_at DESC, id DESC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// FindCreditsForUpdate returns an account's payments that still have an
+// unallocated amount, oldest first, and locks them until the surrounding
+// transaction ends.
+func (r *InvoiceRepository) FindCreditsForUpdate(ctx context.Context, accountID uint) ([]models.AccountPayment, error) {
+	var payments []models.AccountPayment
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("fleet_account_id = ? AND unallocated_amount > 0", accountID).
+		Order("received_at ASC, id ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// SumCreditsByAccount returns the unallocated amount an account has paid.
+func (r *InvoiceRepository) SumCreditsByAccount(ctx context.Context, accountID uint) (float64, error) {
+	var total float64
+	err := r.DB().WithContext(ctx).Model(&models.AccountPayment{}).
+		Where("fleet_account_id = ?", accountID).
+		Select("COALESCE(SUM(unallocated_amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// SumCredits returns the unallocated amount paid per fleet account.
+func (r *InvoiceRepository) SumCredits(ctx context.Context) (map[uint]float64, error) {
+	var rows []struct {
+		FleetAccountID uint
+		Total          float64
+	}
+	err := r.DB().WithContext(ctx).Model(&models.AccountPayment{}).
+		Where("unallocated_amount > 0").
+		Select("fleet_account_id, SUM(unallocated_amount) AS total").
+		Group("fleet_account_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		totals[row.FleetAccountID] = row.Total
+	}
+	return totals, nil
+}
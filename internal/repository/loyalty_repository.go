@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LoyaltyEarnRuleRepository struct {
+	*BaseRepository[models.LoyaltyEarnRule]
+}
+
+func NewLoyaltyEarnRuleRepository(db *gorm.DB) *LoyaltyEarnRuleRepository {
+	return &LoyaltyEarnRuleRepository{
+		BaseRepository: NewBaseRepository[models.LoyaltyEarnRule](db),
+	}
+}
+
+func (r *LoyaltyEarnRuleRepository) FindActive(ctx context.Context) ([]models.LoyaltyEarnRule, error) {
+	var rules []models.LoyaltyEarnRule
+	err := r.DB().WithContext(ctx).Where("is_active = ?", true).Find(&rules).Error
+	return rules, err
+}
+
+type LoyaltyRewardRepository struct {
+	*BaseRepository[models.LoyaltyReward]
+}
+
+func NewLoyaltyRewardRepository(db *gorm.DB) *LoyaltyRewardRepository {
+	return &LoyaltyRewardRepository{
+		BaseRepository: NewBaseRepository[models.LoyaltyReward](db),
+	}
+}
+
+func (r *LoyaltyRewardRepository) FindActive(ctx context.Context) ([]models.LoyaltyReward, error) {
+	var rewards []models.LoyaltyReward
+	err := r.DB().WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("points_cost ASC").
+		Find(&rewards).Error
+	return rewards, err
+}
+
+// LoyaltyPointRepository gives access to the points ledger. Like the
+// stored-value ledger it only ever appends.
+type LoyaltyPointRepository struct {
+	db *gorm.DB
+}
+
+func NewLoyaltyPointRepository(db *gorm.DB) *LoyaltyPointRepository {
+	return &LoyaltyPointRepository{db: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *LoyaltyPointRepository) WithTx(tx *gorm.DB) *LoyaltyPointRepository {
+	return &LoyaltyPointRepository{db: tx}
+}
+
+// LockCustomer serialises points movements for one customer until the
+// surrounding transaction ends.
+func (r *LoyaltyPointRepository) LockCustomer(ctx context.Context, customerID uint) error {
+	key := fmt.Sprintf("loyalty:customer:%d", customerID)
+	return r.db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error
+}
+
+func (r *LoyaltyPointRepository) Append(ctx context.Context, entry *models.LoyaltyPointEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *LoyaltyPointRepository) GetBalance(ctx context.Context, customerID uint) (int, error) {
+	var balance int
+	err := r.db.WithContext(ctx).Model(&models.LoyaltyPointEntry{}).
+		Where("customer_user_id = ?", customerID).
+		Select("COALESCE(SUM(points), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+// SumByTypes totals a customer's points for the given entry types.
+func (r *LoyaltyPointRepository) SumByTypes(ctx context.Context, customerID uint, types ...models.LoyaltyEntryType) (int, error) {
+	var total int
+	err := r.db.WithContext(ctx).Model(&models.LoyaltyPointEntry{}).
+		Where("customer_user_id = ? AND entry_type IN ?", customerID, types).
+		Select("COALESCE(SUM(points), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// FindEarnings returns a customer's earn and earn reversal entries, oldest
+// first.
+func (r *LoyaltyPointRepository) FindEarnings(ctx context.Context, customerID uint) ([]models.LoyaltyPointEntry, error) {
+	var entries []models.LoyaltyPointEntry
+	err := r.db.WithContext(ctx).
+		Where("customer_user_id = ? AND entry_type IN ?", customerID,
+			[]models.LoyaltyEntryType{models.LoyaltyEntryEarn, models.LoyaltyEntryEarnReversal}).
+		Order("created_at ASC, id ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// SumForWorkOrder totals the points of the given entry types tied to a work
+// order.
+func (r *LoyaltyPointRepository) SumForWorkOrder(ctx context.Context, workOrderID uint, types ...models.LoyaltyEntryType) (int, error) {
+	var total int
+	err := r.db.WithContext(ctx).Model(&models.LoyaltyPointEntry{}).
+		Where("work_order_id = ? AND entry_type IN ?", workOrderID, types).
+		Select("COALESCE(SUM(points), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// FindCustomersWithExpiredEarnings lists customers holding earn entries whose
+// expiry has passed.
+func (r *LoyaltyPointRepository) FindCustomersWithExpiredEarnings(ctx context.Context, now time.Time) ([]uint, error) {
+	var customerIDs []uint
+	err := r.db.WithContext(ctx).Model(&models.LoyaltyPointEntry{}).
+		Where("entry_type = ? AND expires_at <= ?", models.LoyaltyEntryEarn, now).
+		Distinct().
+		Pluck("customer_user_id", &customerIDs).Error
+	return customerIDs, err
+}
+
+func (r *LoyaltyPointRepository) FindByCustomer(ctx context.Context, customerID uint, page, perPage int) ([]models.LoyaltyPointEntry, int64, error) {
+	var entries []models.LoyaltyPointEntry
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.LoyaltyPointEntry{}).
+		Where("customer_user_id = ?", customerID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Order("id DESC").Offset(offset).Limit(perPage).Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
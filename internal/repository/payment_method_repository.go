@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PaymentMethodRepository struct {
+	*BaseRepository[models.PaymentMethodSetting]
+}
+
+func NewPaymentMethodRepository(db *gorm.DB) *PaymentMethodRepository {
+	return &PaymentMethodRepository{
+		BaseRepository: NewBaseRepository[models.PaymentMethodSetting](db),
+	}
+}
+
+// FindByOutlet returns an outlet's payment methods in display order,
+// optionally only the enabled ones.
+func (r *PaymentMethodRepository) FindByOutlet(ctx context.Context, outletCode string, enabledOnly bool) ([]models.PaymentMethodSetting, error) {
+	var methods []models.PaymentMethodSetting
+	query := r.DB().WithContext(ctx).Where("outlet_code = ?", outletCode)
+	if enabledOnly {
+		query = query.Where("is_enabled = ?", true)
+	}
+	err := query.Order("sort_order ASC, id ASC").Find(&methods).Error
+	return methods, err
+}
+
+// FindByCode returns an outlet's payment method with the given code, or nil
+// when the outlet has none.
+func (r *PaymentMethodRepository) FindByCode(ctx context.Context, outletCode string, code models.PaymentMethod) (*models.PaymentMethodSetting, error) {
+	var method models.PaymentMethodSetting
+	err := r.DB().WithContext(ctx).
+		Where("outlet_code = ? AND code = ?", outletCode, code).
+		First(&method).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &method, nil
+}
@@ -2,12 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"flashlight-go/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PaymentRepository struct {
@@ -20,6 +22,11 @@ func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
 	}
 }
 
+// WithTx returns a repository bound to the given transaction.
+func (r *PaymentRepository) WithTx(tx *gorm.DB) *PaymentRepository {
+	return NewPaymentRepository(tx)
+}
+
 func (r *PaymentRepository) GeneratePaymentNumber(ctx context.Context) (string, error) {
 	now := time.Now()
 	prefix := fmt.Sprintf("PAY-%s", now.Format("20060102"))
@@ -36,6 +43,52 @@ func (r *PaymentRepository) GeneratePaymentNumber(ctx context.Context) (string,
 	return paymentNumber, nil
 }
 
+func (r *PaymentRepository) FindWithDetails(ctx context.Context, id uint) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.DB().WithContext(ctx).
+		Preload("WorkOrder").
+		Preload("CashierUser").
+		Preload("Tips").
+		Preload("Refunds").
+		Preload("VoidedBy").
+		Preload("VoidApprovedBy").
+		First(&payment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// FindByIDForUpdate loads a payment and locks its row until the surrounding
+// transaction ends.
+func (r *PaymentRepository) FindByIDForUpdate(ctx context.Context, id uint) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&payment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// FindByPaymentNumberForUpdate loads a payment and locks its row until the
+// surrounding transaction ends. It returns nil when there is no such payment.
+func (r *PaymentRepository) FindByPaymentNumberForUpdate(ctx context.Context, paymentNumber string) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("payment_number = ?", paymentNumber).
+		First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
 func (r *PaymentRepository) FindByWorkOrder(ctx context.Context, workOrderID uint) ([]models.Payment, error) {
 	var payments []models.Payment
 	err := r.DB().WithContext(ctx).
@@ -45,11 +98,25 @@ func (r *PaymentRepository) FindByWorkOrder(ctx context.Context, workOrderID uin
 	return payments, err
 }
 
-func (r *PaymentRepository) GetTotalPaidForWorkOrder(ctx context.Context, workOrderID uint) (float64, error) {
+// FindPendingByWorkOrder returns the payments of a work order still waiting
+// for confirmation, such as QRIS codes not paid yet.
+func (r *PaymentRepository) FindPendingByWorkOrder(ctx context.Context, workOrderID uint) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.DB().WithContext(ctx).
+		Where("work_order_id = ? AND status = ?", workOrderID, models.PaymentStatusPending).
+		Order("created_at ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// GetNetPaidForWorkOrder returns what completed payments contribute to a
+// work order: the amount paid less change handed back and less what has
+// been refunded on them. Tips never count.
+func (r *PaymentRepository) GetNetPaidForWorkOrder(ctx context.Context, workOrderID uint) (float64, error) {
 	var total float64
 	err := r.DB().WithContext(ctx).Model(&models.Payment{}).
 		Where("work_order_id = ? AND status = ?", workOrderID, models.PaymentStatusCompleted).
-		Select("COALESCE(SUM(amount_paid), 0)").
+		Select("COALESCE(SUM(amount_paid - change_amount - refunded_amount), 0)").
 		Scan(&total).Error
 	return total, err
 }
@@ -62,3 +129,128 @@ func (r *PaymentRepository) FindByShift(ctx context.Context, shiftID uint) ([]mo
 		Find(&payments).Error
 	return payments, err
 }
+
+// FindByReferenceNumber returns the payments with the given provider
+// reference taken by one of the methods, oldest first.
+func (r *PaymentRepository) FindByReferenceNumber(ctx context.Context, reference string, methods []models.PaymentMethod) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.DB().WithContext(ctx).
+		Where("reference_number = ? AND method IN ?", reference, methods).
+		Order("id ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// FindUnsettled returns the completed payments taken by one of the methods
+// and paid in [from, to) that no settlement line has settled yet.
+func (r *PaymentRepository) FindUnsettled(ctx context.Context, methods []models.PaymentMethod, from, to time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.DB().WithContext(ctx).
+		Where("method IN ? AND status IN ? AND paid_at >= ? AND paid_at < ?", methods, []models.PaymentStatus{
+			models.PaymentStatusCompleted,
+			models.PaymentStatusRefunded,
+		}, from, to).
+		Where("NOT EXISTS (SELECT 1 FROM settlement_lines WHERE settlement_lines.payment_id = payments.id AND settlement_lines.status IN ?)", []models.SettlementLineStatus{
+			models.SettlementLineMatched,
+			models.SettlementLineAmountMismatch,
+		}).
+		Order("paid_at ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// PaymentMethodTotal is what the completed payments of one method brought in
+// during a period. Gross is net of change.
+type PaymentMethodTotal struct {
+	Method models.PaymentMethod
+	Count  int64
+	Gross  float64
+	Tip    float64
+	Fee    float64
+}
+
+// SumByMethod totals the completed payments paid in [from, to) per method.
+func (r *PaymentRepository) SumByMethod(ctx context.Context, from, to time.Time) ([]PaymentMethodTotal, error) {
+	var totals []PaymentMethodTotal
+	err := r.DB().WithContext(ctx).Model(&models.Payment{}).
+		Where("status = ? AND paid_at >= ? AND paid_at < ?", models.PaymentStatusCompleted, from, to).
+		Select("method, COUNT(*) AS count, COALESCE(SUM(amount_paid - change_amount), 0) AS gross, COALESCE(SUM(tip_amount), 0) AS tip, COALESCE(SUM(fee_amount), 0) AS fee").
+		Group("method").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// FindPendingCreatedBefore returns the payments still pending that were
+// created before the cutoff, oldest first.
+func (r *PaymentRepository) FindPendingCreatedBefore(ctx context.Context, cutoff time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.DB().WithContext(ctx).
+		Where("status = ? AND created_at < ?", models.PaymentStatusPending, cutoff).
+		Order("created_at ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// accountChargeAmount is what an on-account payment adds to the account's
+// bill: the amount charged and the tip, less refunds.
+const accountChargeAmount = "amount_paid - change_amount - refunded_amount + tip_amount"
+
+// SumUnbilledByAccount returns what has been charged to a fleet account but
+// not invoiced yet.
+func (r *PaymentRepository) SumUnbilledByAccount(ctx context.Context, accountID uint) (float64, error) {
+	var total float64
+	err := r.DB().WithContext(ctx).Model(&models.Payment{}).
+		Where("fleet_account_id = ? AND invoice_id IS NULL AND status = ?", accountID, models.PaymentStatusCompleted).
+		Select("COALESCE(SUM(" + accountChargeAmount + "), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// SumUnbilled returns the amount charged but not invoiced yet per fleet
+// account.
+func (r *PaymentRepository) SumUnbilled(ctx context.Context) (map[uint]float64, error) {
+	var rows []struct {
+		FleetAccountID uint
+		Total          float64
+	}
+	err := r.DB().WithContext(ctx).Model(&models.Payment{}).
+		Where("fleet_account_id IS NOT NULL AND invoice_id IS NULL AND status = ?", models.PaymentStatusCompleted).
+		Select("fleet_account_id, COALESCE(SUM(" + accountChargeAmount + "), 0) AS total").
+		Group("fleet_account_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		totals[row.FleetAccountID] = row.Total
+	}
+	return totals, nil
+}
+
+// FindAccountsWithUnbilledCharges returns the fleet accounts that have
+// charges paid before the cutoff that are not invoiced yet.
+func (r *PaymentRepository) FindAccountsWithUnbilledCharges(ctx context.Context, before time.Time) ([]uint, error) {
+	var accountIDs []uint
+	err := r.DB().WithContext(ctx).Model(&models.Payment{}).
+		Where("fleet_account_id IS NOT NULL AND invoice_id IS NULL AND status = ? AND paid_at < ?", models.PaymentStatusCompleted, before).
+		Distinct("fleet_account_id").
+		Order("fleet_account_id ASC").
+		Pluck("fleet_account_id", &accountIDs).Error
+	return accountIDs, err
+}
+
+// FindUnbilledChargesForUpdate returns a fleet account's charges paid before
+// the cutoff that are not invoiced yet, oldest first, and locks them until
+// the surrounding transaction ends so they cannot be voided or refunded
+// while they are invoiced.
+func (r *PaymentRepository) FindUnbilledChargesForUpdate(ctx context.Context, accountID uint, before time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("fleet_account_id = ? AND invoice_id IS NULL AND status = ? AND paid_at < ?", accountID, models.PaymentStatusCompleted, before).
+		Order("paid_at ASC, id ASC").
+		Find(&payments).Error
+	return payments, err
+}
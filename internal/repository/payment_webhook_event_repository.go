@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentWebhookEventRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentWebhookEventRepository(db *gorm.DB) *PaymentWebhookEventRepository {
+	return &PaymentWebhookEventRepository{db: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *PaymentWebhookEventRepository) WithTx(tx *gorm.DB) *PaymentWebhookEventRepository {
+	return &PaymentWebhookEventRepository{db: tx}
+}
+
+// Record stores an event unless the provider delivered it before. It
+// reports whether the event is new.
+func (r *PaymentWebhookEventRepository) Record(ctx context.Context, event *models.PaymentWebhookEvent) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PaymentWebhookEventRepository) Update(ctx context.Context, event *models.PaymentWebhookEvent) error {
+	return r.db.WithContext(ctx).Save(event).Error
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PricingRuleRepository struct {
+	*BaseRepository[models.PricingRule]
+}
+
+func NewPricingRuleRepository(db *gorm.DB) *PricingRuleRepository {
+	return &PricingRuleRepository{
+		BaseRepository: NewBaseRepository[models.PricingRule](db),
+	}
+}
+
+// FindActiveForProduct returns the active rules that target the product
+// directly or through its category. A rule carrying a product only ever
+// applies to that product. Time windows are evaluated by the caller.
+func (r *PricingRuleRepository) FindActiveForProduct(ctx context.Context, productID, categoryID uint) ([]models.PricingRule, error) {
+	var rules []models.PricingRule
+	err := r.DB().WithContext(ctx).
+		Where("is_active = ? AND (product_id = ? OR (product_id IS NULL AND category_id = ?))", true, productID, categoryID).
+		Order("priority DESC, id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+type VehiclePriceTierRepository struct {
+	*BaseRepository[models.VehiclePriceTier]
+}
+
+func NewVehiclePriceTierRepository(db *gorm.DB) *VehiclePriceTierRepository {
+	return &VehiclePriceTierRepository{
+		BaseRepository: NewBaseRepository[models.VehiclePriceTier](db),
+	}
+}
+
+// FindActiveForProduct returns the active tiers for the vehicle type that
+// target the product directly or through its category, product tiers first.
+func (r *VehiclePriceTierRepository) FindActiveForProduct(ctx context.Context, vehicleType string, productID, categoryID uint) ([]models.VehiclePriceTier, error) {
+	var tiers []models.VehiclePriceTier
+	err := r.DB().WithContext(ctx).
+		Where("is_active = ? AND vehicle_type = ? AND (product_id = ? OR (product_id IS NULL AND category_id = ?))", true, vehicleType, productID, categoryID).
+		Order("product_id IS NULL, id ASC").
+		Find(&tiers).Error
+	return tiers, err
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ProductPriceRepository struct {
+	*BaseRepository[models.ProductPrice]
+}
+
+func NewProductPriceRepository(db *gorm.DB) *ProductPriceRepository {
+	return &ProductPriceRepository{
+		BaseRepository: NewBaseRepository[models.ProductPrice](db),
+	}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *ProductPriceRepository) WithTx(tx *gorm.DB) *ProductPriceRepository {
+	return NewProductPriceRepository(tx)
+}
+
+// FindEffective returns the price entry in effect for a product at the given
+// time, or nil when the product has no price history yet.
+func (r *ProductPriceRepository) FindEffective(ctx context.Context, productID uint, at time.Time) (*models.ProductPrice, error) {
+	var price models.ProductPrice
+	err := r.DB().WithContext(ctx).
+		Where("product_id = ? AND effective_from <= ?", productID, at).
+		Order("effective_from DESC, id DESC").
+		First(&price).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &price, nil
+}
+
+func (r *ProductPriceRepository) FindByProduct(ctx context.Context, productID uint) ([]models.ProductPrice, error) {
+	var prices []models.ProductPrice
+	err := r.DB().WithContext(ctx).
+		Where("product_id = ?", productID).
+		Order("effective_from DESC, id DESC").
+		Find(&prices).Error
+	return prices, err
+}
+
+func (r *ProductPriceRepository) CountByProduct(ctx context.Context, productID uint) (int64, error) {
+	var count int64
+	err := r.DB().WithContext(ctx).Model(&models.ProductPrice{}).
+		Where("product_id = ?", productID).
+		Count(&count).Error
+	return count, err
+}
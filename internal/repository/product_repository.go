@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductRepository struct {
+	*BaseRepository[models.Product]
+}
+
+func NewProductRepository(db *gorm.DB) *ProductRepository {
+	return &ProductRepository{
+		BaseRepository: NewBaseRepository[models.Product](db),
+	}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *ProductRepository) WithTx(tx *gorm.DB) *ProductRepository {
+	return NewProductRepository(tx)
+}
+
+// FindByIDForUpdate loads a product and locks its row until the surrounding
+// transaction ends, serialising changes to its price history.
+func (r *ProductRepository) FindByIDForUpdate(ctx context.Context, id uint) (*models.Product, error) {
+	var product models.Product
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&product, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromoRepository struct {
+	*BaseRepository[models.Promo]
+}
+
+func NewPromoRepository(db *gorm.DB) *PromoRepository {
+	return &PromoRepository{
+		BaseRepository: NewBaseRepository[models.Promo](db),
+	}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *PromoRepository) WithTx(tx *gorm.DB) *PromoRepository {
+	return NewPromoRepository(tx)
+}
+
+// FindByCode returns the promo with the given code, or nil if there is none.
+func (r *PromoRepository) FindByCode(ctx context.Context, code string) (*models.Promo, error) {
+	var promo models.Promo
+	err := r.DB().WithContext(ctx).Where("code = ?", code).First(&promo).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// FindByIDForUpdate loads a promo and locks its row until the surrounding
+// transaction ends, so its last use cannot be redeemed twice.
+func (r *PromoRepository) FindByIDForUpdate(ctx context.Context, id uint) (*models.Promo, error) {
+	var promo models.Promo
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&promo, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
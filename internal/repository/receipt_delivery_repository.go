@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ReceiptDeliveryRepository struct {
+	*BaseRepository[models.ReceiptDelivery]
+}
+
+func NewReceiptDeliveryRepository(db *gorm.DB) *ReceiptDeliveryRepository {
+	return &ReceiptDeliveryRepository{
+		BaseRepository: NewBaseRepository[models.ReceiptDelivery](db),
+	}
+}
+
+func (r *ReceiptDeliveryRepository) FindByWorkOrder(ctx context.Context, workOrderID uint) ([]models.ReceiptDelivery, error) {
+	var deliveries []models.ReceiptDelivery
+	err := r.DB().WithContext(ctx).
+		Where("work_order_id = ?", workOrderID).
+		Preload("RequestedBy").
+		Order("created_at DESC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
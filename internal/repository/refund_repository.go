@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RefundRepository struct {
+	*BaseRepository[models.Refund]
+}
+
+func NewRefundRepository(db *gorm.DB) *RefundRepository {
+	return &RefundRepository{
+		BaseRepository: NewBaseRepository[models.Refund](db),
+	}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *RefundRepository) WithTx(tx *gorm.DB) *RefundRepository {
+	return NewRefundRepository(tx)
+}
+
+func (r *RefundRepository) GenerateRefundNumber(ctx context.Context) (string, error) {
+	now := time.Now()
+	prefix := fmt.Sprintf("RF-%s", now.Format("20060102"))
+
+	var count int64
+	err := r.DB().WithContext(ctx).Model(&models.Refund{}).
+		Where("refund_number LIKE ?", prefix+"%").
+		Count(&count).Error
+	if err != nil {
+		return "", err
+	}
+
+	refundNumber := fmt.Sprintf("%s-%04d", prefix, count+1)
+	return refundNumber, nil
+}
+
+func (r *RefundRepository) FindByPayment(ctx context.Context, paymentID uint) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := r.DB().WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Preload("ApprovedBy").
+		Order("created_at DESC").
+		Find(&refunds).Error
+	return refunds, err
+}
+
+func (r *RefundRepository) FindByWorkOrder(ctx context.Context, workOrderID uint) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := r.DB().WithContext(ctx).
+		Where("work_order_id = ?", workOrderID).
+		Preload("ApprovedBy").
+		Order("created_at DESC").
+		Find(&refunds).Error
+	return refunds, err
+}
+
+// SumByMethod totals the refunds given in [from, to) per method.
+func (r *RefundRepository) SumByMethod(ctx context.Context, from, to time.Time) (map[models.PaymentMethod]float64, error) {
+	var rows []struct {
+		Method models.PaymentMethod
+		Amount float64
+	}
+	err := r.DB().WithContext(ctx).Model(&models.Refund{}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Select("method, COALESCE(SUM(amount), 0) AS amount").
+		Group("method").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[models.PaymentMethod]float64, len(rows))
+	for _, row := range rows {
+		totals[row.Method] = row.Amount
+	}
+	return totals, nil
+}
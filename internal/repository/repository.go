@@ -38,16 +38,6 @@ func NewVehicleRepository(db *gorm.DB) *VehicleRepository {
 	}
 }
 
-type CustomerVehicleRepository struct {
-	*BaseRepository[models.CustomerVehicle]
-}
-
-func NewCustomerVehicleRepository(db *gorm.DB) *CustomerVehicleRepository {
-	return &CustomerVehicleRepository{
-		BaseRepository: NewBaseRepository[models.CustomerVehicle](db),
-	}
-}
-
 type ProductCategoryRepository struct {
 	*BaseRepository[models.ProductCategory]
 }
@@ -58,16 +48,6 @@ func NewProductCategoryRepository(db *gorm.DB) *ProductCategoryRepository {
 	}
 }
 
-type ProductRepository struct {
-	*BaseRepository[models.Product]
-}
-
-func NewProductRepository(db *gorm.DB) *ProductRepository {
-	return &ProductRepository{
-		BaseRepository: NewBaseRepository[models.Product](db),
-	}
-}
-
 type WorkOrderItemRepository struct {
 	*BaseRepository[models.WorkOrderItem]
 }
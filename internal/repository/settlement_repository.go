@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SettlementRepository struct {
+	*BaseRepository[models.SettlementBatch]
+}
+
+func NewSettlementRepository(db *gorm.DB) *SettlementRepository {
+	return &SettlementRepository{
+		BaseRepository: NewBaseRepository[models.SettlementBatch](db),
+	}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *SettlementRepository) WithTx(tx *gorm.DB) *SettlementRepository {
+	return NewSettlementRepository(tx)
+}
+
+// FindByHash returns the batch imported from a file with the given hash, or
+// nil when the file has not been imported.
+func (r *SettlementRepository) FindByHash(ctx context.Context, fileHash string) (*models.SettlementBatch, error) {
+	var batch models.SettlementBatch
+	err := r.DB().WithContext(ctx).
+		Where("file_hash = ?", fileHash).
+		First(&batch).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *SettlementRepository) FindWithLines(ctx context.Context, id uint) (*models.SettlementBatch, error) {
+	var batch models.SettlementBatch
+	err := r.DB().WithContext(ctx).
+		Preload("ImportedBy").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("row_number ASC")
+		}).
+		First(&batch, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// FindLinesByDate returns the lines of a layout settled on the given date,
+// with the payments they were matched to.
+func (r *SettlementRepository) FindLinesByDate(ctx context.Context, layout, settlementDate string) ([]models.SettlementLine, error) {
+	var lines []models.SettlementLine
+	err := r.DB().WithContext(ctx).
+		Where("layout = ? AND settlement_date = ?", layout, settlementDate).
+		Preload("Payment").
+		Order("batch_id ASC, row_number ASC").
+		Find(&lines).Error
+	return lines, err
+}
+
+// IsPaymentSettled reports whether a line already settled the payment,
+// whether or not for the right amount.
+func (r *SettlementRepository) IsPaymentSettled(ctx context.Context, paymentID uint) (bool, error) {
+	var count int64
+	err := r.DB().WithContext(ctx).Model(&models.SettlementLine{}).
+		Where("payment_id = ? AND status IN ?", paymentID, []models.SettlementLineStatus{
+			models.SettlementLineMatched,
+			models.SettlementLineAmountMismatch,
+		}).
+		Count(&count).Error
+	return count > 0, err
+}
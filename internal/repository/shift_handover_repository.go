@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShiftHandoverRepository struct {
+	*BaseRepository[models.ShiftHandover]
+}
+
+func NewShiftHandoverRepository(db *gorm.DB) *ShiftHandoverRepository {
+	return &ShiftHandoverRepository{
+		BaseRepository: NewBaseRepository[models.ShiftHandover](db),
+	}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *ShiftHandoverRepository) WithTx(tx *gorm.DB) *ShiftHandoverRepository {
+	return NewShiftHandoverRepository(tx)
+}
+
+// FindByIDForUpdate loads a handover and locks its row until the surrounding
+// transaction ends, so it is acknowledged only once.
+func (r *ShiftHandoverRepository) FindByIDForUpdate(ctx context.Context, id uint) (*models.ShiftHandover, error) {
+	var handover models.ShiftHandover
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&handover, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &handover, nil
+}
+
+// FindPendingForUser returns the handovers waiting for a user to
+// acknowledge them, oldest first.
+func (r *ShiftHandoverRepository) FindPendingForUser(ctx context.Context, userID uint) ([]models.ShiftHandover, error) {
+	var handovers []models.ShiftHandover
+	err := r.DB().WithContext(ctx).
+		Where("to_user_id = ? AND status = ?", userID, models.HandoverStatusPending).
+		Preload("FromUser").
+		Order("created_at ASC, id ASC").
+		Find(&handovers).Error
+	return handovers, err
+}
+
+// FindFiltered returns a page of handovers, newest first, optionally only
+// those with one status or those flagged with a discrepancy.
+func (r *ShiftHandoverRepository) FindFiltered(ctx context.Context, page, perPage int, status string, flaggedOnly bool) ([]models.ShiftHandover, int64, error) {
+	query := r.DB().WithContext(ctx).Model(&models.ShiftHandover{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if flaggedOnly {
+		query = query.Where("is_flagged = ?", true)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var handovers []models.ShiftHandover
+	err := query.
+		Preload("FromUser").
+		Preload("ToUser").
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&handovers).Error
+	return handovers, total, err
+}
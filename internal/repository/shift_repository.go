@@ -7,6 +7,7 @@ import (
 	"flashlight-go/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ShiftRepository struct {
@@ -19,6 +20,24 @@ func NewShiftRepository(db *gorm.DB) *ShiftRepository {
 	}
 }
 
+// WithTx returns a repository bound to the given transaction.
+func (r *ShiftRepository) WithTx(tx *gorm.DB) *ShiftRepository {
+	return NewShiftRepository(tx)
+}
+
+// FindByIDForUpdate loads a shift and locks its row until the surrounding
+// transaction ends, so it cannot be closed in the meantime.
+func (r *ShiftRepository) FindByIDForUpdate(ctx context.Context, id uint) (*models.Shift, error) {
+	var shift models.Shift
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&shift, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
 func (r *ShiftRepository) FindActiveShiftByUser(ctx context.Context, userID uint) (*models.Shift, error) {
 	var shift models.Shift
 	err := r.DB().WithContext(ctx).
@@ -33,12 +52,36 @@ func (r *ShiftRepository) FindActiveShiftByUser(ctx context.Context, userID uint
 	return &shift, nil
 }
 
+// FindActiveShiftByUserForShare returns the user's active shift like
+// FindActiveShiftByUser and holds a shared lock on it until the surrounding
+// transaction ends. Money can be booked to the shift concurrently, but it
+// cannot be closed until the booking commits.
+func (r *ShiftRepository) FindActiveShiftByUserForShare(ctx context.Context, userID uint) (*models.Shift, error) {
+	var shift models.Shift
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("user_id = ? AND status = ?", userID, models.ShiftStatusActive).
+		First(&shift).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &shift, nil
+}
+
 func (r *ShiftRepository) FindWithDetails(ctx context.Context, id uint) (*models.Shift, error) {
 	var shift models.Shift
 	err := r.DB().WithContext(ctx).
 		Preload("User").
 		Preload("WorkOrders").
 		Preload("Payments").
+		Preload("CashCounts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("denomination DESC")
+		}).
+		Preload("HandoverIn").
+		Preload("HandoverOut").
 		First(&shift, id).Error
 	if err != nil {
 		return nil, err
@@ -48,6 +91,14 @@ func (r *ShiftRepository) FindWithDetails(ctx context.Context, id uint) (*models
 
 func (r *ShiftRepository) GetShiftSummary(ctx context.Context, shiftID uint) (map[string]interface{}, error) {
 	var totalSales float64
+	var totalTips float64
+	var cashReceived float64
+	var sales struct {
+		Cash    float64
+		NonCash float64
+	}
+	var totalRefunds float64
+	var cashRefunds float64
 	var totalOrders int64
 
 	err := r.DB().WithContext(ctx).Model(&models.Payment{}).
@@ -58,6 +109,52 @@ func (r *ShiftRepository) GetShiftSummary(ctx context.Context, shiftID uint) (ma
 		return nil, err
 	}
 
+	// Tips are kept out of sales; they belong to the staff
+	err = r.DB().WithContext(ctx).Model(&models.Payment{}).
+		Where("shift_id = ? AND status = ?", shiftID, models.PaymentStatusCompleted).
+		Select("COALESCE(SUM(tip_amount), 0)").
+		Scan(&totalTips).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Sales split by whether they were paid in cash, net of change
+	err = r.DB().WithContext(ctx).Model(&models.Payment{}).
+		Where("shift_id = ? AND status = ?", shiftID, models.PaymentStatusCompleted).
+		Select("COALESCE(SUM(CASE WHEN method = ? THEN amount_paid - change_amount ELSE 0 END), 0) AS cash, "+
+			"COALESCE(SUM(CASE WHEN method <> ? THEN amount_paid - change_amount ELSE 0 END), 0) AS non_cash", models.MethodCash, models.MethodCash).
+		Scan(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Cash that stayed in the drawer: tendered amount and tip, less change.
+	// Change is always handed back in cash, also for other methods that
+	// allow it
+	err = r.DB().WithContext(ctx).Model(&models.Payment{}).
+		Where("shift_id = ? AND status = ?", shiftID, models.PaymentStatusCompleted).
+		Select("COALESCE(SUM(CASE WHEN method = ? THEN amount_paid + tip_amount - change_amount ELSE -change_amount END), 0)", models.MethodCash).
+		Scan(&cashReceived).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.DB().WithContext(ctx).Model(&models.Refund{}).
+		Where("shift_id = ?", shiftID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&totalRefunds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.DB().WithContext(ctx).Model(&models.Refund{}).
+		Where("shift_id = ? AND method = ?", shiftID, models.MethodCash).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&cashRefunds).Error
+	if err != nil {
+		return nil, err
+	}
+
 	err = r.DB().WithContext(ctx).Model(&models.WorkOrder{}).
 		Where("shift_id = ?", shiftID).
 		Count(&totalOrders).Error
@@ -65,8 +162,48 @@ func (r *ShiftRepository) GetShiftSummary(ctx context.Context, shiftID uint) (ma
 		return nil, err
 	}
 
+	// Cash taken for wallet top-ups and gift card sales is not a sale but
+	// still goes into the drawer
+	var storedValueCash float64
+	err = r.DB().WithContext(ctx).Model(&models.StoredValueEntry{}).
+		Where("shift_id = ? AND tender_method = ? AND entry_type IN ?", shiftID, models.MethodCash,
+			[]models.StoredValueEntryType{models.EntryTopUp, models.EntryGiftCardIssue}).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&storedValueCash).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Pay-ins, pay-outs and safe drops, totalled per type
+	var movements []struct {
+		Type  models.CashMovementType
+		Total float64
+	}
+	err = r.DB().WithContext(ctx).Model(&models.CashMovement{}).
+		Where("shift_id = ?", shiftID).
+		Select("type, COALESCE(SUM(amount), 0) AS total").
+		Group("type").
+		Scan(&movements).Error
+	if err != nil {
+		return nil, err
+	}
+	movementTotals := make(map[models.CashMovementType]float64, len(movements))
+	for _, movement := range movements {
+		movementTotals[movement.Type] = movement.Total
+	}
+
 	return map[string]interface{}{
-		"total_sales":  totalSales,
-		"total_orders": totalOrders,
+		"total_sales":       totalSales,
+		"total_tips":        totalTips,
+		"cash_sales":        sales.Cash,
+		"non_cash_sales":    sales.NonCash,
+		"cash_received":     cashReceived,
+		"stored_value_cash": storedValueCash,
+		"total_refunds":     totalRefunds,
+		"cash_refunds":      cashRefunds,
+		"total_orders":      totalOrders,
+		"cash_pay_ins":      movementTotals[models.CashMovementPayIn],
+		"cash_pay_outs":     movementTotals[models.CashMovementPayOut],
+		"safe_drops":        movementTotals[models.CashMovementSafeDrop],
 	}, nil
 }
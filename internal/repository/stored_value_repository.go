@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StoredValueRepository gives access to the stored-value ledger. It
+// deliberately has no update or delete: corrections are new entries.
+type StoredValueRepository struct {
+	db *gorm.DB
+}
+
+func NewStoredValueRepository(db *gorm.DB) *StoredValueRepository {
+	return &StoredValueRepository{db: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *StoredValueRepository) WithTx(tx *gorm.DB) *StoredValueRepository {
+	return &StoredValueRepository{db: tx}
+}
+
+// LockAccount serialises ledger writes for one account until the surrounding
+// transaction ends, so two movements cannot both spend the same balance.
+func (r *StoredValueRepository) LockAccount(ctx context.Context, accountType models.StoredValueAccountType, accountID uint) error {
+	key := fmt.Sprintf("stored_value:%s:%d", accountType, accountID)
+	return r.db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error
+}
+
+func (r *StoredValueRepository) GetBalance(ctx context.Context, accountType models.StoredValueAccountType, accountID uint) (float64, error) {
+	var balance float64
+	err := r.db.WithContext(ctx).Model(&models.StoredValueEntry{}).
+		Where("account_type = ? AND account_id = ?", accountType, accountID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+func (r *StoredValueRepository) Append(ctx context.Context, entry *models.StoredValueEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *StoredValueRepository) FindByAccount(ctx context.Context, accountType models.StoredValueAccountType, accountID uint, page, perPage int) ([]models.StoredValueEntry, int64, error) {
+	var entries []models.StoredValueEntry
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.StoredValueEntry{}).
+		Where("account_type = ? AND account_id = ?", accountType, accountID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Order("id DESC").Offset(offset).Limit(perPage).Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+type GiftCardRepository struct {
+	*BaseRepository[models.GiftCard]
+}
+
+func NewGiftCardRepository(db *gorm.DB) *GiftCardRepository {
+	return &GiftCardRepository{
+		BaseRepository: NewBaseRepository[models.GiftCard](db),
+	}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *GiftCardRepository) WithTx(tx *gorm.DB) *GiftCardRepository {
+	return NewGiftCardRepository(tx)
+}
+
+func (r *GiftCardRepository) FindByCode(ctx context.Context, code string) (*models.GiftCard, error) {
+	var card models.GiftCard
+	err := r.DB().WithContext(ctx).Where("code = ?", code).First(&card).Error
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// FindByCodeForUpdate loads a gift card and locks its row for the rest of the
+// transaction.
+func (r *GiftCardRepository) FindByCodeForUpdate(ctx context.Context, code string) (*models.GiftCard, error) {
+	var card models.GiftCard
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", code).
+		First(&card).Error
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+func (r *GiftCardRepository) FindExpired(ctx context.Context, now time.Time) ([]models.GiftCard, error) {
+	var cards []models.GiftCard
+	err := r.DB().WithContext(ctx).
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", models.GiftCardStatusActive, now).
+		Find(&cards).Error
+	return cards, err
+}
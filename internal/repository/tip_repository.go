@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TipAllocationRepository struct {
+	db *gorm.DB
+}
+
+func NewTipAllocationRepository(db *gorm.DB) *TipAllocationRepository {
+	return &TipAllocationRepository{db: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *TipAllocationRepository) WithTx(tx *gorm.DB) *TipAllocationRepository {
+	return &TipAllocationRepository{db: tx}
+}
+
+func (r *TipAllocationRepository) CreateAll(ctx context.Context, allocations []models.TipAllocation) error {
+	if len(allocations) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&allocations).Error
+}
+
+func (r *TipAllocationRepository) FindByPayment(ctx context.Context, paymentID uint) ([]models.TipAllocation, error) {
+	var allocations []models.TipAllocation
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("id ASC").
+		Find(&allocations).Error
+	return allocations, err
+}
+
+// StaffTipTotal is the tips one staff member received in a period. A nil
+// StaffUserID holds tips that could not be assigned to anyone.
+type StaffTipTotal struct {
+	StaffUserID *uint
+	Tips        float64
+}
+
+// SumByStaff totals the tips of completed payments made in [from, to).
+// When staffUserID is given only that staff member is included.
+func (r *TipAllocationRepository) SumByStaff(ctx context.Context, from, to time.Time, staffUserID *uint) ([]StaffTipTotal, error) {
+	var totals []StaffTipTotal
+	query := r.db.WithContext(ctx).Model(&models.TipAllocation{}).
+		Joins("JOIN payments ON payments.id = tip_allocations.payment_id").
+		Where("payments.status = ? AND payments.paid_at >= ? AND payments.paid_at < ?", models.PaymentStatusCompleted, from, to)
+	if staffUserID != nil {
+		query = query.Where("tip_allocations.staff_user_id = ?", *staffUserID)
+	}
+	err := query.
+		Select("tip_allocations.staff_user_id AS staff_user_id, COALESCE(SUM(tip_allocations.amount), 0) AS tips").
+		Group("tip_allocations.staff_user_id").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// StaffServiceTotal is the work one staff member was assigned on completed
+// orders in a period.
+type StaffServiceTotal struct {
+	StaffUserID    uint
+	ItemCount      int64
+	ServiceRevenue float64
+}
+
+// SumServicesByStaff totals the items assigned to each staff member on work
+// orders completed in [from, to).
+func (r *TipAllocationRepository) SumServicesByStaff(ctx context.Context, from, to time.Time, staffUserID *uint) ([]StaffServiceTotal, error) {
+	var totals []StaffServiceTotal
+	query := r.db.WithContext(ctx).Model(&models.WorkOrderItem{}).
+		Joins("JOIN work_orders ON work_orders.id = work_order_items.work_order_id").
+		Where("work_order_items.assigned_staff_user_id IS NOT NULL AND work_orders.deleted_at IS NULL").
+		Where("work_orders.status = ? AND work_orders.completed_at >= ? AND work_orders.completed_at < ?", models.StatusCompleted, from, to)
+	if staffUserID != nil {
+		query = query.Where("work_order_items.assigned_staff_user_id = ?", *staffUserID)
+	}
+	err := query.
+		Select("work_order_items.assigned_staff_user_id AS staff_user_id, COUNT(*) AS item_count, COALESCE(SUM(work_order_items.subtotal), 0) AS service_revenue").
+		Group("work_order_items.assigned_staff_user_id").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// DeleteByPayment removes the allocations of a payment whose tip was never
+// received.
+func (r *TipAllocationRepository) DeleteByPayment(ctx context.Context, paymentID uint) error {
+	return r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Delete(&models.TipAllocation{}).Error
+}
@@ -6,6 +6,7 @@ import (
 	"flashlight-go/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -18,6 +19,24 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	}
 }
 
+// WithTx returns a repository bound to the given transaction.
+func (r *UserRepository) WithTx(tx *gorm.DB) *UserRepository {
+	return NewUserRepository(tx)
+}
+
+// FindByIDForUpdate loads a user and locks their row until the surrounding
+// transaction ends.
+func (r *UserRepository) FindByIDForUpdate(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	err := r.DB().WithContext(ctx).Where("email = ?", email).First(&user).Error
@@ -27,6 +46,15 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models
 	return &user, nil
 }
 
+func (r *UserRepository) FindWithMembership(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	err := r.DB().WithContext(ctx).Preload("MembershipType").First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *UserRepository) FindByRole(ctx context.Context, role models.UserRole) ([]models.User, error) {
 	var users []models.User
 	err := r.DB().WithContext(ctx).Where("role = ? AND is_active = ?", role, true).Find(&users).Error
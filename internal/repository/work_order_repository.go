@@ -8,6 +8,7 @@ import (
 	"flashlight-go/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type WorkOrderRepository struct {
@@ -20,6 +21,24 @@ func NewWorkOrderRepository(db *gorm.DB) *WorkOrderRepository {
 	}
 }
 
+// WithTx returns a repository bound to the given transaction.
+func (r *WorkOrderRepository) WithTx(tx *gorm.DB) *WorkOrderRepository {
+	return NewWorkOrderRepository(tx)
+}
+
+// FindByIDForUpdate loads a work order and locks its row until the
+// surrounding transaction ends, serialising payments against it.
+func (r *WorkOrderRepository) FindByIDForUpdate(ctx context.Context, id uint) (*models.WorkOrder, error) {
+	var workOrder models.WorkOrder
+	err := r.DB().WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&workOrder, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workOrder, nil
+}
+
 func (r *WorkOrderRepository) GenerateOrderNumber(ctx context.Context) (string, error) {
 	now := time.Now()
 	prefix := fmt.Sprintf("WO-%s", now.Format("20060102"))
@@ -57,6 +76,7 @@ func (r *WorkOrderRepository) FindByStatus(ctx context.Context, status models.Wo
 	var orders []models.WorkOrder
 	err := r.DB().WithContext(ctx).
 		Where("status = ?", status).
+		Preload("Payments").
 		Preload("CustomerUser").
 		Preload("CustomerVehicle").
 		Preload("CustomerVehicle.Vehicle").
@@ -86,3 +106,16 @@ func (r *WorkOrderRepository) GetNextQueueNumber(ctx context.Context) (int, erro
 
 	return maxQueue + 1, err
 }
+
+// FindByIDs returns the work orders with the given IDs and the requested
+// relations.
+func (r *WorkOrderRepository) FindByIDs(ctx context.Context, ids []uint, preloads ...string) ([]models.WorkOrder, error) {
+	query := r.DB().WithContext(ctx)
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+
+	var workOrders []models.WorkOrder
+	err := query.Where("id IN ?", ids).Find(&workOrders).Error
+	return workOrders, err
+}
@@ -8,17 +8,71 @@ import (
 )
 
 type Router struct {
-	userHandler      *handler.UserHandler
-	workOrderHandler *handler.WorkOrderHandler
+	userHandler       *handler.UserHandler
+	workOrderHandler  *handler.WorkOrderHandler
+	productHandler    *handler.ProductHandler
+	pricingHandler    *handler.PricingRuleHandler
+	walletHandler     *handler.WalletHandler
+	loyaltyHandler    *handler.LoyaltyHandler
+	tipHandler        *handler.TipHandler
+	paymentHandler    *handler.PaymentHandler
+	webhookHandler    *handler.PaymentWebhookHandler
+	refundHandler     *handler.RefundHandler
+	receiptHandler    *handler.ReceiptHandler
+	supervisorHandler *handler.SupervisorHandler
+	cashHandler       *handler.CashMovementHandler
+	settlementHandler *handler.SettlementHandler
+	methodHandler     *handler.PaymentMethodHandler
+	fleetHandler      *handler.FleetAccountHandler
+	invoiceHandler    *handler.InvoiceHandler
+	shiftHandler      *handler.ShiftHandler
+	idempotency       gin.HandlerFunc
+	activeShift       gin.HandlerFunc
 }
 
 func NewRouter(
 	userHandler *handler.UserHandler,
 	workOrderHandler *handler.WorkOrderHandler,
+	productHandler *handler.ProductHandler,
+	pricingHandler *handler.PricingRuleHandler,
+	walletHandler *handler.WalletHandler,
+	loyaltyHandler *handler.LoyaltyHandler,
+	tipHandler *handler.TipHandler,
+	paymentHandler *handler.PaymentHandler,
+	webhookHandler *handler.PaymentWebhookHandler,
+	refundHandler *handler.RefundHandler,
+	receiptHandler *handler.ReceiptHandler,
+	supervisorHandler *handler.SupervisorHandler,
+	cashHandler *handler.CashMovementHandler,
+	settlementHandler *handler.SettlementHandler,
+	methodHandler *handler.PaymentMethodHandler,
+	fleetHandler *handler.FleetAccountHandler,
+	invoiceHandler *handler.InvoiceHandler,
+	shiftHandler *handler.ShiftHandler,
+	idempotency gin.HandlerFunc,
+	activeShift gin.HandlerFunc,
 ) *Router {
 	return &Router{
-		userHandler:      userHandler,
-		workOrderHandler: workOrderHandler,
+		userHandler:       userHandler,
+		workOrderHandler:  workOrderHandler,
+		productHandler:    productHandler,
+		pricingHandler:    pricingHandler,
+		walletHandler:     walletHandler,
+		loyaltyHandler:    loyaltyHandler,
+		tipHandler:        tipHandler,
+		paymentHandler:    paymentHandler,
+		webhookHandler:    webhookHandler,
+		refundHandler:     refundHandler,
+		receiptHandler:    receiptHandler,
+		supervisorHandler: supervisorHandler,
+		cashHandler:       cashHandler,
+		settlementHandler: settlementHandler,
+		methodHandler:     methodHandler,
+		fleetHandler:      fleetHandler,
+		invoiceHandler:    invoiceHandler,
+		shiftHandler:      shiftHandler,
+		idempotency:       idempotency,
+		activeShift:       activeShift,
 	}
 }
 
@@ -43,6 +97,12 @@ func (r *Router) Setup() *gin.Engine {
 			auth.POST("/register", r.userHandler.Create)
 		}
 
+		// Payment provider webhooks, authenticated by their signatures
+		webhooks := v1.Group("/webhooks")
+		{
+			webhooks.POST("/payments/:provider", r.webhookHandler.Receive)
+		}
+
 		// Protected routes
 		protected := v1.Group("")
 		protected.Use(middleware.AuthMiddleware())
@@ -51,6 +111,8 @@ func (r *Router) Setup() *gin.Engine {
 			users := protected.Group("/users")
 			{
 				users.GET("", r.userHandler.GetAll)
+				users.PUT("/me/supervisor-pin", middleware.RoleMiddleware("owner", "admin"), r.supervisorHandler.SetPIN)
+				users.POST("/me/approval-token", middleware.RoleMiddleware("owner", "admin"), r.supervisorHandler.IssueApprovalToken)
 				users.GET("/:id", r.userHandler.GetByID)
 				users.PUT("/:id", r.userHandler.Update)
 				users.DELETE("/:id", r.userHandler.Delete)
@@ -59,11 +121,100 @@ func (r *Router) Setup() *gin.Engine {
 			// Work Orders
 			workOrders := protected.Group("/work-orders")
 			{
-				workOrders.POST("", r.workOrderHandler.Create)
+				workOrders.POST("", r.activeShift, r.idempotency, r.workOrderHandler.Create)
+				workOrders.POST("/quote", r.workOrderHandler.Quote)
 				workOrders.GET("", r.workOrderHandler.GetAll)
 				workOrders.GET("/:id", r.workOrderHandler.GetByID)
 				workOrders.PUT("/:id", r.workOrderHandler.Update)
 				workOrders.DELETE("/:id", r.workOrderHandler.Delete)
+				workOrders.GET("/:id/payments", middleware.RoleMiddleware("owner", "admin", "cashier"), r.paymentHandler.GetByWorkOrder)
+				workOrders.GET("/:id/refunds", middleware.RoleMiddleware("owner", "admin", "cashier"), r.refundHandler.GetByWorkOrder)
+				workOrders.GET("/:id/receipt", middleware.RoleMiddleware("owner", "admin", "cashier"), r.receiptHandler.GetReceipt)
+				workOrders.POST("/:id/receipt/email", middleware.RoleMiddleware("owner", "admin", "cashier"), r.receiptHandler.Resend)
+				workOrders.GET("/:id/receipt/deliveries", middleware.RoleMiddleware("owner", "admin", "cashier"), r.receiptHandler.GetDeliveries)
+			}
+
+			// Payments
+			payments := protected.Group("/payments")
+			payments.Use(middleware.RoleMiddleware("owner", "admin", "cashier"))
+			{
+				payments.POST("", r.activeShift, r.idempotency, r.paymentHandler.Create)
+				payments.POST("/checkout", r.activeShift, r.idempotency, r.paymentHandler.Checkout)
+				payments.POST("/qris", r.activeShift, r.idempotency, r.paymentHandler.CreateQRIS)
+				payments.GET("", middleware.RoleMiddleware("owner", "admin"), r.paymentHandler.GetAll)
+				payments.GET("/:id", r.paymentHandler.GetByID)
+				payments.GET("/:id/qris.png", r.paymentHandler.GetQRISImage)
+				payments.PUT("/:id", r.paymentHandler.Update)
+				payments.POST("/:id/void", r.activeShift, r.paymentHandler.Void)
+				payments.GET("/:id/refunds", r.refundHandler.GetByPayment)
+				payments.POST("/:id/refunds", middleware.RoleMiddleware("owner", "admin"), r.refundHandler.Create)
+			}
+
+			// Payment Methods
+			protected.GET("/payment-methods", r.methodHandler.GetAvailable)
+
+			// Cash drawer movements
+			cashMovements := protected.Group("/cash-movements")
+			cashMovements.Use(middleware.RoleMiddleware("owner", "admin", "cashier"))
+			{
+				cashMovements.POST("", r.activeShift, r.idempotency, r.cashHandler.Create)
+			}
+
+			// Shifts
+			shifts := protected.Group("/shifts")
+			shifts.Use(middleware.RoleMiddleware("owner", "admin", "cashier"))
+			{
+				shifts.POST("", r.shiftHandler.Start)
+				shifts.GET("/current", r.shiftHandler.GetCurrent)
+				shifts.GET("/denominations", r.shiftHandler.GetDenominations)
+				shifts.GET("/handovers", middleware.RoleMiddleware("owner", "admin"), r.shiftHandler.GetHandovers)
+				shifts.GET("/handovers/pending", r.shiftHandler.GetPendingHandovers)
+				shifts.GET("/:id", r.shiftHandler.GetByID)
+				shifts.GET("/:id/summary", r.shiftHandler.GetSummary)
+				shifts.POST("/:id/close", r.shiftHandler.Close)
+				shifts.GET("/:id/cash-movements", r.cashHandler.GetByShift)
+			}
+
+			// Products
+			products := protected.Group("/products")
+			{
+				products.GET("/:id/prices", r.productHandler.GetPriceHistory)
+			}
+
+			// Product Categories
+			protected.GET("/product-categories", r.productHandler.GetCategories)
+
+			// Wallets
+			wallets := protected.Group("/wallets")
+			{
+				wallets.GET("/:customerId", r.walletHandler.GetBalance)
+				wallets.GET("/:customerId/entries", r.walletHandler.GetEntries)
+				wallets.POST("/:customerId/top-ups", middleware.RoleMiddleware("owner", "admin", "cashier"), r.activeShift, r.walletHandler.TopUp)
+			}
+
+			// Gift Cards
+			giftCards := protected.Group("/gift-cards")
+			{
+				giftCards.POST("", middleware.RoleMiddleware("owner", "admin", "cashier"), r.activeShift, r.walletHandler.IssueGiftCard)
+				giftCards.POST("/redeem", r.walletHandler.RedeemGiftCard)
+				giftCards.GET("/:code", middleware.RoleMiddleware("owner", "admin", "cashier"), r.walletHandler.GetGiftCard)
+			}
+
+			// Loyalty
+			loyalty := protected.Group("/loyalty")
+			{
+				loyalty.GET("/rewards", r.loyaltyHandler.GetActiveRewards)
+				loyalty.POST("/redeem", r.loyaltyHandler.Redeem)
+				loyalty.GET("/customers/:customerId", r.loyaltyHandler.GetBalance)
+				loyalty.GET("/customers/:customerId/history", r.loyaltyHandler.GetHistory)
+			}
+
+			// Reports
+			reports := protected.Group("/reports")
+			{
+				reports.GET("/staff-earnings", middleware.RoleMiddleware("owner", "admin", "staff"), r.tipHandler.GetStaffEarnings)
+				reports.GET("/payment-methods", middleware.RoleMiddleware("owner", "admin"), r.methodHandler.GetRevenueReport)
+				reports.GET("/accounts-receivable", middleware.RoleMiddleware("owner", "admin"), r.invoiceHandler.GetAgingReport)
 			}
 
 			// Admin only routes
@@ -71,6 +222,65 @@ func (r *Router) Setup() *gin.Engine {
 			admin.Use(middleware.RoleMiddleware("owner", "admin"))
 			{
 				admin.POST("/users", r.userHandler.Create)
+				admin.POST("/products/:id/prices", r.productHandler.SchedulePriceChange)
+				admin.DELETE("/products/:id/prices/:priceId", r.productHandler.CancelPriceChange)
+				admin.PUT("/product-categories/:id", r.productHandler.UpdateCategory)
+
+				admin.GET("/payment-methods", r.methodHandler.GetAll)
+				admin.POST("/payment-methods", r.methodHandler.Create)
+				admin.PUT("/payment-methods/:id", r.methodHandler.Update)
+
+				admin.POST("/pricing-rules", r.pricingHandler.Create)
+				admin.GET("/pricing-rules", r.pricingHandler.GetAll)
+				admin.GET("/pricing-rules/:id", r.pricingHandler.GetByID)
+				admin.PUT("/pricing-rules/:id", r.pricingHandler.Update)
+				admin.DELETE("/pricing-rules/:id", r.pricingHandler.Delete)
+
+				admin.POST("/vehicle-price-tiers", r.pricingHandler.CreateVehicleTier)
+				admin.GET("/vehicle-price-tiers", r.pricingHandler.GetAllVehicleTiers)
+				admin.GET("/vehicle-price-tiers/:id", r.pricingHandler.GetVehicleTierByID)
+				admin.PUT("/vehicle-price-tiers/:id", r.pricingHandler.UpdateVehicleTier)
+				admin.DELETE("/vehicle-price-tiers/:id", r.pricingHandler.DeleteVehicleTier)
+
+				admin.POST("/promos", r.pricingHandler.CreatePromo)
+				admin.GET("/promos", r.pricingHandler.GetAllPromos)
+				admin.GET("/promos/:id", r.pricingHandler.GetPromoByID)
+				admin.PUT("/promos/:id", r.pricingHandler.UpdatePromo)
+				admin.DELETE("/promos/:id", r.pricingHandler.DeletePromo)
+
+				admin.POST("/gift-cards/expire", r.walletHandler.ExpireGiftCards)
+
+				admin.GET("/loyalty/earn-rules", r.loyaltyHandler.GetEarnRules)
+				admin.POST("/loyalty/earn-rules", r.loyaltyHandler.CreateEarnRule)
+				admin.PUT("/loyalty/earn-rules/:id", r.loyaltyHandler.UpdateEarnRule)
+				admin.DELETE("/loyalty/earn-rules/:id", r.loyaltyHandler.DeleteEarnRule)
+				admin.GET("/loyalty/rewards", r.loyaltyHandler.GetAllRewards)
+				admin.POST("/loyalty/rewards", r.loyaltyHandler.CreateReward)
+				admin.PUT("/loyalty/rewards/:id", r.loyaltyHandler.UpdateReward)
+				admin.DELETE("/loyalty/rewards/:id", r.loyaltyHandler.DeleteReward)
+				admin.POST("/loyalty/expire", r.loyaltyHandler.ExpirePoints)
+
+				admin.POST("/settlements/import", r.settlementHandler.Import)
+				admin.GET("/settlements", r.settlementHandler.GetAll)
+				admin.GET("/settlements/layouts", r.settlementHandler.GetLayouts)
+				admin.GET("/settlements/report", r.settlementHandler.GetReport)
+				admin.GET("/settlements/:id", r.settlementHandler.GetByID)
+
+				admin.GET("/fleet-accounts", r.fleetHandler.GetAll)
+				admin.POST("/fleet-accounts", r.fleetHandler.Create)
+				admin.GET("/fleet-accounts/:id", r.fleetHandler.GetByID)
+				admin.PUT("/fleet-accounts/:id", r.fleetHandler.Update)
+				admin.POST("/fleet-accounts/:id/vehicles", r.fleetHandler.AddVehicle)
+				admin.DELETE("/fleet-accounts/:id/vehicles/:vehicleId", r.fleetHandler.RemoveVehicle)
+				admin.POST("/fleet-accounts/:id/drivers", r.fleetHandler.AddDriver)
+				admin.DELETE("/fleet-accounts/:id/drivers/:userId", r.fleetHandler.RemoveDriver)
+				admin.GET("/fleet-accounts/:id/payments", r.invoiceHandler.GetPayments)
+				admin.POST("/fleet-accounts/:id/payments", r.invoiceHandler.ReceivePayment)
+
+				admin.POST("/invoices/generate", r.invoiceHandler.Generate)
+				admin.GET("/invoices", r.invoiceHandler.GetAll)
+				admin.GET("/invoices/:id", r.invoiceHandler.GetByID)
+				admin.GET("/invoices/:id/pdf", r.invoiceHandler.PDF)
 			}
 		}
 	}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// safeDropCategory is the category of safe drops recorded without one.
+const safeDropCategory = "safe"
+
+type CashMovementService struct {
+	cashMovementRepo *repository.CashMovementRepository
+	shiftRepo        *repository.ShiftRepository
+	db               *gorm.DB
+}
+
+func NewCashMovementService(
+	cashMovementRepo *repository.CashMovementRepository,
+	shiftRepo *repository.ShiftRepository,
+	db *gorm.DB,
+) *CashMovementService {
+	return &CashMovementService{
+		cashMovementRepo: cashMovementRepo,
+		shiftRepo:        shiftRepo,
+		db:               db,
+	}
+}
+
+// Create records a pay-in, pay-out or safe drop against an active shift,
+// approved by the given supervisor. Cash can only be taken out of the drawer
+// up to what it is expected to hold.
+func (s *CashMovementService) Create(ctx context.Context, req dto.CreateCashMovementRequest, recordedByUserID *uint, approval *Approval) (*models.CashMovement, error) {
+	movementType := models.CashMovementType(req.Type)
+	amount := roundAmount(req.Amount)
+
+	category := strings.TrimSpace(req.Category)
+	if category == "" {
+		if movementType != models.CashMovementSafeDrop {
+			return nil, errors.New("category is required for pay-ins and pay-outs")
+		}
+		category = safeDropCategory
+	}
+
+	shiftID, err := s.resolveShiftID(ctx, req.ShiftID, recordedByUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var movement *models.CashMovement
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+		shift, err := shiftRepo.FindByIDForUpdate(ctx, shiftID)
+		if err != nil {
+			return errors.New("shift not found")
+		}
+		if shift.Status != models.ShiftStatusActive {
+			return errors.New("cash can only be moved in an active shift")
+		}
+
+		if movementType != models.CashMovementPayIn {
+			summary, err := shiftRepo.GetShiftSummary(ctx, shift.ID)
+			if err != nil {
+				return err
+			}
+			// The error says nothing about the amounts, since the drawer is
+			// counted blind at shift close
+			if amount > expectedCash(shift, summary) {
+				return errors.New("the drawer does not hold enough cash for this movement")
+			}
+		}
+
+		movement = &models.CashMovement{
+			ShiftID:          shift.ID,
+			Type:             movementType,
+			Category:         category,
+			Amount:           amount,
+			Note:             req.Note,
+			RecordedByUserID: recordedByUserID,
+			ApprovedByUserID: approval.Supervisor.ID,
+		}
+		if err := s.cashMovementRepo.WithTx(tx).Create(ctx, movement); err != nil {
+			return err
+		}
+		return approval.Consume(ctx, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+func (s *CashMovementService) GetByShift(ctx context.Context, shiftID uint) ([]models.CashMovement, error) {
+	return s.cashMovementRepo.FindByShift(ctx, shiftID)
+}
+
+// resolveShiftID returns the shift a movement is recorded against: the
+// requested one, or else the recorder's own active shift.
+func (s *CashMovementService) resolveShiftID(ctx context.Context, shiftID *uint, userID *uint) (uint, error) {
+	if shiftID != nil {
+		return *shiftID, nil
+	}
+	if userID == nil {
+		return 0, ErrNoActiveShift
+	}
+
+	shift, err := s.shiftRepo.FindActiveShiftByUser(ctx, *userID)
+	if err != nil {
+		return 0, err
+	}
+	if shift == nil {
+		return 0, ErrNoActiveShift
+	}
+	return shift.ID, nil
+}
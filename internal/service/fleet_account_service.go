@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// FleetAccountDetail is a fleet account with its vehicles, drivers and what
+// it owes.
+type FleetAccountDetail struct {
+	*models.FleetAccount
+	Balance dto.FleetAccountBalance `json:"balance"`
+}
+
+type FleetAccountService struct {
+	fleetAccountRepo    *repository.FleetAccountRepository
+	customerVehicleRepo *repository.CustomerVehicleRepository
+	userRepo            *repository.UserRepository
+	paymentRepo         *repository.PaymentRepository
+	invoiceRepo         *repository.InvoiceRepository
+}
+
+func NewFleetAccountService(
+	fleetAccountRepo *repository.FleetAccountRepository,
+	customerVehicleRepo *repository.CustomerVehicleRepository,
+	userRepo *repository.UserRepository,
+	paymentRepo *repository.PaymentRepository,
+	invoiceRepo *repository.InvoiceRepository,
+) *FleetAccountService {
+	return &FleetAccountService{
+		fleetAccountRepo:    fleetAccountRepo,
+		customerVehicleRepo: customerVehicleRepo,
+		userRepo:            userRepo,
+		paymentRepo:         paymentRepo,
+		invoiceRepo:         invoiceRepo,
+	}
+}
+
+func (s *FleetAccountService) Create(ctx context.Context, req dto.CreateFleetAccountRequest) (*models.FleetAccount, error) {
+	account := &models.FleetAccount{
+		Name:            req.Name,
+		ContactName:     req.ContactName,
+		BillingEmail:    req.BillingEmail,
+		PhoneNumber:     req.PhoneNumber,
+		BillingAddress:  req.BillingAddress,
+		TaxID:           req.TaxID,
+		CreditLimit:     roundAmount(req.CreditLimit),
+		PaymentTermDays: 30,
+		IsActive:        true,
+	}
+	if req.PaymentTermDays != nil {
+		account.PaymentTermDays = *req.PaymentTermDays
+	}
+
+	if err := s.fleetAccountRepo.Create(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *FleetAccountService) Update(ctx context.Context, id uint, req dto.UpdateFleetAccountRequest) (*models.FleetAccount, error) {
+	account, err := s.fleetAccountRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("fleet account not found")
+	}
+
+	if req.Name != nil {
+		account.Name = *req.Name
+	}
+	if req.ContactName != nil {
+		account.ContactName = req.ContactName
+	}
+	if req.BillingEmail != nil {
+		account.BillingEmail = req.BillingEmail
+	}
+	if req.PhoneNumber != nil {
+		account.PhoneNumber = req.PhoneNumber
+	}
+	if req.BillingAddress != nil {
+		account.BillingAddress = req.BillingAddress
+	}
+	if req.TaxID != nil {
+		account.TaxID = req.TaxID
+	}
+	if req.CreditLimit != nil {
+		account.CreditLimit = roundAmount(*req.CreditLimit)
+	}
+	if req.PaymentTermDays != nil {
+		account.PaymentTermDays = *req.PaymentTermDays
+	}
+	if req.IsActive != nil {
+		account.IsActive = *req.IsActive
+	}
+
+	if err := s.fleetAccountRepo.Update(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *FleetAccountService) GetAll(ctx context.Context, page, perPage int) ([]models.FleetAccount, *dto.PaginationMeta, error) {
+	accounts, total, err := s.fleetAccountRepo.FindAll(ctx, page, perPage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+
+	meta := &dto.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return accounts, meta, nil
+}
+
+func (s *FleetAccountService) GetByID(ctx context.Context, id uint) (*FleetAccountDetail, error) {
+	account, err := s.fleetAccountRepo.FindWithDetails(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.Balance(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FleetAccountDetail{FleetAccount: account, Balance: *balance}, nil
+}
+
+// Balance works out what an account owes: charges not invoiced yet plus
+// what is unpaid on its invoices, less payments not allocated to any.
+func (s *FleetAccountService) Balance(ctx context.Context, accountID uint) (*dto.FleetAccountBalance, error) {
+	unbilled, err := s.paymentRepo.SumUnbilledByAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	invoiced, err := s.invoiceRepo.SumUnpaidByAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	credit, err := s.invoiceRepo.SumCreditsByAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.FleetAccountBalance{
+		Unbilled: roundAmount(unbilled),
+		Invoiced: roundAmount(invoiced),
+		Credit:   roundAmount(credit),
+		Total:    roundAmount(unbilled + invoiced - credit),
+	}, nil
+}
+
+// AddVehicle puts a customer's vehicle on an account. A vehicle belongs to
+// at most one account.
+func (s *FleetAccountService) AddVehicle(ctx context.Context, accountID, customerVehicleID uint) (*models.CustomerVehicle, error) {
+	if _, err := s.fleetAccountRepo.FindByID(ctx, accountID); err != nil {
+		return nil, errors.New("fleet account not found")
+	}
+	vehicle, err := s.customerVehicleRepo.FindByID(ctx, customerVehicleID)
+	if err != nil {
+		return nil, errors.New("customer vehicle not found")
+	}
+	if vehicle.FleetAccountID != nil && *vehicle.FleetAccountID != accountID {
+		return nil, fmt.Errorf("vehicle %s already belongs to fleet account %d", vehicle.LicensePlate, *vehicle.FleetAccountID)
+	}
+
+	vehicle.FleetAccountID = &accountID
+	if err := s.customerVehicleRepo.Update(ctx, vehicle); err != nil {
+		return nil, err
+	}
+	return vehicle, nil
+}
+
+// RemoveVehicle takes a vehicle off an account. Charges already made for it
+// stay on the account.
+func (s *FleetAccountService) RemoveVehicle(ctx context.Context, accountID, customerVehicleID uint) error {
+	vehicle, err := s.customerVehicleRepo.FindByID(ctx, customerVehicleID)
+	if err != nil || vehicle.FleetAccountID == nil || *vehicle.FleetAccountID != accountID {
+		return errors.New("vehicle is not on this fleet account")
+	}
+
+	vehicle.FleetAccountID = nil
+	return s.customerVehicleRepo.Update(ctx, vehicle)
+}
+
+// AddDriver authorises a user to charge orders to an account.
+func (s *FleetAccountService) AddDriver(ctx context.Context, accountID, userID uint) (*models.FleetDriver, error) {
+	if _, err := s.fleetAccountRepo.FindByID(ctx, accountID); err != nil {
+		return nil, errors.New("fleet account not found")
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	existing, err := s.fleetAccountRepo.FindDriver(ctx, accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		existing.User = user
+		return existing, nil
+	}
+
+	driver := &models.FleetDriver{FleetAccountID: accountID, UserID: userID}
+	if err := s.fleetAccountRepo.CreateDriver(ctx, driver); err != nil {
+		return nil, err
+	}
+	driver.User = user
+	return driver, nil
+}
+
+func (s *FleetAccountService) RemoveDriver(ctx context.Context, accountID, userID uint) error {
+	removed, err := s.fleetAccountRepo.DeleteDriver(ctx, accountID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errors.New("user is not a driver of this fleet account")
+	}
+	return nil
+}
+
+// AccountForCharge returns the fleet account a work order is charged to on
+// account, as part of the caller's transaction. The order's vehicle must
+// belong to an active account, its customer must be one of the account's
+// drivers, and the charge must fit within the account's credit limit. The
+// account stays locked until the transaction ends, so concurrent charges
+// see each other against the limit.
+func (s *FleetAccountService) AccountForCharge(ctx context.Context, tx *gorm.DB, workOrder *models.WorkOrder, amount float64) (*models.FleetAccount, error) {
+	if workOrder.CustomerVehicleID == nil {
+		return nil, errors.New("on-account payments require a work order with a fleet vehicle")
+	}
+	vehicle, err := repository.NewCustomerVehicleRepository(tx).FindByID(ctx, *workOrder.CustomerVehicleID)
+	if err != nil {
+		return nil, errors.New("customer vehicle not found")
+	}
+	if vehicle.FleetAccountID == nil {
+		return nil, fmt.Errorf("vehicle %s does not belong to a fleet account", vehicle.LicensePlate)
+	}
+
+	fleetAccountRepo := s.fleetAccountRepo.WithTx(tx)
+	account, err := fleetAccountRepo.FindByIDForUpdate(ctx, *vehicle.FleetAccountID)
+	if err != nil {
+		return nil, errors.New("fleet account not found")
+	}
+	if !account.IsActive {
+		return nil, fmt.Errorf("fleet account %s is not active", account.Name)
+	}
+
+	if workOrder.CustomerUserID == nil {
+		return nil, errors.New("on-account payments require the driver as the work order's customer")
+	}
+	driver, err := fleetAccountRepo.FindDriver(ctx, account.ID, *workOrder.CustomerUserID)
+	if err != nil {
+		return nil, err
+	}
+	if driver == nil {
+		return nil, fmt.Errorf("the customer is not an authorised driver of %s", account.Name)
+	}
+
+	if account.CreditLimit > 0 {
+		unbilled, err := s.paymentRepo.WithTx(tx).SumUnbilledByAccount(ctx, account.ID)
+		if err != nil {
+			return nil, err
+		}
+		invoiceRepo := s.invoiceRepo.WithTx(tx)
+		invoiced, err := invoiceRepo.SumUnpaidByAccount(ctx, account.ID)
+		if err != nil {
+			return nil, err
+		}
+		credit, err := invoiceRepo.SumCreditsByAccount(ctx, account.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		available := roundAmount(account.CreditLimit - (unbilled + invoiced - credit))
+		if roundAmount(amount) > available {
+			return nil, fmt.Errorf("charge of %.2f exceeds the %.2f of credit left on %s", amount, math.Max(available, 0), account.Name)
+		}
+	}
+
+	return account, nil
+}
@@ -0,0 +1,565 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/invoice"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/receipt"
+	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// invoicePeriodFormat is how billed months are written, e.g. 2024-01.
+const invoicePeriodFormat = "2006-01"
+
+type InvoiceService struct {
+	invoiceRepo         *repository.InvoiceRepository
+	fleetAccountRepo    *repository.FleetAccountRepository
+	paymentRepo         *repository.PaymentRepository
+	workOrderRepo       *repository.WorkOrderRepository
+	paymentMethods      *PaymentMethodService
+	header              receipt.Header
+	paymentInstructions []string
+	location            *time.Location
+	db                  *gorm.DB
+}
+
+func NewInvoiceService(
+	invoiceRepo *repository.InvoiceRepository,
+	fleetAccountRepo *repository.FleetAccountRepository,
+	paymentRepo *repository.PaymentRepository,
+	workOrderRepo *repository.WorkOrderRepository,
+	paymentMethods *PaymentMethodService,
+	header receipt.Header,
+	paymentInstructions []string,
+	location *time.Location,
+	db *gorm.DB,
+) *InvoiceService {
+	return &InvoiceService{
+		invoiceRepo:         invoiceRepo,
+		fleetAccountRepo:    fleetAccountRepo,
+		paymentRepo:         paymentRepo,
+		workOrderRepo:       workOrderRepo,
+		paymentMethods:      paymentMethods,
+		header:              header,
+		paymentInstructions: paymentInstructions,
+		location:            location,
+		db:                  db,
+	}
+}
+
+// Generate invoices every fleet account for its on-account charges up to the
+// end of a month. Charges from earlier months that were not invoiced yet are
+// included. Accounts already invoiced for the month are skipped, so running
+// it again only invoices accounts that were missed.
+func (s *InvoiceService) Generate(ctx context.Context, period string, generatedByUserID *uint) ([]models.Invoice, error) {
+	start, err := time.ParseInLocation(invoicePeriodFormat, period, s.location)
+	if err != nil {
+		return nil, errors.New("period must be a month in YYYY-MM format")
+	}
+	end := start.AddDate(0, 1, 0)
+	if end.After(time.Now()) {
+		return nil, fmt.Errorf("%s has not ended yet", period)
+	}
+
+	accountIDs, err := s.paymentRepo.FindAccountsWithUnbilledCharges(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := []models.Invoice{}
+	for _, accountID := range accountIDs {
+		generated, err := s.generateForAccount(ctx, accountID, period, end, generatedByUserID)
+		if err != nil {
+			return invoices, err
+		}
+		if generated == nil {
+			continue
+		}
+
+		log.Printf("Invoice %s for fleet account %d generated: %d orders, %.2f", generated.InvoiceNumber, accountID, len(generated.Lines), generated.TotalAmount)
+		invoices = append(invoices, *generated)
+	}
+
+	return invoices, nil
+}
+
+// GenerateLastMonth runs Generate for the month before the current one. It
+// is meant to be run periodically and does nothing once the month has been
+// invoiced.
+func (s *InvoiceService) GenerateLastMonth(ctx context.Context) (int, error) {
+	now := time.Now().In(s.location)
+	lastMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, s.location).AddDate(0, -1, 0)
+
+	invoices, err := s.Generate(ctx, lastMonth.Format(invoicePeriodFormat), nil)
+	return len(invoices), err
+}
+
+// generateForAccount invoices one account's charges paid before end. It
+// returns nil when the account has nothing to invoice or already has an
+// invoice for the period.
+func (s *InvoiceService) generateForAccount(ctx context.Context, accountID uint, period string, end time.Time, generatedByUserID *uint) (*models.Invoice, error) {
+	var generated *models.Invoice
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		account, err := s.fleetAccountRepo.WithTx(tx).FindByIDForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		// Charges left over once the month is invoiced go on the next invoice
+		invoiceRepo := s.invoiceRepo.WithTx(tx)
+		existing, err := invoiceRepo.FindByAccountPeriod(ctx, account.ID, period)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return nil
+		}
+
+		charges, err := s.paymentRepo.WithTx(tx).FindUnbilledChargesForUpdate(ctx, account.ID, end)
+		if err != nil {
+			return err
+		}
+		if len(charges) == 0 {
+			return nil
+		}
+
+		workOrderIDs := make([]uint, len(charges))
+		paymentIDs := make([]uint, len(charges))
+		for i, charge := range charges {
+			workOrderIDs[i] = charge.WorkOrderID
+			paymentIDs[i] = charge.ID
+		}
+		workOrders, err := s.workOrderRepo.WithTx(tx).FindByIDs(ctx, workOrderIDs, "CustomerVehicle", "CustomerUser")
+		if err != nil {
+			return err
+		}
+		workOrdersByID := make(map[uint]*models.WorkOrder, len(workOrders))
+		for i := range workOrders {
+			workOrdersByID[workOrders[i].ID] = &workOrders[i]
+		}
+
+		invoiceNumber, err := invoiceRepo.GenerateInvoiceNumber(ctx, period)
+		if err != nil {
+			return err
+		}
+
+		today := startOfDay(time.Now(), s.location)
+		generated = &models.Invoice{
+			InvoiceNumber:     invoiceNumber,
+			FleetAccountID:    account.ID,
+			Period:            period,
+			IssueDate:         today,
+			DueDate:           today.AddDate(0, 0, account.PaymentTermDays),
+			Status:            models.InvoiceStatusOpen,
+			GeneratedByUserID: generatedByUserID,
+		}
+
+		lines := make([]models.InvoiceLine, len(charges))
+		for i, charge := range charges {
+			line := models.InvoiceLine{
+				PaymentID:   charge.ID,
+				WorkOrderID: charge.WorkOrderID,
+				ChargedAt:   charge.CreatedAt,
+				Amount:      roundAmount(charge.AmountPaid - charge.ChangeAmount - charge.RefundedAmount + charge.TipAmount),
+			}
+			if charge.PaidAt != nil {
+				line.ChargedAt = *charge.PaidAt
+			}
+			if workOrder := workOrdersByID[charge.WorkOrderID]; workOrder != nil {
+				line.OrderNumber = workOrder.OrderNumber
+				if workOrder.CustomerVehicle != nil {
+					line.LicensePlate = workOrder.CustomerVehicle.LicensePlate
+				}
+				if workOrder.CustomerUser != nil {
+					line.DriverName = workOrder.CustomerUser.Name
+				}
+			}
+			lines[i] = line
+			generated.TotalAmount += line.Amount
+		}
+		generated.TotalAmount = roundAmount(generated.TotalAmount)
+
+		if err := invoiceRepo.Create(ctx, generated); err != nil {
+			return err
+		}
+		for i := range lines {
+			lines[i].InvoiceID = generated.ID
+		}
+		if err := tx.Create(&lines).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Payment{}).Where("id IN ?", paymentIDs).Update("invoice_id", generated.ID).Error; err != nil {
+			return err
+		}
+		generated.Lines = lines
+
+		// Payments received ahead of the invoice are applied to it
+		credits, err := invoiceRepo.FindCreditsForUpdate(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		for i := range credits {
+			balance := roundAmount(generated.TotalAmount - generated.PaidAmount)
+			if balance <= 0 {
+				break
+			}
+			if err := allocateToInvoice(ctx, tx, &credits[i], generated, math.Min(credits[i].UnallocatedAmount, balance)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return generated, nil
+}
+
+// ReceivePayment records money received from a fleet account and allocates
+// it to the account's unpaid invoices: as requested, or else to the oldest
+// due first. Whatever is not allocated stays on the account as credit and is
+// applied to its next invoice.
+func (s *InvoiceService) ReceivePayment(ctx context.Context, accountID uint, req dto.ReceiveAccountPaymentRequest, recordedByUserID *uint) (*models.AccountPayment, error) {
+	method, err := s.paymentMethods.Find(ctx, req.Method)
+	if err != nil {
+		return nil, err
+	}
+	if method.Code == models.MethodAccount || method.Code == models.MethodWallet {
+		return nil, fmt.Errorf("fleet accounts cannot pay by %s", method.DisplayName)
+	}
+
+	receivedAt := time.Now()
+	if req.ReceivedDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", req.ReceivedDate, s.location)
+		if err != nil {
+			return nil, errors.New("received_date must be a date in YYYY-MM-DD format")
+		}
+		if date.After(receivedAt) {
+			return nil, errors.New("received_date cannot be in the future")
+		}
+		receivedAt = date
+	}
+
+	var payment *models.AccountPayment
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		account, err := s.fleetAccountRepo.WithTx(tx).FindByIDForUpdate(ctx, accountID)
+		if err != nil {
+			return errors.New("fleet account not found")
+		}
+
+		invoiceRepo := s.invoiceRepo.WithTx(tx)
+		invoices, err := invoiceRepo.FindUnpaidByAccountForUpdate(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		paymentNumber, err := invoiceRepo.GenerateAccountPaymentNumber(ctx)
+		if err != nil {
+			return err
+		}
+
+		amount := roundAmount(req.Amount)
+		payment = &models.AccountPayment{
+			PaymentNumber:     paymentNumber,
+			FleetAccountID:    account.ID,
+			Method:            method.Code,
+			Amount:            amount,
+			UnallocatedAmount: amount,
+			ReferenceNumber:   req.ReferenceNumber,
+			ReceivedAt:        receivedAt,
+			Note:              req.Note,
+			RecordedByUserID:  recordedByUserID,
+		}
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+
+		if len(req.Allocations) == 0 {
+			for i := range invoices {
+				if payment.UnallocatedAmount <= 0 {
+					break
+				}
+				balance := roundAmount(invoices[i].TotalAmount - invoices[i].PaidAmount)
+				if err := allocateToInvoice(ctx, tx, payment, &invoices[i], math.Min(payment.UnallocatedAmount, balance)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		unpaid := make(map[uint]*models.Invoice, len(invoices))
+		for i := range invoices {
+			unpaid[invoices[i].ID] = &invoices[i]
+		}
+		for _, allocation := range req.Allocations {
+			target, ok := unpaid[allocation.InvoiceID]
+			if !ok {
+				return fmt.Errorf("invoice %d is not an unpaid invoice of this account", allocation.InvoiceID)
+			}
+			allocated := roundAmount(allocation.Amount)
+			if balance := roundAmount(target.TotalAmount - target.PaidAmount); allocated > balance {
+				return fmt.Errorf("%.2f exceeds the balance of %.2f on invoice %s", allocated, balance, target.InvoiceNumber)
+			}
+			if allocated > payment.UnallocatedAmount {
+				return errors.New("allocations exceed the amount received")
+			}
+			if err := allocateToInvoice(ctx, tx, payment, target, allocated); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// allocateToInvoice applies part of an account payment to an invoice. Both
+// should be locked by the caller.
+func allocateToInvoice(ctx context.Context, tx *gorm.DB, payment *models.AccountPayment, target *models.Invoice, amount float64) error {
+	amount = roundAmount(amount)
+	if amount <= 0 {
+		return nil
+	}
+
+	allocation := models.InvoiceAllocation{
+		AccountPaymentID: payment.ID,
+		InvoiceID:        target.ID,
+		Amount:           amount,
+	}
+	if err := tx.WithContext(ctx).Create(&allocation).Error; err != nil {
+		return err
+	}
+
+	payment.UnallocatedAmount = roundAmount(payment.UnallocatedAmount - amount)
+	payment.Allocations = append(payment.Allocations, allocation)
+	if err := tx.WithContext(ctx).Model(&models.AccountPayment{}).Where("id = ?", payment.ID).Update("unallocated_amount", payment.UnallocatedAmount).Error; err != nil {
+		return err
+	}
+
+	target.PaidAmount = roundAmount(target.PaidAmount + amount)
+	target.Status = invoiceStatus(target.TotalAmount, target.PaidAmount)
+	target.Allocations = append(target.Allocations, allocation)
+	return tx.WithContext(ctx).Model(&models.Invoice{}).Where("id = ?", target.ID).Updates(map[string]interface{}{
+		"paid_amount": target.PaidAmount,
+		"status":      target.Status,
+	}).Error
+}
+
+func (s *InvoiceService) GetAll(ctx context.Context, page, perPage int, accountID *uint, status string) ([]models.Invoice, *dto.PaginationMeta, error) {
+	invoices, total, err := s.invoiceRepo.FindFiltered(ctx, page, perPage, accountID, status)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+
+	meta := &dto.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return invoices, meta, nil
+}
+
+func (s *InvoiceService) GetByID(ctx context.Context, id uint) (*models.Invoice, error) {
+	return s.invoiceRepo.FindWithDetails(ctx, id)
+}
+
+func (s *InvoiceService) GetPayments(ctx context.Context, accountID uint) ([]models.AccountPayment, error) {
+	return s.invoiceRepo.FindAccountPayments(ctx, accountID)
+}
+
+// PDF renders an invoice as an A4 PDF. It also returns the invoice, whose
+// number names the file.
+func (s *InvoiceService) PDF(ctx context.Context, id uint) ([]byte, *models.Invoice, error) {
+	record, err := s.invoiceRepo.FindWithDetails(ctx, id)
+	if err != nil {
+		return nil, nil, errors.New("invoice not found")
+	}
+
+	doc := &invoice.Invoice{
+		Header:              s.header,
+		Number:              record.InvoiceNumber,
+		Period:              record.Period,
+		IssueDate:           record.IssueDate.In(s.location),
+		DueDate:             record.DueDate.In(s.location),
+		TotalAmount:         record.TotalAmount,
+		PaidAmount:          record.PaidAmount,
+		PaymentInstructions: s.paymentInstructions,
+	}
+	if account := record.FleetAccount; account != nil {
+		doc.BillTo.Name = account.Name
+		if account.ContactName != nil {
+			doc.BillTo.Contact = *account.ContactName
+		}
+		if account.BillingAddress != nil {
+			doc.BillTo.Address = *account.BillingAddress
+		}
+		if account.TaxID != nil {
+			doc.BillTo.TaxID = *account.TaxID
+		}
+	}
+	for _, line := range record.Lines {
+		doc.Lines = append(doc.Lines, invoice.Line{
+			Date:         line.ChargedAt.In(s.location),
+			OrderNumber:  line.OrderNumber,
+			LicensePlate: line.LicensePlate,
+			Driver:       line.DriverName,
+			Amount:       line.Amount,
+		})
+	}
+
+	data, err := invoice.PDF(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, record, nil
+}
+
+// GetAging reports what each fleet account owes as of today. Unpaid invoice
+// balances are bucketed by how many days they are past due; charges not
+// invoiced yet and unallocated payments are shown alongside.
+func (s *InvoiceService) GetAging(ctx context.Context) (*dto.ARAgingResponse, error) {
+	today := startOfDay(time.Now(), s.location)
+
+	invoices, err := s.invoiceRepo.FindUnpaid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unbilled, err := s.paymentRepo.SumUnbilled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	credits, err := s.invoiceRepo.SumCredits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[uint]*dto.ARAgingBuckets)
+	bucketFor := func(accountID uint) *dto.ARAgingBuckets {
+		if buckets[accountID] == nil {
+			buckets[accountID] = &dto.ARAgingBuckets{}
+		}
+		return buckets[accountID]
+	}
+
+	for _, unpaid := range invoices {
+		balance := roundAmount(unpaid.TotalAmount - unpaid.PaidAmount)
+		bucket := bucketFor(unpaid.FleetAccountID)
+		daysPastDue := int(math.Round(today.Sub(startOfDay(unpaid.DueDate, s.location)).Hours() / 24))
+		switch {
+		case daysPastDue <= 0:
+			bucket.Current += balance
+		case daysPastDue <= 30:
+			bucket.Days1To30 += balance
+		case daysPastDue <= 60:
+			bucket.Days31To60 += balance
+		case daysPastDue <= 90:
+			bucket.Days61To90 += balance
+		default:
+			bucket.Over90 += balance
+		}
+		bucket.TotalInvoiced += balance
+	}
+	for accountID, amount := range unbilled {
+		bucketFor(accountID).Unbilled += amount
+	}
+	for accountID, amount := range credits {
+		bucketFor(accountID).Credit += amount
+	}
+
+	accountIDs := make([]uint, 0, len(buckets))
+	for accountID := range buckets {
+		accountIDs = append(accountIDs, accountID)
+	}
+	accounts, err := s.fleetAccountRepo.FindByIDs(ctx, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(accounts))
+	for _, account := range accounts {
+		names[account.ID] = account.Name
+	}
+	sort.Slice(accountIDs, func(i, j int) bool {
+		if names[accountIDs[i]] != names[accountIDs[j]] {
+			return names[accountIDs[i]] < names[accountIDs[j]]
+		}
+		return accountIDs[i] < accountIDs[j]
+	})
+
+	response := &dto.ARAgingResponse{
+		AsOf:     today.Format("2006-01-02"),
+		Accounts: make([]dto.ARAgingLine, 0, len(accountIDs)),
+	}
+	totals := &response.Totals
+	for _, accountID := range accountIDs {
+		bucket := roundAgingBuckets(*buckets[accountID])
+		response.Accounts = append(response.Accounts, dto.ARAgingLine{
+			FleetAccountID:   accountID,
+			FleetAccountName: names[accountID],
+			ARAgingBuckets:   bucket,
+		})
+
+		totals.Current += bucket.Current
+		totals.Days1To30 += bucket.Days1To30
+		totals.Days31To60 += bucket.Days31To60
+		totals.Days61To90 += bucket.Days61To90
+		totals.Over90 += bucket.Over90
+		totals.TotalInvoiced += bucket.TotalInvoiced
+		totals.Unbilled += bucket.Unbilled
+		totals.Credit += bucket.Credit
+	}
+	response.Totals = roundAgingBuckets(response.Totals)
+
+	return response, nil
+}
+
+func roundAgingBuckets(b dto.ARAgingBuckets) dto.ARAgingBuckets {
+	return dto.ARAgingBuckets{
+		Current:       roundAmount(b.Current),
+		Days1To30:     roundAmount(b.Days1To30),
+		Days31To60:    roundAmount(b.Days31To60),
+		Days61To90:    roundAmount(b.Days61To90),
+		Over90:        roundAmount(b.Over90),
+		TotalInvoiced: roundAmount(b.TotalInvoiced),
+		Unbilled:      roundAmount(b.Unbilled),
+		Credit:        roundAmount(b.Credit),
+	}
+}
+
+// invoiceStatus describes how far an invoice has been paid.
+func invoiceStatus(total, paid float64) models.InvoiceStatus {
+	switch {
+	case paid <= 0:
+		return models.InvoiceStatusOpen
+	case paid < roundAmount(total):
+		return models.InvoiceStatusPartiallyPaid
+	default:
+		return models.InvoiceStatusPaid
+	}
+}
+
+// startOfDay returns midnight of the day t falls on in the location.
+func startOfDay(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
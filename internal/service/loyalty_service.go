@@ -0,0 +1,709 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrInsufficientPoints = errors.New("insufficient loyalty points")
+
+type LoyaltyService struct {
+	pointRepo      *repository.LoyaltyPointRepository
+	earnRuleRepo   *repository.LoyaltyEarnRuleRepository
+	rewardRepo     *repository.LoyaltyRewardRepository
+	workOrderRepo  *repository.WorkOrderRepository
+	paymentRepo    *repository.PaymentRepository
+	productRepo    *repository.ProductRepository
+	userRepo       *repository.UserRepository
+	pricingService *PricingService
+	db             *gorm.DB
+	pointsValidity time.Duration
+}
+
+func NewLoyaltyService(
+	pointRepo *repository.LoyaltyPointRepository,
+	earnRuleRepo *repository.LoyaltyEarnRuleRepository,
+	rewardRepo *repository.LoyaltyRewardRepository,
+	workOrderRepo *repository.WorkOrderRepository,
+	paymentRepo *repository.PaymentRepository,
+	productRepo *repository.ProductRepository,
+	userRepo *repository.UserRepository,
+	pricingService *PricingService,
+	db *gorm.DB,
+	pointsExpiryDays int,
+) *LoyaltyService {
+	return &LoyaltyService{
+		pointRepo:      pointRepo,
+		earnRuleRepo:   earnRuleRepo,
+		rewardRepo:     rewardRepo,
+		workOrderRepo:  workOrderRepo,
+		paymentRepo:    paymentRepo,
+		productRepo:    productRepo,
+		userRepo:       userRepo,
+		pricingService: pricingService,
+		db:             db,
+		pointsValidity: time.Duration(pointsExpiryDays) * 24 * time.Hour,
+	}
+}
+
+func (s *LoyaltyService) GetBalance(ctx context.Context, customerID uint) (*dto.LoyaltyBalanceResponse, error) {
+	if _, err := s.userRepo.FindByID(ctx, customerID); err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	var balance int
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.expireDue(ctx, tx, customerID, time.Now()); err != nil {
+			return err
+		}
+		var err error
+		balance, err = s.pointRepo.WithTx(tx).GetBalance(ctx, customerID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.LoyaltyBalanceResponse{
+		CustomerUserID: customerID,
+		Points:         balance,
+	}, nil
+}
+
+func (s *LoyaltyService) GetHistory(ctx context.Context, customerID uint, page, perPage int) ([]dto.LoyaltyPointEntryResponse, *dto.PaginationMeta, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.expireDue(ctx, tx, customerID, time.Now())
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, total, err := s.pointRepo.FindByCustomer(ctx, customerID, page, perPage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]dto.LoyaltyPointEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = *s.toEntryResponse(&entry)
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+
+	meta := &dto.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return responses, meta, nil
+}
+
+// AwardForWorkOrder credits the points a completed work order earns its
+// customer. Orders without a customer earn nothing, and an order is only
+// ever credited once.
+func (s *LoyaltyService) AwardForWorkOrder(ctx context.Context, tx *gorm.DB, workOrderID uint) error {
+	workOrder, err := s.workOrderRepo.WithTx(tx).FindWithItems(ctx, workOrderID)
+	if err != nil {
+		return err
+	}
+	if workOrder.CustomerUserID == nil {
+		return nil
+	}
+
+	pointRepo := s.pointRepo.WithTx(tx)
+	if err := pointRepo.LockCustomer(ctx, *workOrder.CustomerUserID); err != nil {
+		return err
+	}
+
+	// Points taken back when the order was reopened may be earned again
+	earned, err := pointRepo.SumForWorkOrder(ctx, workOrderID, models.LoyaltyEntryEarn, models.LoyaltyEntryEarnReversal)
+	if err != nil {
+		return err
+	}
+	if earned > 0 {
+		return nil
+	}
+
+	points, err := s.calculatePoints(ctx, workOrder)
+	if err != nil || points <= 0 {
+		return err
+	}
+
+	entry := &models.LoyaltyPointEntry{
+		CustomerUserID: *workOrder.CustomerUserID,
+		EntryType:      models.LoyaltyEntryEarn,
+		Points:         points,
+		WorkOrderID:    &workOrderID,
+	}
+	if s.pointsValidity > 0 {
+		expiresAt := time.Now().Add(s.pointsValidity)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	return s.post(ctx, tx, entry, false)
+}
+
+// ReverseForWorkOrder takes back the points a work order earned, e.g. after a
+// refund. When restoreRedeemed is set, points spent on rewards for the order
+// are given back as well, which is what a cancellation needs.
+func (s *LoyaltyService) ReverseForWorkOrder(ctx context.Context, tx *gorm.DB, workOrderID uint, restoreRedeemed bool) error {
+	workOrder, err := s.workOrderRepo.WithTx(tx).FindByID(ctx, workOrderID)
+	if err != nil {
+		return err
+	}
+	if workOrder.CustomerUserID == nil {
+		return nil
+	}
+
+	pointRepo := s.pointRepo.WithTx(tx)
+	if err := pointRepo.LockCustomer(ctx, *workOrder.CustomerUserID); err != nil {
+		return err
+	}
+
+	// Earned points not yet reversed
+	outstanding, err := pointRepo.SumForWorkOrder(ctx, workOrderID, models.LoyaltyEntryEarn, models.LoyaltyEntryEarnReversal)
+	if err != nil {
+		return err
+	}
+	if outstanding > 0 {
+		// Reversals may leave the balance negative when the points were
+		// already spent; the customer earns their way back out of it.
+		if err := s.post(ctx, tx, &models.LoyaltyPointEntry{
+			CustomerUserID: *workOrder.CustomerUserID,
+			EntryType:      models.LoyaltyEntryEarnReversal,
+			Points:         -outstanding,
+			WorkOrderID:    &workOrderID,
+		}, true); err != nil {
+			return err
+		}
+	}
+
+	if !restoreRedeemed {
+		return nil
+	}
+
+	// Redeemed points not yet restored (redemptions are negative)
+	spent, err := pointRepo.SumForWorkOrder(ctx, workOrderID, models.LoyaltyEntryRedeem, models.LoyaltyEntryRedeemReversal)
+	if err != nil {
+		return err
+	}
+	if spent < 0 {
+		return s.post(ctx, tx, &models.LoyaltyPointEntry{
+			CustomerUserID: *workOrder.CustomerUserID,
+			EntryType:      models.LoyaltyEntryRedeemReversal,
+			Points:         -spent,
+			WorkOrderID:    &workOrderID,
+		}, true)
+	}
+	return nil
+}
+
+// ReverseForRefund takes back the share of a work order's earned points that
+// a refund of amount pays back, out of the remaining amount still kept on
+// the order before the refund. Refunding everything that remains takes back
+// all points still held for the order.
+func (s *LoyaltyService) ReverseForRefund(ctx context.Context, tx *gorm.DB, workOrderID uint, amount, remaining float64) error {
+	if amount <= 0 || remaining <= 0 {
+		return nil
+	}
+
+	workOrder, err := s.workOrderRepo.WithTx(tx).FindByID(ctx, workOrderID)
+	if err != nil {
+		return err
+	}
+	if workOrder.CustomerUserID == nil {
+		return nil
+	}
+
+	pointRepo := s.pointRepo.WithTx(tx)
+	if err := pointRepo.LockCustomer(ctx, *workOrder.CustomerUserID); err != nil {
+		return err
+	}
+
+	// Earned points not yet reversed stand for what is still kept
+	outstanding, err := pointRepo.SumForWorkOrder(ctx, workOrderID, models.LoyaltyEntryEarn, models.LoyaltyEntryEarnReversal)
+	if err != nil || outstanding <= 0 {
+		return err
+	}
+
+	points := outstanding
+	if amount < remaining {
+		points = int(math.Round(float64(outstanding) * amount / remaining))
+	}
+	if points <= 0 {
+		return nil
+	}
+
+	return s.post(ctx, tx, &models.LoyaltyPointEntry{
+		CustomerUserID: *workOrder.CustomerUserID,
+		EntryType:      models.LoyaltyEntryEarnReversal,
+		Points:         -points,
+		WorkOrderID:    &workOrderID,
+	}, true)
+}
+
+// Redeem exchanges a customer's points for a reward applied to one of their
+// open work orders. The order is locked like a payment would lock it, and
+// rewards are refused once any payment has been taken or started, since
+// those were made against the order's current total.
+func (s *LoyaltyService) Redeem(ctx context.Context, req dto.RedeemLoyaltyRewardRequest, userID *uint, customerOnly *uint) (*dto.LoyaltyPointEntryResponse, error) {
+	reward, err := s.rewardRepo.FindByID(ctx, req.RewardID)
+	if err != nil || !reward.IsActive {
+		return nil, errors.New("reward not found")
+	}
+
+	var entry *models.LoyaltyPointEntry
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		workOrder, err := s.workOrderRepo.WithTx(tx).FindByIDForUpdate(ctx, req.WorkOrderID)
+		if err != nil {
+			return errors.New("work order not found")
+		}
+		if workOrder.CustomerUserID == nil {
+			return errors.New("work order has no customer")
+		}
+		if customerOnly != nil && *workOrder.CustomerUserID != *customerOnly {
+			return errors.New("work order belongs to another customer")
+		}
+		if workOrder.Status == models.StatusCompleted || workOrder.Status == models.StatusCancelled {
+			return errors.New("rewards can only be applied to open work orders")
+		}
+
+		payments, err := s.paymentRepo.WithTx(tx).FindByWorkOrder(ctx, workOrder.ID)
+		if err != nil {
+			return err
+		}
+		for _, payment := range payments {
+			if payment.Status == models.PaymentStatusPending || payment.Status == models.PaymentStatusCompleted {
+				return errors.New("rewards can only be applied before the work order is paid")
+			}
+		}
+
+		customerID := *workOrder.CustomerUserID
+		if err := s.expireDue(ctx, tx, customerID, time.Now()); err != nil {
+			return err
+		}
+
+		note := reward.Name
+		entry = &models.LoyaltyPointEntry{
+			CustomerUserID:  customerID,
+			EntryType:       models.LoyaltyEntryRedeem,
+			Points:          -reward.PointsCost,
+			WorkOrderID:     &workOrder.ID,
+			RewardID:        &reward.ID,
+			Note:            &note,
+			CreatedByUserID: userID,
+		}
+		if err := s.post(ctx, tx, entry, false); err != nil {
+			return err
+		}
+
+		return s.applyReward(ctx, tx, workOrder, reward)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toEntryResponse(entry), nil
+}
+
+// ExpireAll writes off expired points for every customer that has any and
+// returns how many customers were checked.
+func (s *LoyaltyService) ExpireAll(ctx context.Context, now time.Time) (int, error) {
+	customerIDs, err := s.pointRepo.FindCustomersWithExpiredEarnings(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, customerID := range customerIDs {
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return s.expireDue(ctx, tx, customerID, now)
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(customerIDs), nil
+}
+
+func (s *LoyaltyService) GetEarnRules(ctx context.Context) ([]models.LoyaltyEarnRule, error) {
+	rules, _, err := s.earnRuleRepo.FindAll(ctx, 1, math.MaxInt32)
+	return rules, err
+}
+
+func (s *LoyaltyService) CreateEarnRule(ctx context.Context, req dto.CreateLoyaltyEarnRuleRequest) (*models.LoyaltyEarnRule, error) {
+	rule := &models.LoyaltyEarnRule{
+		CategoryID:       req.CategoryID,
+		MembershipTypeID: req.MembershipTypeID,
+		AmountPerPoint:   req.AmountPerPoint,
+		IsActive:         true,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := s.earnRuleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *LoyaltyService) UpdateEarnRule(ctx context.Context, id uint, req dto.UpdateLoyaltyEarnRuleRequest) (*models.LoyaltyEarnRule, error) {
+	rule, err := s.earnRuleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CategoryID != nil {
+		rule.CategoryID = req.CategoryID
+	}
+	if req.MembershipTypeID != nil {
+		rule.MembershipTypeID = req.MembershipTypeID
+	}
+	if req.AmountPerPoint != nil {
+		rule.AmountPerPoint = *req.AmountPerPoint
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := s.earnRuleRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *LoyaltyService) DeleteEarnRule(ctx context.Context, id uint) error {
+	return s.earnRuleRepo.Delete(ctx, id)
+}
+
+func (s *LoyaltyService) GetRewards(ctx context.Context, activeOnly bool) ([]models.LoyaltyReward, error) {
+	if activeOnly {
+		return s.rewardRepo.FindActive(ctx)
+	}
+	rewards, _, err := s.rewardRepo.FindAll(ctx, 1, math.MaxInt32)
+	return rewards, err
+}
+
+func (s *LoyaltyService) CreateReward(ctx context.Context, req dto.CreateLoyaltyRewardRequest) (*models.LoyaltyReward, error) {
+	reward := &models.LoyaltyReward{
+		Name:           req.Name,
+		PointsCost:     req.PointsCost,
+		RewardType:     models.LoyaltyRewardType(req.RewardType),
+		DiscountAmount: req.DiscountAmount,
+		ProductID:      req.ProductID,
+		IsActive:       true,
+	}
+	if req.IsActive != nil {
+		reward.IsActive = *req.IsActive
+	}
+
+	if err := validateReward(reward); err != nil {
+		return nil, err
+	}
+
+	if err := s.rewardRepo.Create(ctx, reward); err != nil {
+		return nil, err
+	}
+	return reward, nil
+}
+
+func (s *LoyaltyService) UpdateReward(ctx context.Context, id uint, req dto.UpdateLoyaltyRewardRequest) (*models.LoyaltyReward, error) {
+	reward, err := s.rewardRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		reward.Name = *req.Name
+	}
+	if req.PointsCost != nil {
+		reward.PointsCost = *req.PointsCost
+	}
+	if req.RewardType != nil {
+		reward.RewardType = models.LoyaltyRewardType(*req.RewardType)
+	}
+	if req.DiscountAmount != nil {
+		reward.DiscountAmount = *req.DiscountAmount
+	}
+	if req.ProductID != nil {
+		reward.ProductID = req.ProductID
+	}
+	if req.IsActive != nil {
+		reward.IsActive = *req.IsActive
+	}
+
+	if err := validateReward(reward); err != nil {
+		return nil, err
+	}
+
+	if err := s.rewardRepo.Update(ctx, reward); err != nil {
+		return nil, err
+	}
+	return reward, nil
+}
+
+func (s *LoyaltyService) DeleteReward(ctx context.Context, id uint) error {
+	return s.rewardRepo.Delete(ctx, id)
+}
+
+// calculatePoints applies the most specific earn rule to each item of the
+// order and rounds the total down to whole points.
+func (s *LoyaltyService) calculatePoints(ctx context.Context, workOrder *models.WorkOrder) (int, error) {
+	rules, err := s.earnRuleRepo.FindActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	customer, err := s.userRepo.FindByID(ctx, *workOrder.CustomerUserID)
+	if err != nil {
+		return 0, err
+	}
+	var membershipTypeID *uint
+	if customer.MembershipTypeID != nil &&
+		(customer.MembershipExpiresAt == nil || customer.MembershipExpiresAt.After(time.Now())) {
+		membershipTypeID = customer.MembershipTypeID
+	}
+
+	var points float64
+	for _, item := range workOrder.Items {
+		rule := selectEarnRule(rules, item.Product.CategoryID, membershipTypeID)
+		if rule == nil {
+			continue
+		}
+		points += item.Subtotal / rule.AmountPerPoint
+	}
+
+	return int(math.Floor(points)), nil
+}
+
+// expireDue writes off earned points that passed their expiry date without
+// being spent. Each earning is a lot: reversing an order's points takes them
+// from that order's own lot, while redemptions and earlier expiries consume
+// the oldest lots first. What remains of the expired lots is removed.
+func (s *LoyaltyService) expireDue(ctx context.Context, tx *gorm.DB, customerID uint, now time.Time) error {
+	pointRepo := s.pointRepo.WithTx(tx)
+	if err := pointRepo.LockCustomer(ctx, customerID); err != nil {
+		return err
+	}
+
+	entries, err := pointRepo.FindEarnings(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	type lot struct {
+		remaining int
+		expired   bool
+	}
+	var lots []*lot
+	lotsByOrder := make(map[uint][]*lot)
+	anyExpired := false
+	for _, entry := range entries {
+		if entry.EntryType == models.LoyaltyEntryEarn {
+			l := &lot{
+				remaining: entry.Points,
+				expired:   entry.ExpiresAt != nil && !entry.ExpiresAt.After(now),
+			}
+			anyExpired = anyExpired || l.expired
+			lots = append(lots, l)
+			if entry.WorkOrderID != nil {
+				lotsByOrder[*entry.WorkOrderID] = append(lotsByOrder[*entry.WorkOrderID], l)
+			}
+			continue
+		}
+
+		// Reversals are negative and come after the earning they reverse
+		if entry.WorkOrderID == nil {
+			continue
+		}
+		reversed := -entry.Points
+		for _, l := range lotsByOrder[*entry.WorkOrderID] {
+			take := min(reversed, l.remaining)
+			l.remaining -= take
+			reversed -= take
+		}
+	}
+	if !anyExpired {
+		return nil
+	}
+
+	// Spending and earlier expiries, as a positive number of points
+	consumed, err := pointRepo.SumByTypes(ctx, customerID,
+		models.LoyaltyEntryRedeem, models.LoyaltyEntryRedeemReversal, models.LoyaltyEntryExpiry)
+	if err != nil {
+		return err
+	}
+	consumed = -consumed
+
+	due := 0
+	for _, l := range lots {
+		take := max(min(consumed, l.remaining), 0)
+		l.remaining -= take
+		consumed -= take
+		if l.expired {
+			due += l.remaining
+		}
+	}
+	if due <= 0 {
+		return nil
+	}
+
+	balance, err := pointRepo.GetBalance(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if due > balance {
+		due = balance
+	}
+	if due <= 0 {
+		return nil
+	}
+
+	return s.post(ctx, tx, &models.LoyaltyPointEntry{
+		CustomerUserID: customerID,
+		EntryType:      models.LoyaltyEntryExpiry,
+		Points:         -due,
+	}, false)
+}
+
+// applyReward puts a redeemed reward on the work order: a discount lowers
+// the taxable amount and the order is re-taxed, a free product is added as
+// a zero-priced item. The deposit is item-based and only capped at the new
+// total, as it is when the order is created.
+func (s *LoyaltyService) applyReward(ctx context.Context, tx *gorm.DB, workOrder *models.WorkOrder, reward *models.LoyaltyReward) error {
+	switch reward.RewardType {
+	case models.RewardTypeDiscount:
+		discount := math.Min(reward.DiscountAmount, workOrder.Subtotal-workOrder.DiscountAmount)
+		workOrder.DiscountAmount = roundAmount(workOrder.DiscountAmount + discount)
+		taxable := workOrder.Subtotal - workOrder.DiscountAmount
+		workOrder.TaxAmount = s.pricingService.TaxOn(taxable)
+		workOrder.TotalAmount = roundAmount(taxable + workOrder.TaxAmount)
+		if workOrder.DepositRequired > workOrder.TotalAmount {
+			workOrder.DepositRequired = workOrder.TotalAmount
+		}
+		return tx.Model(workOrder).Updates(map[string]interface{}{
+			"discount_amount":  workOrder.DiscountAmount,
+			"tax_amount":       workOrder.TaxAmount,
+			"total_amount":     workOrder.TotalAmount,
+			"deposit_required": workOrder.DepositRequired,
+		}).Error
+
+	case models.RewardTypeFreeProduct:
+		product, err := s.productRepo.FindByID(ctx, *reward.ProductID)
+		if err != nil {
+			return errors.New("reward product not found")
+		}
+		priced, err := s.pricingService.PriceProduct(ctx, product, time.Now())
+		if err != nil {
+			return err
+		}
+		note := fmt.Sprintf("Loyalty reward: %s", reward.Name)
+		item := &models.WorkOrderItem{
+			WorkOrderID:         workOrder.ID,
+			ProductID:           product.ID,
+			ProductNameSnapshot: product.Name,
+			BasePriceSnapshot:   priced.BasePrice,
+			PriceSnapshot:       0,
+			Quantity:            1,
+			Subtotal:            0,
+			ItemNote:            &note,
+		}
+		return tx.Create(item).Error
+	}
+
+	return errors.New("unknown reward type")
+}
+
+// post appends an entry to the customer's points ledger. Unless
+// allowNegative is set, entries that would overdraw the balance are refused.
+func (s *LoyaltyService) post(ctx context.Context, tx *gorm.DB, entry *models.LoyaltyPointEntry, allowNegative bool) error {
+	pointRepo := s.pointRepo.WithTx(tx)
+	if err := pointRepo.LockCustomer(ctx, entry.CustomerUserID); err != nil {
+		return err
+	}
+
+	balance, err := pointRepo.GetBalance(ctx, entry.CustomerUserID)
+	if err != nil {
+		return err
+	}
+
+	entry.BalanceAfter = balance + entry.Points
+	if entry.BalanceAfter < 0 && !allowNegative {
+		return ErrInsufficientPoints
+	}
+
+	return pointRepo.Append(ctx, entry)
+}
+
+func (s *LoyaltyService) toEntryResponse(entry *models.LoyaltyPointEntry) *dto.LoyaltyPointEntryResponse {
+	return &dto.LoyaltyPointEntryResponse{
+		ID:              entry.ID,
+		CustomerUserID:  entry.CustomerUserID,
+		EntryType:       string(entry.EntryType),
+		Points:          entry.Points,
+		BalanceAfter:    entry.BalanceAfter,
+		WorkOrderID:     entry.WorkOrderID,
+		RewardID:        entry.RewardID,
+		ExpiresAt:       entry.ExpiresAt,
+		Note:            entry.Note,
+		CreatedByUserID: entry.CreatedByUserID,
+		CreatedAt:       entry.CreatedAt,
+	}
+}
+
+// selectEarnRule returns the most specific rule for an item: category and
+// tier, then category only, then tier only, then the default rule.
+func selectEarnRule(rules []models.LoyaltyEarnRule, categoryID uint, membershipTypeID *uint) *models.LoyaltyEarnRule {
+	var best *models.LoyaltyEarnRule
+	bestScore := -1
+	for i := range rules {
+		rule := &rules[i]
+		score := 0
+		if rule.CategoryID != nil {
+			if *rule.CategoryID != categoryID {
+				continue
+			}
+			score += 2
+		}
+		if rule.MembershipTypeID != nil {
+			if membershipTypeID == nil || *rule.MembershipTypeID != *membershipTypeID {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best = rule
+			bestScore = score
+		}
+	}
+	return best
+}
+
+func validateReward(reward *models.LoyaltyReward) error {
+	switch reward.RewardType {
+	case models.RewardTypeDiscount:
+		if reward.DiscountAmount <= 0 {
+			return errors.New("discount rewards need a discount_amount")
+		}
+	case models.RewardTypeFreeProduct:
+		if reward.ProductID == nil {
+			return errors.New("free product rewards need a product_id")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+)
+
+var paymentMethodCode = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// defaultPaymentMethods are set up for an outlet that has none of them yet,
+// matching how payments worked before methods were configurable.
+var defaultPaymentMethods = []models.PaymentMethodSetting{
+	{Code: models.MethodCash, DisplayName: "Cash", AllowsChange: true, SortOrder: 1},
+	{Code: models.MethodQRIS, DisplayName: "QRIS", PendingTTLMinutes: intPtr(30), SortOrder: 2},
+	{Code: models.MethodTransfer, DisplayName: "Bank Transfer", PendingTTLMinutes: intPtr(24 * 60), SortOrder: 3},
+	{Code: models.MethodEWallet, DisplayName: "E-Wallet", SortOrder: 4},
+	{Code: models.MethodWallet, DisplayName: "Wallet", SortOrder: 5},
+	{Code: models.MethodAccount, DisplayName: "On Account", SortOrder: 6},
+}
+
+type PaymentMethodService struct {
+	paymentMethodRepo *repository.PaymentMethodRepository
+	paymentRepo       *repository.PaymentRepository
+	refundRepo        *repository.RefundRepository
+	outletCode        string
+	location          *time.Location
+}
+
+func NewPaymentMethodService(
+	paymentMethodRepo *repository.PaymentMethodRepository,
+	paymentRepo *repository.PaymentRepository,
+	refundRepo *repository.RefundRepository,
+	outletCode string,
+	location *time.Location,
+) *PaymentMethodService {
+	return &PaymentMethodService{
+		paymentMethodRepo: paymentMethodRepo,
+		paymentRepo:       paymentRepo,
+		refundRepo:        refundRepo,
+		outletCode:        outletCode,
+		location:          location,
+	}
+}
+
+// EnsureDefaults adds the default payment methods the outlet does not have
+// yet. Methods already configured are left as they are.
+func (s *PaymentMethodService) EnsureDefaults(ctx context.Context) error {
+	for _, method := range defaultPaymentMethods {
+		existing, err := s.paymentMethodRepo.FindByCode(ctx, s.outletCode, method.Code)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+
+		method.OutletCode = s.outletCode
+		method.IsEnabled = true
+		if err := s.paymentMethodRepo.Create(ctx, &method); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAvailable returns the methods the outlet currently accepts.
+func (s *PaymentMethodService) GetAvailable(ctx context.Context) ([]models.PaymentMethodSetting, error) {
+	return s.paymentMethodRepo.FindByOutlet(ctx, s.outletCode, true)
+}
+
+func (s *PaymentMethodService) GetAll(ctx context.Context) ([]models.PaymentMethodSetting, error) {
+	return s.paymentMethodRepo.FindByOutlet(ctx, s.outletCode, false)
+}
+
+func (s *PaymentMethodService) Create(ctx context.Context, req dto.CreatePaymentMethodRequest) (*models.PaymentMethodSetting, error) {
+	if !paymentMethodCode.MatchString(req.Code) {
+		return nil, errors.New("code must be lowercase letters, digits and underscores, starting with a letter")
+	}
+
+	existing, err := s.paymentMethodRepo.FindByCode(ctx, s.outletCode, models.PaymentMethod(req.Code))
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("payment method %q already exists", req.Code)
+	}
+
+	method := &models.PaymentMethodSetting{
+		OutletCode:        s.outletCode,
+		Code:              models.PaymentMethod(req.Code),
+		DisplayName:       req.DisplayName,
+		IsEnabled:         true,
+		RequiresReference: req.RequiresReference,
+		AllowsChange:      req.AllowsChange,
+		FeePercentage:     req.FeePercentage,
+		FeeFixed:          roundAmount(req.FeeFixed),
+		PendingTTLMinutes: req.PendingTTLMinutes,
+		SortOrder:         req.SortOrder,
+	}
+	if req.IsEnabled != nil {
+		method.IsEnabled = *req.IsEnabled
+	}
+
+	if err := s.paymentMethodRepo.Create(ctx, method); err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
+
+func (s *PaymentMethodService) Update(ctx context.Context, id uint, req dto.UpdatePaymentMethodRequest) (*models.PaymentMethodSetting, error) {
+	method, err := s.paymentMethodRepo.FindByID(ctx, id)
+	if err != nil || method.OutletCode != s.outletCode {
+		return nil, errors.New("payment method not found")
+	}
+
+	if req.DisplayName != nil {
+		method.DisplayName = *req.DisplayName
+	}
+	if req.IsEnabled != nil {
+		method.IsEnabled = *req.IsEnabled
+	}
+	if req.RequiresReference != nil {
+		method.RequiresReference = *req.RequiresReference
+	}
+	if req.AllowsChange != nil {
+		method.AllowsChange = *req.AllowsChange
+	}
+	if req.FeePercentage != nil {
+		method.FeePercentage = *req.FeePercentage
+	}
+	if req.FeeFixed != nil {
+		method.FeeFixed = roundAmount(*req.FeeFixed)
+	}
+	if req.PendingTTLMinutes != nil {
+		// Zero goes back to the server's default
+		method.PendingTTLMinutes = req.PendingTTLMinutes
+		if *req.PendingTTLMinutes == 0 {
+			method.PendingTTLMinutes = nil
+		}
+	}
+	if req.SortOrder != nil {
+		method.SortOrder = *req.SortOrder
+	}
+
+	if err := s.paymentMethodRepo.Update(ctx, method); err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
+
+// Resolve returns the enabled method a payment is taken with, checking that
+// it carries a reference number when the method needs one.
+func (s *PaymentMethodService) Resolve(ctx context.Context, code string, referenceNumber *string) (*models.PaymentMethodSetting, error) {
+	method, err := s.paymentMethodRepo.FindByCode(ctx, s.outletCode, models.PaymentMethod(code))
+	if err != nil {
+		return nil, err
+	}
+	if method == nil || !method.IsEnabled {
+		return nil, fmt.Errorf("payment method %q is not available", code)
+	}
+	if method.RequiresReference && (referenceNumber == nil || *referenceNumber == "") {
+		return nil, fmt.Errorf("%s payments require a reference number", method.DisplayName)
+	}
+	return method, nil
+}
+
+// Find returns a method the outlet has, whether or not it is enabled, such
+// as the method a refund is given back with.
+func (s *PaymentMethodService) Find(ctx context.Context, code string) (*models.PaymentMethodSetting, error) {
+	method, err := s.paymentMethodRepo.FindByCode(ctx, s.outletCode, models.PaymentMethod(code))
+	if err != nil {
+		return nil, err
+	}
+	if method == nil {
+		return nil, fmt.Errorf("unknown payment method %q", code)
+	}
+	return method, nil
+}
+
+// GetRevenueReport totals the completed payments of each method paid between
+// two dates, inclusive, in the outlet's time zone. Net revenue is what was
+// paid less change, method fees and refunds; tips are reported apart.
+func (s *PaymentMethodService) GetRevenueReport(ctx context.Context, fromDate, toDate string) (*dto.PaymentMethodRevenueResponse, error) {
+	today := time.Now().In(s.location).Format("2006-01-02")
+	if fromDate == "" {
+		fromDate = today
+	}
+	if toDate == "" {
+		toDate = fromDate
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", fromDate, s.location)
+	if err != nil {
+		return nil, errors.New("from must be a date in YYYY-MM-DD format")
+	}
+	to, err := time.ParseInLocation("2006-01-02", toDate, s.location)
+	if err != nil {
+		return nil, errors.New("to must be a date in YYYY-MM-DD format")
+	}
+	if to.Before(from) {
+		return nil, errors.New("to must not be before from")
+	}
+	end := to.AddDate(0, 0, 1)
+
+	payments, err := s.paymentRepo.SumByMethod(ctx, from, end)
+	if err != nil {
+		return nil, err
+	}
+	refunds, err := s.refundRepo.SumByMethod(ctx, from, end)
+	if err != nil {
+		return nil, err
+	}
+	methods, err := s.paymentMethodRepo.FindByOutlet(ctx, s.outletCode, false)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[models.PaymentMethod]*dto.PaymentMethodRevenueLine)
+	var order []models.PaymentMethod
+	lineFor := func(method models.PaymentMethod) *dto.PaymentMethodRevenueLine {
+		if line, ok := lines[method]; ok {
+			return line
+		}
+		line := &dto.PaymentMethodRevenueLine{Method: string(method), DisplayName: string(method)}
+		lines[method] = line
+		order = append(order, method)
+		return line
+	}
+
+	// Configured methods come first in their display order, even without
+	// payments, followed by any others that were paid with
+	for _, method := range methods {
+		lineFor(method.Code).DisplayName = method.DisplayName
+	}
+	for _, total := range payments {
+		line := lineFor(total.Method)
+		line.PaymentCount = total.Count
+		line.GrossAmount = total.Gross
+		line.TipAmount = total.Tip
+		line.FeeAmount = total.Fee
+	}
+	for method, amount := range refunds {
+		lineFor(method).RefundAmount = amount
+	}
+
+	report := &dto.PaymentMethodRevenueResponse{
+		From:    fromDate,
+		To:      toDate,
+		Methods: make([]dto.PaymentMethodRevenueLine, 0, len(order)),
+	}
+	for _, method := range order {
+		line := lines[method]
+		line.GrossAmount = roundAmount(line.GrossAmount)
+		line.TipAmount = roundAmount(line.TipAmount)
+		line.FeeAmount = roundAmount(line.FeeAmount)
+		line.RefundAmount = roundAmount(line.RefundAmount)
+		line.NetAmount = roundAmount(line.GrossAmount - line.FeeAmount - line.RefundAmount)
+
+		report.GrossAmount += line.GrossAmount
+		report.TipAmount += line.TipAmount
+		report.FeeAmount += line.FeeAmount
+		report.RefundAmount += line.RefundAmount
+		report.NetAmount += line.NetAmount
+		report.Methods = append(report.Methods, *line)
+	}
+	report.GrossAmount = roundAmount(report.GrossAmount)
+	report.TipAmount = roundAmount(report.TipAmount)
+	report.FeeAmount = roundAmount(report.FeeAmount)
+	report.RefundAmount = roundAmount(report.RefundAmount)
+	report.NetAmount = roundAmount(report.NetAmount)
+
+	return report, nil
+}
+
+// applyPaymentFee sets the fee a method charges on a payment and the net
+// amount left of it. The fee is charged on everything that went through the
+// method, tip included, and comes out of the business's share.
+func applyPaymentFee(payment *models.Payment, method *models.PaymentMethodSetting) {
+	charged := roundAmount(payment.AmountPaid - payment.ChangeAmount + payment.TipAmount)
+	if charged > 0 {
+		payment.FeeAmount = roundAmount(charged*method.FeePercentage/100 + method.FeeFixed)
+	}
+	payment.NetAmount = roundAmount(payment.AmountPaid - payment.ChangeAmount - payment.FeeAmount)
+}
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -2,93 +2,455 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"log"
+	"math"
 	"time"
 
 	"flashlight-go/internal/dto"
 	"flashlight-go/internal/models"
 	"flashlight-go/internal/repository"
+	"flashlight-go/pkg/utils"
+
+	"github.com/skip2/go-qrcode"
 
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
+// ErrNoActiveShift is returned when a payment is taken by someone without
+// an open shift to account it to.
+var ErrNoActiveShift = errors.New("no active shift, open a shift before taking payments")
+
+// qrImageSize is the width and height in pixels of rendered QR codes.
+const qrImageSize = 512
+
 type PaymentService struct {
-	paymentRepo   *repository.PaymentRepository
-	workOrderRepo *repository.WorkOrderRepository
+	paymentRepo     *repository.PaymentRepository
+	workOrderRepo   *repository.WorkOrderRepository
+	shiftRepo       *repository.ShiftRepository
+	walletService   *WalletService
+	loyaltyService  *LoyaltyService
+	tipService      *TipService
+	receiptDelivery *ReceiptDeliveryService
+	paymentMethods  *PaymentMethodService
+	fleetAccounts   *FleetAccountService
+	qrisMerchant    utils.QRISMerchant
+	db              *gorm.DB
 }
 
 func NewPaymentService(
 	paymentRepo *repository.PaymentRepository,
 	workOrderRepo *repository.WorkOrderRepository,
+	shiftRepo *repository.ShiftRepository,
+	walletService *WalletService,
+	loyaltyService *LoyaltyService,
+	tipService *TipService,
+	receiptDelivery *ReceiptDeliveryService,
+	paymentMethods *PaymentMethodService,
+	fleetAccounts *FleetAccountService,
+	qrisMerchant utils.QRISMerchant,
+	db *gorm.DB,
 ) *PaymentService {
 	return &PaymentService{
-		paymentRepo:   paymentRepo,
-		workOrderRepo: workOrderRepo,
+		paymentRepo:     paymentRepo,
+		workOrderRepo:   workOrderRepo,
+		shiftRepo:       shiftRepo,
+		walletService:   walletService,
+		loyaltyService:  loyaltyService,
+		tipService:      tipService,
+		receiptDelivery: receiptDelivery,
+		paymentMethods:  paymentMethods,
+		fleetAccounts:   fleetAccounts,
+		qrisMerchant:    qrisMerchant,
+		db:              db,
 	}
 }
 
-func (s *PaymentService) Create(ctx context.Context, req dto.CreatePaymentRequest, cashierUserID, shiftID *uint) (*models.Payment, error) {
-	// Verify work order exists
-	workOrder, err := s.workOrderRepo.FindByID(ctx, req.WorkOrderID)
+func (s *PaymentService) Create(ctx context.Context, req dto.CreatePaymentRequest, cashierUserID *uint) (*models.Payment, error) {
+	method, err := s.paymentMethods.Resolve(ctx, req.Method, req.ReferenceNumber)
 	if err != nil {
-		return nil, errors.New("work order not found")
+		return nil, err
 	}
 
-	// Generate payment number
-	paymentNumber, err := s.paymentRepo.GeneratePaymentNumber(ctx)
+	var payment *models.Payment
+	var completed bool
+
+	// The work order is locked for the whole operation, so concurrent
+	// payments see each other's totals and only one of them completes it
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Every payment is accounted to the cashier's open shift
+		shift, err := s.activeShift(ctx, tx, cashierUserID)
+		if err != nil {
+			return err
+		}
+
+		workOrder, err := s.workOrderRepo.WithTx(tx).FindByIDForUpdate(ctx, req.WorkOrderID)
+		if err != nil {
+			return errors.New("work order not found")
+		}
+		if workOrder.Status == models.StatusCancelled {
+			return errors.New("cannot take payment for a cancelled work order")
+		}
+
+		if method.Code == models.MethodWallet && workOrder.CustomerUserID == nil {
+			return errors.New("wallet payments require a work order with a customer")
+		}
+
+		// Payments only go towards what is still owed, and not while a QRIS
+		// code for it may still be paid
+		paymentRepo := s.paymentRepo.WithTx(tx)
+		if err := refusePending(ctx, paymentRepo, workOrder.ID); err != nil {
+			return err
+		}
+		netPaid, err := paymentRepo.GetNetPaidForWorkOrder(ctx, workOrder.ID)
+		if err != nil {
+			return err
+		}
+		balance := roundAmount(workOrder.TotalAmount - netPaid)
+		if balance <= 0 {
+			return errors.New("work order is already fully paid")
+		}
+
+		// The first payment must at least cover the deposit, unless it settles
+		// the whole balance anyway
+		if netPaid <= 0 && workOrder.DepositRequired > 0 && req.AmountPaid < math.Min(workOrder.DepositRequired, balance) {
+			return fmt.Errorf("a deposit of at least %.2f is required", workOrder.DepositRequired)
+		}
+
+		// Calculate change against the remaining balance; only methods that
+		// give change can overpay
+		var changeAmount float64
+		if req.AmountPaid > balance {
+			if !method.AllowsChange {
+				return fmt.Errorf("%s payments may not exceed the balance of %.2f", method.DisplayName, balance)
+			}
+			changeAmount = roundAmount(req.AmountPaid - balance)
+		}
+
+		// Generate payment number
+		paymentNumber, err := paymentRepo.GeneratePaymentNumber(ctx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		payment = &models.Payment{
+			WorkOrderID:     workOrder.ID,
+			CashierUserID:   cashierUserID,
+			ShiftID:         &shift.ID,
+			PaymentNumber:   paymentNumber,
+			Method:          method.Code,
+			Status:          models.PaymentStatusCompleted,
+			AmountPaid:      req.AmountPaid,
+			ChangeAmount:    changeAmount,
+			TipAmount:       roundAmount(req.TipAmount),
+			ReferenceNumber: req.ReferenceNumber,
+			PaidAt:          &now,
+		}
+		applyPaymentFee(payment, method)
+
+		// On-account payments are charged to the vehicle's fleet account
+		// and billed on its next invoice
+		if method.Code == models.MethodAccount {
+			account, err := s.fleetAccounts.AccountForCharge(ctx, tx, workOrder, payment.AmountPaid-payment.ChangeAmount+payment.TipAmount)
+			if err != nil {
+				return err
+			}
+			payment.FleetAccountID = &account.ID
+		}
+
+		// Convert raw payload to JSON
+		if req.RawPayload != nil {
+			jsonData, _ := datatypes.NewJSONType(req.RawPayload).MarshalJSON()
+			payment.RawPayload = jsonData
+		}
+
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+
+		// Wallet payments draw from the customer's balance in the same
+		// transaction, tip included
+		if payment.Method == models.MethodWallet {
+			if err := s.walletService.DebitForPayment(ctx, tx, *workOrder.CustomerUserID, payment.AmountPaid+payment.TipAmount, payment.ID, cashierUserID); err != nil {
+				return err
+			}
+		}
+
+		if err := s.tipService.Distribute(ctx, tx, payment); err != nil {
+			return err
+		}
+
+		completed, err = s.completeInTx(ctx, tx, workOrder)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate change for cash payments
-	var changeAmount float64
-	if req.Method == string(models.MethodCash) && req.AmountPaid > workOrder.TotalAmount {
-		changeAmount = req.AmountPaid - workOrder.TotalAmount
+	if completed {
+		s.receiptDelivery.SendForCompletedOrder(payment.WorkOrderID)
 	}
 
-	// Create payment
-	payment := &models.Payment{
-		WorkOrderID:     req.WorkOrderID,
-		CashierUserID:   cashierUserID,
-		ShiftID:         shiftID,
-		PaymentNumber:   paymentNumber,
-		Method:          models.PaymentMethod(req.Method),
-		Status:          models.PaymentStatusCompleted,
-		AmountPaid:      req.AmountPaid,
-		ChangeAmount:    changeAmount,
-		ReferenceNumber: req.ReferenceNumber,
-		PaidAt:          &time.Time{},
+	return payment, nil
+}
+
+// CheckoutResult is the outcome of a split-tender checkout.
+type CheckoutResult struct {
+	WorkOrderID     uint             `json:"work_order_id"`
+	Balance         float64          `json:"balance"`
+	TotalTendered   float64          `json:"total_tendered"`
+	ChangeAmount    float64          `json:"change_amount"`
+	WorkOrderStatus string           `json:"work_order_status"`
+	Payments        []models.Payment `json:"payments"`
+}
+
+// Checkout settles the outstanding balance of a work order with one or more
+// tenders, e.g. part cash and part QRIS. Together the tenders must cover
+// the balance and only methods that give change may exceed it. Every payment
+// and the completion of the order are written in one transaction, so a
+// failing tender leaves nothing behind.
+func (s *PaymentService) Checkout(ctx context.Context, req dto.CheckoutRequest, cashierUserID *uint) (*CheckoutResult, error) {
+	methods := make([]*models.PaymentMethodSetting, len(req.Tenders))
+	for i, tender := range req.Tenders {
+		method, err := s.paymentMethods.Resolve(ctx, tender.Method, tender.ReferenceNumber)
+		if err != nil {
+			return nil, err
+		}
+		methods[i] = method
 	}
 
-	// Set paid at to now
-	now := time.Now()
-	payment.PaidAt = &now
+	result := &CheckoutResult{WorkOrderID: req.WorkOrderID}
+	var completed bool
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		shift, err := s.activeShift(ctx, tx, cashierUserID)
+		if err != nil {
+			return err
+		}
+
+		workOrder, err := s.workOrderRepo.WithTx(tx).FindByIDForUpdate(ctx, req.WorkOrderID)
+		if err != nil {
+			return errors.New("work order not found")
+		}
+		if workOrder.Status == models.StatusCancelled {
+			return errors.New("cannot take payment for a cancelled work order")
+		}
+
+		paymentRepo := s.paymentRepo.WithTx(tx)
+		if err := refusePending(ctx, paymentRepo, workOrder.ID); err != nil {
+			return err
+		}
+		netPaid, err := paymentRepo.GetNetPaidForWorkOrder(ctx, workOrder.ID)
+		if err != nil {
+			return err
+		}
+		balance := roundAmount(workOrder.TotalAmount - netPaid)
+		if balance <= 0 {
+			return errors.New("work order is already fully paid")
+		}
 
-	// Convert raw payload to JSON
-	if req.RawPayload != nil {
-		jsonData, _ := datatypes.NewJSONType(req.RawPayload).MarshalJSON()
-		payment.RawPayload = jsonData
+		changes, err := allocateChange(req.Tenders, methods, balance)
+		if err != nil {
+			return err
+		}
+
+		result.Balance = balance
+		now := time.Now()
+		for i, tender := range req.Tenders {
+			method := methods[i]
+			if method.Code == models.MethodWallet && workOrder.CustomerUserID == nil {
+				return errors.New("wallet payments require a work order with a customer")
+			}
+
+			paymentNumber, err := paymentRepo.GeneratePaymentNumber(ctx)
+			if err != nil {
+				return err
+			}
+
+			payment := models.Payment{
+				WorkOrderID:     workOrder.ID,
+				CashierUserID:   cashierUserID,
+				ShiftID:         &shift.ID,
+				PaymentNumber:   paymentNumber,
+				Method:          method.Code,
+				Status:          models.PaymentStatusCompleted,
+				AmountPaid:      roundAmount(tender.Amount),
+				ChangeAmount:    changes[i],
+				TipAmount:       roundAmount(tender.TipAmount),
+				ReferenceNumber: tender.ReferenceNumber,
+				PaidAt:          &now,
+			}
+			applyPaymentFee(&payment, method)
+			if method.Code == models.MethodAccount {
+				account, err := s.fleetAccounts.AccountForCharge(ctx, tx, workOrder, payment.AmountPaid-payment.ChangeAmount+payment.TipAmount)
+				if err != nil {
+					return err
+				}
+				payment.FleetAccountID = &account.ID
+			}
+			if tender.RawPayload != nil {
+				jsonData, _ := datatypes.NewJSONType(tender.RawPayload).MarshalJSON()
+				payment.RawPayload = jsonData
+			}
+
+			if err := tx.Create(&payment).Error; err != nil {
+				return err
+			}
+			if method.Code == models.MethodWallet {
+				if err := s.walletService.DebitForPayment(ctx, tx, *workOrder.CustomerUserID, payment.AmountPaid+payment.TipAmount, payment.ID, cashierUserID); err != nil {
+					return err
+				}
+			}
+			if err := s.tipService.Distribute(ctx, tx, &payment); err != nil {
+				return err
+			}
+
+			result.TotalTendered += payment.AmountPaid
+			result.ChangeAmount += payment.ChangeAmount
+			result.Payments = append(result.Payments, payment)
+		}
+		result.TotalTendered = roundAmount(result.TotalTendered)
+		result.ChangeAmount = roundAmount(result.ChangeAmount)
+
+		completed, err = s.completeInTx(ctx, tx, workOrder)
+		if err != nil {
+			return err
+		}
+		result.WorkOrderStatus = string(workOrder.Status)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+	if completed {
+		s.receiptDelivery.SendForCompletedOrder(req.WorkOrderID)
+	}
+
+	return result, nil
+}
+
+// CreateQRIS issues a dynamic QRIS code for the outstanding amount of a work
+// order, plus an optional tip, and records it as a pending payment that
+// completes once the acquirer confirms it.
+func (s *PaymentService) CreateQRIS(ctx context.Context, req dto.CreateQRISPaymentRequest, cashierUserID *uint) (*dto.QRISPaymentResponse, error) {
+	if !s.qrisMerchant.Configured() {
+		return nil, errors.New("QRIS is not configured for this outlet")
+	}
+
+	// The acquirer's reference only arrives with its confirmation, so it is
+	// not asked for here
+	method, err := s.paymentMethods.Find(ctx, string(models.MethodQRIS))
+	if err != nil {
 		return nil, err
 	}
+	if !method.IsEnabled {
+		return nil, fmt.Errorf("payment method %q is not available", method.Code)
+	}
 
-	// Check if work order is fully paid
-	totalPaid, err := s.paymentRepo.GetTotalPaidForWorkOrder(ctx, req.WorkOrderID)
-	if err == nil && totalPaid >= workOrder.TotalAmount {
-		// Update work order status to completed
-		workOrder.Status = models.StatusCompleted
-		now := time.Now()
-		workOrder.CompletedAt = &now
-		_ = s.workOrderRepo.Update(ctx, workOrder)
+	// The code is issued under the work order lock, so it cannot be issued
+	// twice for the same balance or race a payment settling it
+	var payment *models.Payment
+	var payload string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		shift, err := s.activeShift(ctx, tx, cashierUserID)
+		if err != nil {
+			return err
+		}
+
+		workOrder, err := s.workOrderRepo.WithTx(tx).FindByIDForUpdate(ctx, req.WorkOrderID)
+		if err != nil {
+			return errors.New("work order not found")
+		}
+		if workOrder.Status == models.StatusCancelled {
+			return errors.New("cannot take payment for a cancelled work order")
+		}
+
+		paymentRepo := s.paymentRepo.WithTx(tx)
+		if err := refusePending(ctx, paymentRepo, workOrder.ID); err != nil {
+			return err
+		}
+
+		netPaid, err := paymentRepo.GetNetPaidForWorkOrder(ctx, workOrder.ID)
+		if err != nil {
+			return err
+		}
+		outstanding := roundAmount(workOrder.TotalAmount - netPaid)
+		if outstanding <= 0 {
+			return errors.New("work order is already fully paid")
+		}
+
+		paymentNumber, err := paymentRepo.GeneratePaymentNumber(ctx)
+		if err != nil {
+			return err
+		}
+
+		tip := roundAmount(req.TipAmount)
+		payload, err = utils.BuildQRISPayload(s.qrisMerchant, roundAmount(outstanding+tip), paymentNumber)
+		if err != nil {
+			return err
+		}
+
+		payment = &models.Payment{
+			WorkOrderID:   workOrder.ID,
+			CashierUserID: cashierUserID,
+			ShiftID:       &shift.ID,
+			PaymentNumber: paymentNumber,
+			Method:        models.MethodQRIS,
+			Status:        models.PaymentStatusPending,
+			AmountPaid:    outstanding,
+			TipAmount:     tip,
+			QRPayload:     &payload,
+		}
+		applyPaymentFee(payment, method)
+
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		return s.tipService.Distribute(ctx, tx, payment)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return payment, nil
+	image, err := qrcode.Encode(payload, qrcode.Medium, qrImageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.QRISPaymentResponse{
+		PaymentID:     payment.ID,
+		PaymentNumber: payment.PaymentNumber,
+		WorkOrderID:   payment.WorkOrderID,
+		ShiftID:       payment.ShiftID,
+		Status:        string(payment.Status),
+		AmountPaid:    payment.AmountPaid,
+		TipAmount:     payment.TipAmount,
+		Amount:        roundAmount(payment.AmountPaid + payment.TipAmount),
+		QRString:      payload,
+		QRImage:       "data:image/png;base64," + base64.StdEncoding.EncodeToString(image),
+		CreatedAt:     payment.CreatedAt,
+	}, nil
+}
+
+// GetQRISImage renders the QR code of a QRIS payment as a PNG.
+func (s *PaymentService) GetQRISImage(ctx context.Context, id uint) ([]byte, error) {
+	payment, err := s.paymentRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.QRPayload == nil {
+		return nil, errors.New("payment has no QRIS code")
+	}
+	return qrcode.Encode(*payment.QRPayload, qrcode.Medium, qrImageSize)
 }
 
 func (s *PaymentService) GetByID(ctx context.Context, id uint) (*models.Payment, error) {
-	return s.paymentRepo.FindByID(ctx, id)
+	return s.paymentRepo.FindWithDetails(ctx, id)
 }
 
 func (s *PaymentService) GetAll(ctx context.Context, page, perPage int) ([]models.Payment, *dto.PaginationMeta, error) {
@@ -113,33 +475,358 @@ func (s *PaymentService) GetAll(ctx context.Context, page, perPage int) ([]model
 }
 
 func (s *PaymentService) Update(ctx context.Context, id uint, req dto.UpdatePaymentRequest) (*models.Payment, error) {
-	payment, err := s.paymentRepo.FindByID(ctx, id)
+	var completedWorkOrderID *uint
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		payment, err := s.paymentRepo.WithTx(tx).FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		previousStatus := payment.Status
+		if req.Status != nil && models.PaymentStatus(*req.Status) != previousStatus {
+			status := models.PaymentStatus(*req.Status)
+			if !canTransitionPayment(previousStatus, status) {
+				return fmt.Errorf("cannot change payment status from %s to %s", previousStatus, status)
+			}
+			payment.Status = status
+			if status == models.PaymentStatusCompleted {
+				now := time.Now()
+				payment.PaidAt = &now
+			}
+		}
+		if req.ReferenceNumber != nil {
+			payment.ReferenceNumber = req.ReferenceNumber
+		}
+		if req.RawPayload != nil {
+			jsonData, _ := datatypes.NewJSONType(req.RawPayload).MarshalJSON()
+			payment.RawPayload = jsonData
+		}
+
+		if err := tx.Save(payment).Error; err != nil {
+			return err
+		}
+
+		// A tip that never came in is not owed to the staff
+		if previousStatus == models.PaymentStatusPending && payment.Status == models.PaymentStatusFailed {
+			return s.tipService.Release(ctx, tx, payment.ID)
+		}
+
+		// A pending payment that settles may finish paying for its order
+		if previousStatus != models.PaymentStatusCompleted && payment.Status == models.PaymentStatusCompleted {
+			workOrder, err := s.workOrderRepo.WithTx(tx).FindByIDForUpdate(ctx, payment.WorkOrderID)
+			if err != nil {
+				return err
+			}
+			completed, err := s.completeInTx(ctx, tx, workOrder)
+			if completed {
+				completedWorkOrderID = &workOrder.ID
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if completedWorkOrderID != nil {
+		s.receiptDelivery.SendForCompletedOrder(*completedWorkOrderID)
+	}
+
+	return s.paymentRepo.FindWithDetails(ctx, id)
+}
+
+// Void cancels a completed payment that was taken by mistake, such as one
+// with the wrong method or entered twice. Payments are never deleted: the
+// record is kept with its reason and who approved it. Voiding is only
+// possible while the payment's shift is still open; afterwards the money
+// has to be given back with a refund. A work order the payment had
+// completed is reopened with its balance outstanding again.
+func (s *PaymentService) Void(ctx context.Context, id uint, reason string, requestedByUserID *uint, approval *Approval) (*models.Payment, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		payment, err := s.paymentRepo.WithTx(tx).FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return errors.New("payment not found")
+		}
+		if payment.Status != models.PaymentStatusCompleted {
+			return fmt.Errorf("cannot void a %s payment", payment.Status)
+		}
+		if payment.RefundedAmount > 0 {
+			return errors.New("payment has been partly refunded, refund the rest instead")
+		}
+		if payment.InvoiceID != nil {
+			return errors.New("payment has been invoiced to its fleet account and cannot be voided")
+		}
+
+		if payment.ShiftID == nil {
+			return errors.New("payment does not belong to a shift and cannot be voided")
+		}
+		shift, err := s.shiftRepo.WithTx(tx).FindByIDForUpdate(ctx, *payment.ShiftID)
+		if err != nil {
+			return err
+		}
+		if shift.Status != models.ShiftStatusActive {
+			return errors.New("the payment's shift is closed, refund the payment instead")
+		}
+
+		workOrder, err := s.workOrderRepo.WithTx(tx).FindByIDForUpdate(ctx, payment.WorkOrderID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		payment.Status = models.PaymentStatusVoided
+		payment.VoidedAt = &now
+		payment.VoidReason = &reason
+		payment.VoidedByUserID = requestedByUserID
+		payment.VoidApprovedByUserID = &approval.Supervisor.ID
+		if err := tx.Save(payment).Error; err != nil {
+			return err
+		}
+		if err := approval.Consume(ctx, tx); err != nil {
+			return err
+		}
+		if err := s.tipService.Release(ctx, tx, payment.ID); err != nil {
+			return err
+		}
+
+		// Give the wallet back what the payment took, tip included
+		if payment.Method == models.MethodWallet && workOrder.CustomerUserID != nil {
+			if err := s.walletService.CreditForVoid(ctx, tx, *workOrder.CustomerUserID, payment.AmountPaid+payment.TipAmount, payment.ID, requestedByUserID); err != nil {
+				return err
+			}
+		}
+
+		return s.reopenInTx(ctx, tx, workOrder)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if req.Status != nil {
-		payment.Status = models.PaymentStatus(*req.Status)
+	return s.paymentRepo.FindWithDetails(ctx, id)
+}
+
+// ExpirePending fails the payments that stayed pending longer than their
+// method's pending TTL, or defaultTTL for methods without one, such as a
+// QRIS code the customer walked away from. The tip split from an expired
+// payment is released. It returns how many payments expired.
+func (s *PaymentService) ExpirePending(ctx context.Context, defaultTTL time.Duration) (int, error) {
+	methods, err := s.paymentMethods.GetAll(ctx)
+	if err != nil {
+		return 0, err
 	}
-	if req.ReferenceNumber != nil {
-		payment.ReferenceNumber = req.ReferenceNumber
+
+	ttls := make(map[models.PaymentMethod]time.Duration, len(methods))
+	shortest := defaultTTL
+	for _, method := range methods {
+		if method.PendingTTLMinutes == nil {
+			continue
+		}
+		ttl := time.Duration(*method.PendingTTLMinutes) * time.Minute
+		ttls[method.Code] = ttl
+		if ttl < shortest {
+			shortest = ttl
+		}
 	}
-	if req.RawPayload != nil {
-		jsonData, _ := datatypes.NewJSONType(req.RawPayload).MarshalJSON()
-		payment.RawPayload = jsonData
+
+	now := time.Now()
+	candidates, err := s.paymentRepo.FindPendingCreatedBefore(ctx, now.Add(-shortest))
+	if err != nil {
+		return 0, err
 	}
 
-	if err := s.paymentRepo.Update(ctx, payment); err != nil {
-		return nil, err
+	expired := 0
+	for _, candidate := range candidates {
+		ttl, ok := ttls[candidate.Method]
+		if !ok {
+			ttl = defaultTTL
+		}
+		if candidate.CreatedAt.After(now.Add(-ttl)) {
+			continue
+		}
+
+		ok, err := s.expire(ctx, candidate.ID, ttl)
+		if err != nil {
+			return expired, err
+		}
+		if ok {
+			expired++
+		}
 	}
 
-	return payment, nil
+	return expired, nil
 }
 
-func (s *PaymentService) Delete(ctx context.Context, id uint) error {
-	return s.paymentRepo.Delete(ctx, id)
+// expire fails one pending payment, unless it settled in the meantime.
+func (s *PaymentService) expire(ctx context.Context, id uint, ttl time.Duration) (bool, error) {
+	var payment *models.Payment
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		payment, err = s.paymentRepo.WithTx(tx).FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if payment.Status != models.PaymentStatusPending {
+			payment = nil
+			return nil
+		}
+
+		reason := fmt.Sprintf("expired after %d minutes pending", int(ttl.Minutes()))
+		payment.Status = models.PaymentStatusFailed
+		payment.FailureReason = &reason
+		if err := tx.Save(payment).Error; err != nil {
+			return err
+		}
+
+		return s.tipService.Release(ctx, tx, payment.ID)
+	})
+	if err != nil || payment == nil {
+		return false, err
+	}
+
+	log.Printf("Payment %s (%s, work order %d) expired after %d minutes pending", payment.PaymentNumber, payment.Method, payment.WorkOrderID, int(ttl.Minutes()))
+	return true, nil
 }
 
 func (s *PaymentService) GetByWorkOrder(ctx context.Context, workOrderID uint) ([]models.Payment, error) {
 	return s.paymentRepo.FindByWorkOrder(ctx, workOrderID)
 }
+
+// activeShift returns the open shift of the user taking a payment. The
+// shift stays locked against closing until the transaction ends, so a
+// payment is never booked to a shift whose drawer has been counted.
+func (s *PaymentService) activeShift(ctx context.Context, tx *gorm.DB, cashierUserID *uint) (*models.Shift, error) {
+	if cashierUserID == nil {
+		return nil, ErrNoActiveShift
+	}
+	shift, err := s.shiftRepo.WithTx(tx).FindActiveShiftByUserForShare(ctx, *cashierUserID)
+	if err != nil {
+		return nil, err
+	}
+	if shift == nil {
+		return nil, ErrNoActiveShift
+	}
+	return shift, nil
+}
+
+// refusePending rejects a new payment on a work order that still has a
+// pending one, such as a QRIS code for the whole balance the customer may
+// yet pay. The pending payment has to complete or be cancelled first.
+func refusePending(ctx context.Context, paymentRepo *repository.PaymentRepository, workOrderID uint) error {
+	pending, err := paymentRepo.FindPendingByWorkOrder(ctx, workOrderID)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("payment %s is still pending for this work order, wait for it or cancel it first", pending[0].PaymentNumber)
+	}
+	return nil
+}
+
+// canTransitionPayment reports whether a payment may move between two
+// statuses. Only pending payments change status; money given back on a
+// completed payment is recorded as a Refund instead.
+func canTransitionPayment(from, to models.PaymentStatus) bool {
+	if from == models.PaymentStatusPending {
+		return to == models.PaymentStatusCompleted || to == models.PaymentStatusFailed
+	}
+	return false
+}
+
+// completeInTx marks a work order completed once its payments cover the
+// total and credits the customer's loyalty points, as part of the caller's
+// transaction. The work order should be locked by the caller. It reports
+// whether the order was completed by this call.
+func (s *PaymentService) completeInTx(ctx context.Context, tx *gorm.DB, workOrder *models.WorkOrder) (bool, error) {
+	if workOrder.Status == models.StatusCompleted {
+		return false, nil
+	}
+
+	netPaid, err := s.paymentRepo.WithTx(tx).GetNetPaidForWorkOrder(ctx, workOrder.ID)
+	if err != nil {
+		return false, err
+	}
+	if roundAmount(netPaid) < roundAmount(workOrder.TotalAmount) {
+		return false, nil
+	}
+
+	now := time.Now()
+	workOrder.Status = models.StatusCompleted
+	workOrder.CompletedAt = &now
+	if err := tx.Save(workOrder).Error; err != nil {
+		return false, err
+	}
+
+	if err := s.loyaltyService.AwardForWorkOrder(ctx, tx, workOrder.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// reopenInTx undoes the completion of a work order whose payments no longer
+// cover its total, taking back the loyalty points it earned. The order goes
+// back to ready, as the work itself has been done. The work order should be
+// locked by the caller.
+func (s *PaymentService) reopenInTx(ctx context.Context, tx *gorm.DB, workOrder *models.WorkOrder) error {
+	if workOrder.Status != models.StatusCompleted {
+		return nil
+	}
+
+	netPaid, err := s.paymentRepo.WithTx(tx).GetNetPaidForWorkOrder(ctx, workOrder.ID)
+	if err != nil {
+		return err
+	}
+	if roundAmount(netPaid) >= roundAmount(workOrder.TotalAmount) {
+		return nil
+	}
+
+	workOrder.Status = models.StatusReady
+	workOrder.CompletedAt = nil
+	if err := tx.Save(workOrder).Error; err != nil {
+		return err
+	}
+
+	return s.loyaltyService.ReverseForWorkOrder(ctx, tx, workOrder.ID, false)
+}
+
+// allocateChange checks that the tenders settle the balance and returns the
+// change each tender hands back. Tenders whose method gives no change may
+// not exceed what is left to pay, so any overpayment is covered by tenders
+// that do and is given back from them in order.
+func allocateChange(tenders []dto.CheckoutTenderRequest, methods []*models.PaymentMethodSetting, balance float64) ([]float64, error) {
+	var total, withoutChange float64
+	for i, tender := range tenders {
+		total += tender.Amount
+		if !methods[i].AllowsChange {
+			withoutChange += tender.Amount
+		}
+	}
+	total = roundAmount(total)
+	withoutChange = roundAmount(withoutChange)
+
+	if total < balance {
+		return nil, fmt.Errorf("tenders total %.2f but the balance is %.2f", total, balance)
+	}
+	if withoutChange > balance {
+		return nil, errors.New("only tenders that give change may exceed the balance")
+	}
+
+	changes := make([]float64, len(tenders))
+	remaining := roundAmount(total - balance)
+	for i, tender := range tenders {
+		if remaining <= 0 {
+			break
+		}
+		if !methods[i].AllowsChange {
+			continue
+		}
+		change := tender.Amount
+		if change > remaining {
+			change = remaining
+		}
+		changes[i] = roundAmount(change)
+		remaining = roundAmount(remaining - change)
+	}
+
+	return changes, nil
+}
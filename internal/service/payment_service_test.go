@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+)
+
+// countTips counts the tip allocations of a payment.
+func (e *testEnv) countTips(t *testing.T, paymentID uint) int64 {
+	t.Helper()
+	var count int64
+	if err := e.db.Model(&models.TipAllocation{}).Where("payment_id = ?", paymentID).Count(&count).Error; err != nil {
+		t.Fatalf("count tip allocations: %v", err)
+	}
+	return count
+}
+
+func TestVoidReleasesTip(t *testing.T) {
+	env := newTestEnv(t)
+	cashier := env.newUser(t, models.RoleCashier)
+	env.newShift(t, cashier)
+	staff := env.newUser(t, models.RoleStaff)
+	supervisor := env.newSupervisor(t, "482193")
+	product := env.newProduct(t, 50000)
+	workOrder := env.newWorkOrder(t, 50000)
+	env.create(t, &models.WorkOrderItem{
+		WorkOrderID:         workOrder.ID,
+		ProductID:           product.ID,
+		ProductNameSnapshot: product.Name,
+		PriceSnapshot:       50000,
+		Quantity:            1,
+		Subtotal:            50000,
+		AssignedStaffUserID: &staff.ID,
+	})
+	ctx := context.Background()
+
+	payment, err := env.payments.Create(ctx, dto.CreatePaymentRequest{WorkOrderID: workOrder.ID, Method: "cash", AmountPaid: 50000, TipAmount: 5000}, &cashier.ID)
+	if err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+	if count := env.countTips(t, payment.ID); count != 1 {
+		t.Fatalf("tipped payment has %d tip allocations, want 1", count)
+	}
+
+	approval, err := env.supervisor.Approve(ctx, &cashier.ID, pinApproval(supervisor, "482193"))
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if _, err := env.payments.Void(ctx, payment.ID, "wrong order", &cashier.ID, approval); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	if count := env.countTips(t, payment.ID); count != 0 {
+		t.Errorf("voided payment still has %d tip allocations", count)
+	}
+}
+
+func TestFailedPaymentReleasesTip(t *testing.T) {
+	env := newTestEnv(t)
+	staff := env.newUser(t, models.RoleStaff)
+	workOrder := env.newWorkOrder(t, 50000)
+	payment := env.newPendingQRIS(t, workOrder, "PAY-1", 50000)
+	env.create(t, &models.TipAllocation{PaymentID: payment.ID, WorkOrderID: workOrder.ID, StaffUserID: &staff.ID, Amount: 5000})
+
+	failed := string(models.PaymentStatusFailed)
+	if _, err := env.payments.Update(context.Background(), payment.ID, dto.UpdatePaymentRequest{Status: &failed}); err != nil {
+		t.Fatalf("fail payment: %v", err)
+	}
+	if count := env.countTips(t, payment.ID); count != 0 {
+		t.Errorf("failed payment still has %d tip allocations", count)
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/gateway"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type PaymentWebhookService struct {
+	gateways       *gateway.Registry
+	paymentRepo    *repository.PaymentRepository
+	eventRepo      *repository.PaymentWebhookEventRepository
+	workOrderRepo  *repository.WorkOrderRepository
+	paymentService *PaymentService
+	db             *gorm.DB
+}
+
+func NewPaymentWebhookService(
+	gateways *gateway.Registry,
+	paymentRepo *repository.PaymentRepository,
+	eventRepo *repository.PaymentWebhookEventRepository,
+	workOrderRepo *repository.WorkOrderRepository,
+	paymentService *PaymentService,
+	db *gorm.DB,
+) *PaymentWebhookService {
+	return &PaymentWebhookService{
+		gateways:       gateways,
+		paymentRepo:    paymentRepo,
+		eventRepo:      eventRepo,
+		workOrderRepo:  workOrderRepo,
+		paymentService: paymentService,
+		db:             db,
+	}
+}
+
+// Handle processes one webhook delivery from a provider. Only pending
+// payments are settled, except that money received for a payment that
+// already failed, such as a QRIS code paid after it expired, is applied
+// late or flagged for a refund. Every verified delivery is recorded, and a
+// delivery seen before is acknowledged without being applied again.
+func (s *PaymentWebhookService) Handle(ctx context.Context, provider string, header http.Header, body []byte) (*dto.PaymentWebhookResult, error) {
+	adapter, err := s.gateways.Get(provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := adapter.Verify(header, body); err != nil {
+		return nil, err
+	}
+	notification, err := adapter.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	result := &dto.PaymentWebhookResult{EventID: notification.EventID}
+	var completedWorkOrderID *uint
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		eventRepo := s.eventRepo.WithTx(tx)
+		event := &models.PaymentWebhookEvent{
+			Provider:   provider,
+			EventID:    notification.EventID,
+			RawBody:    body,
+			ReceivedAt: time.Now(),
+		}
+
+		created, err := eventRepo.Record(ctx, event)
+		if err != nil {
+			return err
+		}
+		if !created {
+			result.Duplicate = true
+			return nil
+		}
+
+		payment, err := s.paymentRepo.WithTx(tx).FindByPaymentNumberForUpdate(ctx, notification.PaymentNumber)
+		if err != nil {
+			return err
+		}
+
+		late := payment != nil && payment.Status == models.PaymentStatusFailed &&
+			notification.Status == gateway.NotificationPaid
+
+		var message string
+		switch {
+		case payment == nil:
+			event.Status = models.WebhookEventUnmatched
+			message = fmt.Sprintf("no payment with number %s", notification.PaymentNumber)
+		case payment.Status != models.PaymentStatusPending && !late:
+			event.Status = models.WebhookEventIgnored
+			message = fmt.Sprintf("payment is already %s", payment.Status)
+		case notification.Status == gateway.NotificationPaid &&
+			roundAmount(notification.Amount) != roundAmount(payment.AmountPaid+payment.TipAmount):
+			event.Status = models.WebhookEventRejected
+			message = fmt.Sprintf("amount %.2f does not match expected %.2f", notification.Amount, payment.AmountPaid+payment.TipAmount)
+		case late:
+			revived, err := s.settleLate(ctx, tx, payment, notification, body)
+			if err != nil {
+				return err
+			}
+			if revived {
+				event.Status = models.WebhookEventProcessed
+				message = "paid after the payment failed, applied to the balance"
+			} else {
+				event.Status = models.WebhookEventRefundRequired
+				message = "paid after the payment failed and the balance is already settled, refund the customer"
+			}
+		default:
+			if err := s.apply(tx, payment, notification, body); err != nil {
+				return err
+			}
+			event.Status = models.WebhookEventProcessed
+		}
+
+		// A settled payment may finish paying for its work order
+		if event.Status == models.WebhookEventProcessed && payment.Status == models.PaymentStatusCompleted {
+			workOrder, err := s.workOrderRepo.WithTx(tx).FindByIDForUpdate(ctx, payment.WorkOrderID)
+			if err != nil {
+				return err
+			}
+			completed, err := s.paymentService.completeInTx(ctx, tx, workOrder)
+			if err != nil {
+				return err
+			}
+			if completed {
+				completedWorkOrderID = &workOrder.ID
+			}
+		}
+
+		if payment != nil {
+			event.PaymentID = &payment.ID
+			result.PaymentID = &payment.ID
+			result.PaymentStatus = string(payment.Status)
+		}
+		if message != "" {
+			event.Message = &message
+		}
+		result.Status = string(event.Status)
+
+		return eventRepo.Update(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Status == string(models.WebhookEventRefundRequired) {
+		log.Printf("Payment %s was paid after it failed and needs a refund", notification.PaymentNumber)
+	}
+	if completedWorkOrderID != nil {
+		s.paymentService.receiptDelivery.SendForCompletedOrder(*completedWorkOrderID)
+	}
+
+	return result, nil
+}
+
+// apply moves a pending payment to the state the provider reported and
+// keeps the provider's payload on it.
+func (s *PaymentWebhookService) apply(tx *gorm.DB, payment *models.Payment, notification *gateway.Notification, body []byte) error {
+	if notification.Status == gateway.NotificationPaid {
+		now := time.Now()
+		payment.Status = models.PaymentStatusCompleted
+		payment.PaidAt = &now
+	} else {
+		payment.Status = models.PaymentStatusFailed
+	}
+
+	if notification.ProviderReference != "" {
+		reference := notification.ProviderReference
+		payment.ReferenceNumber = &reference
+	}
+	payment.RawPayload = body
+
+	return tx.Save(payment).Error
+}
+
+// settleLate handles money received for a payment that had already failed.
+// The payment is completed after all when the work order still owes its
+// amount and no other payment is pending for the balance. Otherwise it
+// stays failed, keeps the provider's reference and is flagged for a refund.
+// It reports whether the payment was completed.
+func (s *PaymentWebhookService) settleLate(ctx context.Context, tx *gorm.DB, payment *models.Payment, notification *gateway.Notification, body []byte) (bool, error) {
+	workOrder, err := s.workOrderRepo.WithTx(tx).FindByIDForUpdate(ctx, payment.WorkOrderID)
+	if err != nil {
+		return false, err
+	}
+
+	paymentRepo := s.paymentRepo.WithTx(tx)
+	netPaid, err := paymentRepo.GetNetPaidForWorkOrder(ctx, workOrder.ID)
+	if err != nil {
+		return false, err
+	}
+	pending, err := paymentRepo.FindPendingByWorkOrder(ctx, workOrder.ID)
+	if err != nil {
+		return false, err
+	}
+
+	balance := roundAmount(workOrder.TotalAmount - netPaid)
+	if workOrder.Status != models.StatusCancelled && len(pending) == 0 && roundAmount(payment.AmountPaid) <= balance {
+		payment.FailureReason = nil
+		if err := s.apply(tx, payment, notification, body); err != nil {
+			return false, err
+		}
+		// The tip split was released when the payment failed
+		return true, s.paymentService.tipService.Distribute(ctx, tx, payment)
+	}
+
+	now := time.Now()
+	payment.RefundRequired = true
+	payment.PaidAt = &now
+	if notification.ProviderReference != "" {
+		reference := notification.ProviderReference
+		payment.ReferenceNumber = &reference
+	}
+	payment.RawPayload = body
+	return false, tx.Save(payment).Error
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/gateway"
+	"flashlight-go/internal/models"
+)
+
+// newPendingQRIS creates a QRIS payment waiting for the provider.
+func (e *testEnv) newPendingQRIS(t *testing.T, workOrder *models.WorkOrder, number string, amount float64) *models.Payment {
+	t.Helper()
+	payment := &models.Payment{
+		WorkOrderID:   workOrder.ID,
+		PaymentNumber: number,
+		Method:        models.MethodQRIS,
+		Status:        models.PaymentStatusPending,
+		AmountPaid:    amount,
+		NetAmount:     amount,
+	}
+	e.create(t, payment)
+	return payment
+}
+
+// deliver sends a fake provider webhook, signed unless signature is given.
+func (e *testEnv) deliver(t *testing.T, payload gateway.FakePayload, signature ...string) (*dto.PaymentWebhookResult, error) {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	header := http.Header{}
+	if len(signature) > 0 {
+		header.Set(gateway.FakeSignatureHeader, signature[0])
+	} else {
+		header.Set(gateway.FakeSignatureHeader, e.fake.Sign(body))
+	}
+
+	return e.webhooks.Handle(context.Background(), e.fake.Name(), header, body)
+}
+
+func (e *testEnv) countEvents(t *testing.T) int64 {
+	t.Helper()
+	var count int64
+	if err := e.db.Model(&models.PaymentWebhookEvent{}).Count(&count).Error; err != nil {
+		t.Fatalf("count events: %v", err)
+	}
+	return count
+}
+
+func TestWebhookRejectsBadSignature(t *testing.T) {
+	env := newTestEnv(t)
+	workOrder := env.newWorkOrder(t, 50000)
+	payment := env.newPendingQRIS(t, workOrder, "PAY-1", 50000)
+
+	payload := gateway.FakePayload{EventID: "evt-1", PaymentNumber: "PAY-1", Status: "paid", Amount: 50000}
+	for _, signature := range []string{"", "not-hex", env.fake.Sign([]byte("another body"))} {
+		if _, err := env.deliver(t, payload, signature); !errors.Is(err, gateway.ErrInvalidSignature) {
+			t.Errorf("signature %q: got error %v, want %v", signature, err, gateway.ErrInvalidSignature)
+		}
+	}
+
+	if count := env.countEvents(t); count != 0 {
+		t.Errorf("recorded %d events for unsigned deliveries, want 0", count)
+	}
+	env.reload(t, payment, payment.ID)
+	if payment.Status != models.PaymentStatusPending {
+		t.Errorf("payment is %s, want it still pending", payment.Status)
+	}
+}
+
+func TestWebhookCompletesWorkOrder(t *testing.T) {
+	env := newTestEnv(t)
+	workOrder := env.newWorkOrder(t, 50000)
+	payment := env.newPendingQRIS(t, workOrder, "PAY-1", 50000)
+
+	result, err := env.deliver(t, gateway.FakePayload{EventID: "evt-1", PaymentNumber: "PAY-1", TransactionID: "TX-1", Status: "settlement", Amount: 50000})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if result.Status != string(models.WebhookEventProcessed) || result.PaymentStatus != string(models.PaymentStatusCompleted) {
+		t.Errorf("got %s with payment %s, want processed with payment completed", result.Status, result.PaymentStatus)
+	}
+
+	env.reload(t, payment, payment.ID)
+	if payment.Status != models.PaymentStatusCompleted || payment.PaidAt == nil {
+		t.Errorf("payment is %s, want completed with a paid time", payment.Status)
+	}
+	if payment.ReferenceNumber == nil || *payment.ReferenceNumber != "TX-1" {
+		t.Errorf("payment reference is %v, want TX-1", payment.ReferenceNumber)
+	}
+	env.reload(t, workOrder, workOrder.ID)
+	if workOrder.Status != models.StatusCompleted {
+		t.Errorf("work order is %s, want completed", workOrder.Status)
+	}
+}
+
+func TestWebhookDuplicateDelivery(t *testing.T) {
+	env := newTestEnv(t)
+	workOrder := env.newWorkOrder(t, 80000)
+	env.newPendingQRIS(t, workOrder, "PAY-1", 50000)
+
+	payload := gateway.FakePayload{EventID: "evt-1", PaymentNumber: "PAY-1", Status: "paid", Amount: 50000}
+	first, err := env.deliver(t, payload)
+	if err != nil {
+		t.Fatalf("first delivery: %v", err)
+	}
+	if first.Duplicate || first.Status != string(models.WebhookEventProcessed) {
+		t.Fatalf("first delivery: got %+v, want it processed", first)
+	}
+
+	// The second payment is still pending, so a redelivery that was applied
+	// again would move it
+	second := env.newPendingQRIS(t, workOrder, "PAY-2", 30000)
+	payload.PaymentNumber = "PAY-2"
+	payload.Amount = 30000
+	redelivery, err := env.deliver(t, payload)
+	if err != nil {
+		t.Fatalf("redelivery: %v", err)
+	}
+	if !redelivery.Duplicate {
+		t.Errorf("redelivery of evt-1 was not reported as a duplicate")
+	}
+	env.reload(t, second, second.ID)
+	if second.Status != models.PaymentStatusPending {
+		t.Errorf("redelivery settled another payment, it is %s", second.Status)
+	}
+	if count := env.countEvents(t); count != 1 {
+		t.Errorf("recorded %d events, want 1", count)
+	}
+}
+
+func TestWebhookAmountMismatch(t *testing.T) {
+	env := newTestEnv(t)
+	workOrder := env.newWorkOrder(t, 50000)
+	payment := env.newPendingQRIS(t, workOrder, "PAY-1", 50000)
+
+	result, err := env.deliver(t, gateway.FakePayload{EventID: "evt-1", PaymentNumber: "PAY-1", Status: "paid", Amount: 5000})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if result.Status != string(models.WebhookEventRejected) {
+		t.Errorf("got %s, want rejected", result.Status)
+	}
+
+	env.reload(t, payment, payment.ID)
+	if payment.Status != models.PaymentStatusPending {
+		t.Errorf("payment is %s, want it still pending", payment.Status)
+	}
+	env.reload(t, workOrder, workOrder.ID)
+	if workOrder.Status == models.StatusCompleted {
+		t.Errorf("work order was completed by a payment of the wrong amount")
+	}
+}
+
+// expireQRIS lets a pending payment run past its TTL and expires it.
+func (e *testEnv) expireQRIS(t *testing.T, payment *models.Payment) {
+	t.Helper()
+	if err := e.db.Model(payment).Update("created_at", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatalf("age payment: %v", err)
+	}
+	if expired, err := e.payments.ExpirePending(context.Background(), 30*time.Minute); err != nil || expired != 1 {
+		t.Fatalf("ExpirePending: expired %d, %v", expired, err)
+	}
+}
+
+func TestWebhookLatePaymentRevived(t *testing.T) {
+	env := newTestEnv(t)
+	workOrder := env.newWorkOrder(t, 50000)
+	payment := env.newPendingQRIS(t, workOrder, "PAY-1", 50000)
+	env.expireQRIS(t, payment)
+
+	result, err := env.deliver(t, gateway.FakePayload{EventID: "evt-1", PaymentNumber: "PAY-1", TransactionID: "TX-1", Status: "paid", Amount: 50000})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if result.Status != string(models.WebhookEventProcessed) || result.PaymentStatus != string(models.PaymentStatusCompleted) {
+		t.Errorf("got %s with payment %s, want processed with payment completed", result.Status, result.PaymentStatus)
+	}
+
+	env.reload(t, payment, payment.ID)
+	if payment.Status != models.PaymentStatusCompleted || payment.FailureReason != nil || payment.RefundRequired {
+		t.Errorf("payment is %s (reason %v, refund %v), want completed", payment.Status, payment.FailureReason, payment.RefundRequired)
+	}
+	env.reload(t, workOrder, workOrder.ID)
+	if workOrder.Status != models.StatusCompleted {
+		t.Errorf("work order is %s, want completed", workOrder.Status)
+	}
+}
+
+func TestWebhookLatePaymentRefundRequired(t *testing.T) {
+	env := newTestEnv(t)
+	workOrder := env.newWorkOrder(t, 50000)
+	payment := env.newPendingQRIS(t, workOrder, "PAY-1", 50000)
+	env.expireQRIS(t, payment)
+
+	// The customer paid cash instead once the code expired
+	env.create(t, &models.Payment{
+		WorkOrderID:   workOrder.ID,
+		PaymentNumber: "PAY-2",
+		Method:        models.MethodCash,
+		Status:        models.PaymentStatusCompleted,
+		AmountPaid:    50000,
+		NetAmount:     50000,
+	})
+
+	result, err := env.deliver(t, gateway.FakePayload{EventID: "evt-1", PaymentNumber: "PAY-1", TransactionID: "TX-1", Status: "paid", Amount: 50000})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if result.Status != string(models.WebhookEventRefundRequired) {
+		t.Errorf("got %s, want %s", result.Status, models.WebhookEventRefundRequired)
+	}
+
+	env.reload(t, payment, payment.ID)
+	if payment.Status != models.PaymentStatusFailed || !payment.RefundRequired {
+		t.Errorf("payment is %s (refund %v), want failed and flagged for a refund", payment.Status, payment.RefundRequired)
+	}
+	if payment.ReferenceNumber == nil || *payment.ReferenceNumber != "TX-1" {
+		t.Errorf("payment reference is %v, want TX-1", payment.ReferenceNumber)
+	}
+	total, err := env.payments.paymentRepo.GetNetPaidForWorkOrder(context.Background(), workOrder.ID)
+	if err != nil || total != 50000 {
+		t.Errorf("total paid is %.2f (%v), want 50000", total, err)
+	}
+}
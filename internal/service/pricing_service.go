@@ -0,0 +1,896 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// PricedItem is the unit price of a product at a given moment, together with
+// the vehicle tier and the time-based rule that adjusted it, if any.
+type PricedItem struct {
+	BasePrice float64
+	UnitPrice float64
+	Tier      *models.VehiclePriceTier
+	Rule      *models.PricingRule
+}
+
+// QuoteInput is what an order is priced from: the customer and their
+// vehicle, a promo code the customer entered and the items.
+type QuoteInput struct {
+	CustomerUserID    *uint
+	CustomerVehicleID *uint
+	PromoCode         *string
+	Items             []dto.CreateWorkOrderItemRequest
+}
+
+// QuoteLine is one priced line of an order.
+type QuoteLine struct {
+	Product             *models.Product
+	Quantity            int
+	BasePrice           float64
+	UnitPrice           float64
+	Tier                *models.VehiclePriceTier
+	Rule                *models.PricingRule
+	Subtotal            float64
+	AssignedStaffUserID *uint
+	ItemNote            *string
+}
+
+// Quote is the complete price of an order as it would be charged at
+// PricedAt. It is produced without writing anything, so the same quote backs
+// both the price preview and the order that is eventually created.
+//
+// DiscountAmount is everything taken off the subtotal, the membership
+// discount and the promo together; PromoDiscountAmount is the promo's part.
+type Quote struct {
+	Lines               []QuoteLine
+	VehicleType         string
+	Subtotal            float64
+	DiscountAmount      float64
+	DiscountDescription *string
+	Promo               *models.Promo
+	PromoDiscountAmount float64
+	TaxRate             float64
+	TaxAmount           float64
+	TotalAmount         float64
+	PricedAt            time.Time
+}
+
+// membershipBenefits is the part of MembershipType.Benefits used for pricing.
+type membershipBenefits struct {
+	DiscountPercentage float64 `json:"discount_percentage"`
+}
+
+type PricingService struct {
+	productRepo          *repository.ProductRepository
+	productPriceRepo     *repository.ProductPriceRepository
+	pricingRuleRepo      *repository.PricingRuleRepository
+	vehiclePriceTierRepo *repository.VehiclePriceTierRepository
+	promoRepo            *repository.PromoRepository
+	customerVehicleRepo  *repository.CustomerVehicleRepository
+	userRepo             *repository.UserRepository
+	location             *time.Location
+	taxRate              float64
+}
+
+func NewPricingService(
+	productRepo *repository.ProductRepository,
+	productPriceRepo *repository.ProductPriceRepository,
+	pricingRuleRepo *repository.PricingRuleRepository,
+	vehiclePriceTierRepo *repository.VehiclePriceTierRepository,
+	promoRepo *repository.PromoRepository,
+	customerVehicleRepo *repository.CustomerVehicleRepository,
+	userRepo *repository.UserRepository,
+	location *time.Location,
+	taxRate float64,
+) *PricingService {
+	return &PricingService{
+		productRepo:          productRepo,
+		productPriceRepo:     productPriceRepo,
+		pricingRuleRepo:      pricingRuleRepo,
+		vehiclePriceTierRepo: vehiclePriceTierRepo,
+		promoRepo:            promoRepo,
+		customerVehicleRepo:  customerVehicleRepo,
+		userRepo:             userRepo,
+		location:             location,
+		taxRate:              taxRate,
+	}
+}
+
+// Quote prices a set of order items for a customer: unit prices per item
+// for the customer's vehicle type, then the customer's membership discount,
+// then the promo, then tax on the discounted amount.
+func (s *PricingService) Quote(ctx context.Context, input QuoteInput, at time.Time) (*Quote, error) {
+	quote := &Quote{
+		Lines:    make([]QuoteLine, 0, len(input.Items)),
+		TaxRate:  s.taxRate,
+		PricedAt: at,
+	}
+
+	if input.CustomerVehicleID != nil {
+		customerVehicle, err := s.customerVehicleRepo.FindWithVehicle(ctx, *input.CustomerVehicleID)
+		if err != nil {
+			return nil, errors.New("customer vehicle not found")
+		}
+		if input.CustomerUserID != nil && customerVehicle.CustomerID != *input.CustomerUserID {
+			return nil, errors.New("the vehicle does not belong to the customer")
+		}
+		quote.VehicleType = customerVehicle.Vehicle.VehicleType
+	}
+
+	for _, itemReq := range input.Items {
+		product, err := s.productRepo.FindByID(ctx, itemReq.ProductID)
+		if err != nil {
+			return nil, errors.New("product not found")
+		}
+
+		priced, err := s.PriceProductFor(ctx, product, quote.VehicleType, at)
+		if err != nil {
+			return nil, err
+		}
+
+		lineSubtotal := roundAmount(priced.UnitPrice * float64(itemReq.Quantity))
+		quote.Lines = append(quote.Lines, QuoteLine{
+			Product:             product,
+			Quantity:            itemReq.Quantity,
+			BasePrice:           priced.BasePrice,
+			UnitPrice:           priced.UnitPrice,
+			Tier:                priced.Tier,
+			Rule:                priced.Rule,
+			Subtotal:            lineSubtotal,
+			AssignedStaffUserID: itemReq.AssignedStaffUserID,
+			ItemNote:            itemReq.ItemNote,
+		})
+		quote.Subtotal += lineSubtotal
+	}
+	quote.Subtotal = roundAmount(quote.Subtotal)
+
+	var descriptions []string
+	if input.CustomerUserID != nil {
+		customer, err := s.userRepo.FindWithMembership(ctx, *input.CustomerUserID)
+		if err != nil {
+			return nil, errors.New("customer not found")
+		}
+
+		if percentage, name := membershipDiscount(customer, at); percentage > 0 {
+			quote.DiscountAmount = roundAmount(quote.Subtotal * percentage / 100)
+			descriptions = append(descriptions, fmt.Sprintf("%s membership discount (%g%%)", name, percentage))
+		}
+	}
+
+	if input.PromoCode != nil && strings.TrimSpace(*input.PromoCode) != "" {
+		promo, err := s.promoRepo.FindByCode(ctx, normalizePromoCode(*input.PromoCode))
+		if err != nil {
+			return nil, err
+		}
+		if promo == nil {
+			return nil, errors.New("promo code not found")
+		}
+		if err := promoUsable(promo, quote.Subtotal, at); err != nil {
+			return nil, err
+		}
+
+		quote.Promo = promo
+		quote.PromoDiscountAmount = promoDiscount(promo, quote.Subtotal-quote.DiscountAmount)
+		quote.DiscountAmount = roundAmount(quote.DiscountAmount + quote.PromoDiscountAmount)
+		descriptions = append(descriptions, fmt.Sprintf("Promo %s (%s)", promo.Code, promo.Name))
+	}
+	if len(descriptions) > 0 {
+		description := strings.Join(descriptions, ", ")
+		quote.DiscountDescription = &description
+	}
+
+	taxable := quote.Subtotal - quote.DiscountAmount
+	quote.TaxAmount = s.TaxOn(taxable)
+	quote.TotalAmount = roundAmount(taxable + quote.TaxAmount)
+
+	return quote, nil
+}
+
+// RedeemPromo uses up one redemption of a quoted promo inside the
+// transaction that creates the order. The promo is locked and checked again,
+// so its last use cannot go to two orders.
+func (s *PricingService) RedeemPromo(ctx context.Context, tx *gorm.DB, quote *Quote) error {
+	if quote.Promo == nil {
+		return nil
+	}
+
+	promo, err := s.promoRepo.WithTx(tx).FindByIDForUpdate(ctx, quote.Promo.ID)
+	if err != nil {
+		return errors.New("promo code not found")
+	}
+	if err := promoUsable(promo, quote.Subtotal, quote.PricedAt); err != nil {
+		return err
+	}
+
+	return tx.WithContext(ctx).Model(promo).
+		Update("used_count", gorm.Expr("used_count + 1")).Error
+}
+
+// TaxOn returns the tax charged on a taxable amount, i.e. the subtotal of an
+// order after its discounts.
+func (s *PricingService) TaxOn(taxable float64) float64 {
+	return roundAmount(taxable * s.taxRate / 100)
+}
+
+// PriceProduct resolves the unit price of a product at the given time
+// without regard to a vehicle.
+func (s *PricingService) PriceProduct(ctx context.Context, product *models.Product, at time.Time) (*PricedItem, error) {
+	return s.PriceProductFor(ctx, product, "", at)
+}
+
+// PriceProductFor resolves the unit price of a product for a vehicle type at
+// the given time: the price effective from its history, adjusted by the
+// vehicle type's tier and then by the best matching pricing rule evaluated on
+// the outlet's local clock. An empty vehicle type has no tier.
+func (s *PricingService) PriceProductFor(ctx context.Context, product *models.Product, vehicleType string, at time.Time) (*PricedItem, error) {
+	basePrice := product.Price
+	effectivePrice, err := s.productPriceRepo.FindEffective(ctx, product.ID, at)
+	if err != nil {
+		return nil, err
+	}
+	if effectivePrice != nil {
+		basePrice = effectivePrice.Price
+	}
+
+	priced := &PricedItem{
+		BasePrice: basePrice,
+		UnitPrice: basePrice,
+	}
+
+	if vehicleType != "" {
+		// Product tiers come first, so the first one found is the one to use
+		tiers, err := s.vehiclePriceTierRepo.FindActiveForProduct(ctx, vehicleType, product.ID, product.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if len(tiers) > 0 {
+			priced.Tier = &tiers[0]
+			priced.UnitPrice = adjustPrice(basePrice, priced.Tier.AdjustmentType, priced.Tier.AdjustmentValue)
+		}
+	}
+
+	rules, err := s.pricingRuleRepo.FindActiveForProduct(ctx, product.ID, product.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule := selectRule(rules, product.ID, at.In(s.location)); rule != nil {
+		priced.Rule = rule
+		priced.UnitPrice = applyAdjustment(priced.UnitPrice, rule)
+	}
+
+	return priced, nil
+}
+
+func (s *PricingService) GetAllRules(ctx context.Context, page, perPage int) ([]dto.PricingRuleResponse, *dto.PaginationMeta, error) {
+	rules, total, err := s.pricingRuleRepo.FindAll(ctx, page, perPage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]dto.PricingRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = *s.toRuleResponse(&rule)
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+
+	meta := &dto.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return responses, meta, nil
+}
+
+func (s *PricingService) GetRuleByID(ctx context.Context, id uint) (*dto.PricingRuleResponse, error) {
+	rule, err := s.pricingRuleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toRuleResponse(rule), nil
+}
+
+func (s *PricingService) CreateRule(ctx context.Context, req dto.CreatePricingRuleRequest) (*dto.PricingRuleResponse, error) {
+	rule := &models.PricingRule{
+		Name:            req.Name,
+		DaysOfWeek:      encodeDaysOfWeek(req.DaysOfWeek),
+		StartTime:       req.StartTime,
+		EndTime:         req.EndTime,
+		ProductID:       req.ProductID,
+		CategoryID:      req.CategoryID,
+		AdjustmentType:  models.PricingAdjustmentType(req.AdjustmentType),
+		AdjustmentValue: req.AdjustmentValue,
+		Priority:        req.Priority,
+		IsActive:        true,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.pricingRuleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return s.toRuleResponse(rule), nil
+}
+
+func (s *PricingService) UpdateRule(ctx context.Context, id uint, req dto.UpdatePricingRuleRequest) (*dto.PricingRuleResponse, error) {
+	rule, err := s.pricingRuleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.DaysOfWeek != nil {
+		rule.DaysOfWeek = encodeDaysOfWeek(req.DaysOfWeek)
+	}
+	if req.StartTime != nil {
+		rule.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		rule.EndTime = *req.EndTime
+	}
+	// A rule targets either a product or a category, so retargeting it to
+	// one clears the other
+	if req.ProductID != nil && req.CategoryID != nil {
+		return nil, errors.New("a pricing rule cannot target both a product and a category")
+	}
+	if req.ProductID != nil {
+		rule.ProductID = req.ProductID
+		rule.CategoryID = nil
+	}
+	if req.CategoryID != nil {
+		rule.CategoryID = req.CategoryID
+		rule.ProductID = nil
+	}
+	if req.AdjustmentType != nil {
+		rule.AdjustmentType = models.PricingAdjustmentType(*req.AdjustmentType)
+	}
+	if req.AdjustmentValue != nil {
+		rule.AdjustmentValue = *req.AdjustmentValue
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.pricingRuleRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return s.toRuleResponse(rule), nil
+}
+
+func (s *PricingService) DeleteRule(ctx context.Context, id uint) error {
+	return s.pricingRuleRepo.Delete(ctx, id)
+}
+
+func (s *PricingService) toRuleResponse(rule *models.PricingRule) *dto.PricingRuleResponse {
+	return &dto.PricingRuleResponse{
+		ID:              rule.ID,
+		Name:            rule.Name,
+		DaysOfWeek:      decodeDaysOfWeek(rule.DaysOfWeek),
+		StartTime:       rule.StartTime,
+		EndTime:         rule.EndTime,
+		ProductID:       rule.ProductID,
+		CategoryID:      rule.CategoryID,
+		AdjustmentType:  string(rule.AdjustmentType),
+		AdjustmentValue: rule.AdjustmentValue,
+		Priority:        rule.Priority,
+		IsActive:        rule.IsActive,
+		CreatedAt:       rule.CreatedAt,
+		UpdatedAt:       rule.UpdatedAt,
+	}
+}
+
+func (s *PricingService) GetAllVehicleTiers(ctx context.Context, page, perPage int) ([]dto.VehiclePriceTierResponse, *dto.PaginationMeta, error) {
+	tiers, total, err := s.vehiclePriceTierRepo.FindAll(ctx, page, perPage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]dto.VehiclePriceTierResponse, len(tiers))
+	for i, tier := range tiers {
+		responses[i] = *s.toTierResponse(&tier)
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+
+	meta := &dto.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return responses, meta, nil
+}
+
+func (s *PricingService) GetVehicleTierByID(ctx context.Context, id uint) (*dto.VehiclePriceTierResponse, error) {
+	tier, err := s.vehiclePriceTierRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toTierResponse(tier), nil
+}
+
+func (s *PricingService) CreateVehicleTier(ctx context.Context, req dto.CreateVehiclePriceTierRequest) (*dto.VehiclePriceTierResponse, error) {
+	tier := &models.VehiclePriceTier{
+		VehicleType:     req.VehicleType,
+		ProductID:       req.ProductID,
+		CategoryID:      req.CategoryID,
+		AdjustmentType:  models.PricingAdjustmentType(req.AdjustmentType),
+		AdjustmentValue: req.AdjustmentValue,
+		IsActive:        true,
+	}
+	if req.IsActive != nil {
+		tier.IsActive = *req.IsActive
+	}
+
+	if err := validateTier(tier); err != nil {
+		return nil, err
+	}
+
+	if err := s.vehiclePriceTierRepo.Create(ctx, tier); err != nil {
+		return nil, err
+	}
+
+	return s.toTierResponse(tier), nil
+}
+
+func (s *PricingService) UpdateVehicleTier(ctx context.Context, id uint, req dto.UpdateVehiclePriceTierRequest) (*dto.VehiclePriceTierResponse, error) {
+	tier, err := s.vehiclePriceTierRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.VehicleType != nil {
+		tier.VehicleType = *req.VehicleType
+	}
+	// Like pricing rules, a tier targets either a product or a category
+	if req.ProductID != nil && req.CategoryID != nil {
+		return nil, errors.New("a vehicle price tier cannot target both a product and a category")
+	}
+	if req.ProductID != nil {
+		tier.ProductID = req.ProductID
+		tier.CategoryID = nil
+	}
+	if req.CategoryID != nil {
+		tier.CategoryID = req.CategoryID
+		tier.ProductID = nil
+	}
+	if req.AdjustmentType != nil {
+		tier.AdjustmentType = models.PricingAdjustmentType(*req.AdjustmentType)
+	}
+	if req.AdjustmentValue != nil {
+		tier.AdjustmentValue = *req.AdjustmentValue
+	}
+	if req.IsActive != nil {
+		tier.IsActive = *req.IsActive
+	}
+
+	if err := validateTier(tier); err != nil {
+		return nil, err
+	}
+
+	if err := s.vehiclePriceTierRepo.Update(ctx, tier); err != nil {
+		return nil, err
+	}
+
+	return s.toTierResponse(tier), nil
+}
+
+func (s *PricingService) DeleteVehicleTier(ctx context.Context, id uint) error {
+	return s.vehiclePriceTierRepo.Delete(ctx, id)
+}
+
+func (s *PricingService) toTierResponse(tier *models.VehiclePriceTier) *dto.VehiclePriceTierResponse {
+	return &dto.VehiclePriceTierResponse{
+		ID:              tier.ID,
+		VehicleType:     tier.VehicleType,
+		ProductID:       tier.ProductID,
+		CategoryID:      tier.CategoryID,
+		AdjustmentType:  string(tier.AdjustmentType),
+		AdjustmentValue: tier.AdjustmentValue,
+		IsActive:        tier.IsActive,
+		CreatedAt:       tier.CreatedAt,
+		UpdatedAt:       tier.UpdatedAt,
+	}
+}
+
+func (s *PricingService) GetAllPromos(ctx context.Context, page, perPage int) ([]dto.PromoResponse, *dto.PaginationMeta, error) {
+	promos, total, err := s.promoRepo.FindAll(ctx, page, perPage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]dto.PromoResponse, len(promos))
+	for i, promo := range promos {
+		responses[i] = *s.toPromoResponse(&promo)
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+
+	meta := &dto.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return responses, meta, nil
+}
+
+func (s *PricingService) GetPromoByID(ctx context.Context, id uint) (*dto.PromoResponse, error) {
+	promo, err := s.promoRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toPromoResponse(promo), nil
+}
+
+func (s *PricingService) CreatePromo(ctx context.Context, req dto.CreatePromoRequest) (*dto.PromoResponse, error) {
+	promo := &models.Promo{
+		Code:          normalizePromoCode(req.Code),
+		Name:          req.Name,
+		DiscountType:  models.PricingAdjustmentType(req.DiscountType),
+		DiscountValue: req.DiscountValue,
+		MinSubtotal:   req.MinSubtotal,
+		StartsAt:      req.StartsAt,
+		EndsAt:        req.EndsAt,
+		MaxUses:       req.MaxUses,
+		IsActive:      true,
+	}
+	if req.IsActive != nil {
+		promo.IsActive = *req.IsActive
+	}
+
+	if err := validatePromo(promo); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.promoRepo.FindByCode(ctx, promo.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("promo code already exists")
+	}
+
+	if err := s.promoRepo.Create(ctx, promo); err != nil {
+		return nil, err
+	}
+
+	return s.toPromoResponse(promo), nil
+}
+
+func (s *PricingService) UpdatePromo(ctx context.Context, id uint, req dto.UpdatePromoRequest) (*dto.PromoResponse, error) {
+	promo, err := s.promoRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		promo.Name = *req.Name
+	}
+	if req.DiscountType != nil {
+		promo.DiscountType = models.PricingAdjustmentType(*req.DiscountType)
+	}
+	if req.DiscountValue != nil {
+		promo.DiscountValue = *req.DiscountValue
+	}
+	if req.MinSubtotal != nil {
+		promo.MinSubtotal = *req.MinSubtotal
+	}
+	if req.StartsAt != nil {
+		promo.StartsAt = req.StartsAt
+	}
+	if req.EndsAt != nil {
+		promo.EndsAt = req.EndsAt
+	}
+	if req.MaxUses != nil {
+		promo.MaxUses = req.MaxUses
+	}
+	if req.IsActive != nil {
+		promo.IsActive = *req.IsActive
+	}
+
+	if err := validatePromo(promo); err != nil {
+		return nil, err
+	}
+
+	if err := s.promoRepo.Update(ctx, promo); err != nil {
+		return nil, err
+	}
+
+	return s.toPromoResponse(promo), nil
+}
+
+func (s *PricingService) DeletePromo(ctx context.Context, id uint) error {
+	return s.promoRepo.Delete(ctx, id)
+}
+
+func (s *PricingService) toPromoResponse(promo *models.Promo) *dto.PromoResponse {
+	return &dto.PromoResponse{
+		ID:            promo.ID,
+		Code:          promo.Code,
+		Name:          promo.Name,
+		DiscountType:  string(promo.DiscountType),
+		DiscountValue: promo.DiscountValue,
+		MinSubtotal:   promo.MinSubtotal,
+		StartsAt:      promo.StartsAt,
+		EndsAt:        promo.EndsAt,
+		MaxUses:       promo.MaxUses,
+		UsedCount:     promo.UsedCount,
+		IsActive:      promo.IsActive,
+		CreatedAt:     promo.CreatedAt,
+		UpdatedAt:     promo.UpdatedAt,
+	}
+}
+
+// selectRule picks the rule to apply at the given local time. Rules aimed at
+// the product itself win over category rules; within each group the rules
+// are already ordered by priority.
+func selectRule(rules []models.PricingRule, productID uint, local time.Time) *models.PricingRule {
+	var categoryRule *models.PricingRule
+	for i := range rules {
+		rule := &rules[i]
+		if !ruleAppliesAt(rule, local) {
+			continue
+		}
+		if rule.ProductID != nil && *rule.ProductID == productID {
+			return rule
+		}
+		if categoryRule == nil {
+			categoryRule = rule
+		}
+	}
+	return categoryRule
+}
+
+// ruleAppliesAt reports whether the local time falls inside the rule's
+// window. A window whose end is before its start runs past midnight, and the
+// hours after midnight count towards the previous day.
+func ruleAppliesAt(rule *models.PricingRule, local time.Time) bool {
+	start, err := parseClock(rule.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(rule.EndTime)
+	if err != nil {
+		return false
+	}
+
+	days := decodeDaysOfWeek(rule.DaysOfWeek)
+	weekday := int(local.Weekday())
+	minute := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return containsDay(days, weekday) && minute >= start && minute < end
+	}
+	if minute >= start {
+		return containsDay(days, weekday)
+	}
+	if minute < end {
+		return containsDay(days, (weekday+6)%7)
+	}
+	return false
+}
+
+func applyAdjustment(price float64, rule *models.PricingRule) float64 {
+	return adjustPrice(price, rule.AdjustmentType, rule.AdjustmentValue)
+}
+
+func adjustPrice(price float64, adjustmentType models.PricingAdjustmentType, value float64) float64 {
+	adjusted := price
+	switch adjustmentType {
+	case models.AdjustmentPercentage:
+		adjusted = price + price*value/100
+	case models.AdjustmentFixed:
+		adjusted = price + value
+	}
+	if adjusted < 0 {
+		adjusted = 0
+	}
+	return roundAmount(adjusted)
+}
+
+// membershipDiscount returns the discount percentage a customer's membership
+// grants at the given time, along with the membership name.
+func membershipDiscount(customer *models.User, at time.Time) (float64, string) {
+	membership := customer.MembershipType
+	if membership == nil || !membership.IsActive {
+		return 0, ""
+	}
+	if customer.MembershipExpiresAt != nil && customer.MembershipExpiresAt.Before(at) {
+		return 0, ""
+	}
+
+	var benefits membershipBenefits
+	if len(membership.Benefits) == 0 || json.Unmarshal(membership.Benefits, &benefits) != nil {
+		return 0, ""
+	}
+	if benefits.DiscountPercentage <= 0 {
+		return 0, ""
+	}
+	return math.Min(benefits.DiscountPercentage, 100), membership.Name
+}
+
+// promoUsable reports why a promo cannot be used on an order with the given
+// subtotal at the given time, if it cannot.
+func promoUsable(promo *models.Promo, subtotal float64, at time.Time) error {
+	if !promo.IsActive {
+		return errors.New("promo code is not active")
+	}
+	if promo.StartsAt != nil && at.Before(*promo.StartsAt) {
+		return errors.New("promo code is not valid yet")
+	}
+	if promo.EndsAt != nil && !at.Before(*promo.EndsAt) {
+		return errors.New("promo code has expired")
+	}
+	if promo.MaxUses != nil && promo.UsedCount >= *promo.MaxUses {
+		return errors.New("promo code has been used up")
+	}
+	if subtotal < promo.MinSubtotal {
+		return fmt.Errorf("promo code needs a subtotal of at least %.2f", promo.MinSubtotal)
+	}
+	return nil
+}
+
+// promoDiscount is the amount a promo takes off what is left of an order
+// after the membership discount. It never takes off more than that.
+func promoDiscount(promo *models.Promo, amount float64) float64 {
+	discount := promo.DiscountValue
+	if promo.DiscountType == models.AdjustmentPercentage {
+		discount = amount * math.Min(promo.DiscountValue, 100) / 100
+	}
+	return roundAmount(math.Min(discount, amount))
+}
+
+// normalizePromoCode makes promo codes case-insensitive.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func validateRule(rule *models.PricingRule) error {
+	if rule.ProductID == nil && rule.CategoryID == nil {
+		return errors.New("a pricing rule must target a product or a category")
+	}
+	if rule.ProductID != nil && rule.CategoryID != nil {
+		return errors.New("a pricing rule cannot target both a product and a category")
+	}
+	start, err := parseClock(rule.StartTime)
+	if err != nil {
+		return err
+	}
+	end, err := parseClock(rule.EndTime)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return errors.New("start_time and end_time must differ")
+	}
+	if rule.AdjustmentType == models.AdjustmentPercentage && rule.AdjustmentValue < -100 {
+		return errors.New("a percentage discount cannot exceed 100")
+	}
+	return nil
+}
+
+func validateTier(tier *models.VehiclePriceTier) error {
+	if tier.VehicleType == "" {
+		return errors.New("a vehicle price tier needs a vehicle type")
+	}
+	if tier.ProductID == nil && tier.CategoryID == nil {
+		return errors.New("a vehicle price tier must target a product or a category")
+	}
+	if tier.ProductID != nil && tier.CategoryID != nil {
+		return errors.New("a vehicle price tier cannot target both a product and a category")
+	}
+	if tier.AdjustmentType == models.AdjustmentPercentage && tier.AdjustmentValue < -100 {
+		return errors.New("a percentage discount cannot exceed 100")
+	}
+	return nil
+}
+
+func validatePromo(promo *models.Promo) error {
+	if promo.Code == "" {
+		return errors.New("a promo needs a code")
+	}
+	if promo.DiscountType == models.AdjustmentPercentage && promo.DiscountValue > 100 {
+		return errors.New("a percentage discount cannot exceed 100")
+	}
+	if promo.StartsAt != nil && promo.EndsAt != nil && !promo.EndsAt.After(*promo.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// parseClock converts an "HH:MM" wall-clock time to minutes after midnight.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func encodeDaysOfWeek(days []int) string {
+	unique := make(map[int]bool)
+	for _, day := range days {
+		unique[day] = true
+	}
+
+	sorted := make([]int, 0, len(unique))
+	for day := range unique {
+		sorted = append(sorted, day)
+	}
+	sort.Ints(sorted)
+
+	parts := make([]string, len(sorted))
+	for i, day := range sorted {
+		parts[i] = strconv.Itoa(day)
+	}
+	return strings.Join(parts, ",")
+}
+
+func decodeDaysOfWeek(value string) []int {
+	days := []int{}
+	for _, part := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(strings.TrimSpace(part))
+		if err == nil {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func containsDay(days []int, day int) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// newCustomerVehicle registers a vehicle of the given type to a customer.
+func (e *testEnv) newCustomerVehicle(t *testing.T, customer *models.User, vehicleType string) *models.CustomerVehicle {
+	t.Helper()
+	vehicle := &models.Vehicle{Brand: "Toyota", Model: "Fortuner", VehicleType: vehicleType}
+	e.create(t, vehicle)
+	customerVehicle := &models.CustomerVehicle{CustomerID: customer.ID, VehicleID: vehicle.ID, LicensePlate: "B 1234 XYZ"}
+	e.create(t, customerVehicle)
+	return customerVehicle
+}
+
+func TestQuoteVehiclePriceTier(t *testing.T) {
+	env := newTestEnv(t)
+	product := env.newProduct(t, 50000)
+	customer := env.newUser(t, models.RoleCustomer)
+	suv := env.newCustomerVehicle(t, customer, "suv")
+	ctx := context.Background()
+
+	// The product's own tier wins over its category's
+	categoryTier := &models.VehiclePriceTier{VehicleType: "suv", CategoryID: &product.CategoryID, AdjustmentType: models.AdjustmentPercentage, AdjustmentValue: 20, IsActive: true}
+	productTier := &models.VehiclePriceTier{VehicleType: "suv", ProductID: &product.ID, AdjustmentType: models.AdjustmentFixed, AdjustmentValue: 5000, IsActive: true}
+	sedanTier := &models.VehiclePriceTier{VehicleType: "sedan", ProductID: &product.ID, AdjustmentType: models.AdjustmentFixed, AdjustmentValue: -5000, IsActive: true}
+	env.create(t, categoryTier, productTier, sedanTier)
+
+	items := []dto.CreateWorkOrderItemRequest{{ProductID: product.ID, Quantity: 2}}
+
+	quote, err := env.pricing.Quote(ctx, QuoteInput{CustomerUserID: &customer.ID, Items: items}, time.Now())
+	if err != nil {
+		t.Fatalf("quote without vehicle: %v", err)
+	}
+	if quote.Lines[0].UnitPrice != 50000 || quote.Lines[0].Tier != nil {
+		t.Errorf("without a vehicle the unit price is %.2f, want the list price 50000", quote.Lines[0].UnitPrice)
+	}
+
+	quote, err = env.pricing.Quote(ctx, QuoteInput{CustomerUserID: &customer.ID, CustomerVehicleID: &suv.ID, Items: items}, time.Now())
+	if err != nil {
+		t.Fatalf("quote for the SUV: %v", err)
+	}
+	line := quote.Lines[0]
+	if line.Tier == nil || line.Tier.ID != productTier.ID {
+		t.Fatalf("SUV line priced with tier %v, want the product tier %d", line.Tier, productTier.ID)
+	}
+	if line.BasePrice != 50000 || line.UnitPrice != 55000 || line.Subtotal != 110000 {
+		t.Errorf("SUV line is %.2f base, %.2f unit, %.2f subtotal; want 50000, 55000, 110000", line.BasePrice, line.UnitPrice, line.Subtotal)
+	}
+	if quote.VehicleType != "suv" || quote.TotalAmount != 122100 {
+		t.Errorf("SUV quote is %.2f for %q, want 122100 for suv", quote.TotalAmount, quote.VehicleType)
+	}
+
+	// Somebody else's vehicle cannot be used to price the order
+	other := env.newUser(t, models.RoleCustomer)
+	if _, err := env.pricing.Quote(ctx, QuoteInput{CustomerUserID: &other.ID, CustomerVehicleID: &suv.ID, Items: items}, time.Now()); err == nil {
+		t.Errorf("quote with another customer's vehicle was accepted")
+	}
+}
+
+func TestQuotePromo(t *testing.T) {
+	env := newTestEnv(t)
+	product := env.newProduct(t, 100000)
+	cheap := env.newProduct(t, 40000)
+	ctx := context.Background()
+
+	maxUses := 1
+	promo := &models.Promo{Code: "WASH10", Name: "Ten off", DiscountType: models.AdjustmentPercentage, DiscountValue: 10, MinSubtotal: 50000, MaxUses: &maxUses, IsActive: true}
+	env.create(t, promo)
+
+	code := " wash10 "
+	input := QuoteInput{PromoCode: &code, Items: []dto.CreateWorkOrderItemRequest{{ProductID: product.ID, Quantity: 1}}}
+	quote, err := env.pricing.Quote(ctx, input, time.Now())
+	if err != nil {
+		t.Fatalf("quote with promo: %v", err)
+	}
+	if quote.Promo == nil || quote.Promo.ID != promo.ID {
+		t.Fatalf("quote did not apply promo %d", promo.ID)
+	}
+	// 10% off 100000, then 11% tax on 90000
+	if quote.PromoDiscountAmount != 10000 || quote.DiscountAmount != 10000 || quote.TaxAmount != 9900 || quote.TotalAmount != 99900 {
+		t.Errorf("quote is %.2f promo, %.2f discount, %.2f tax, %.2f total; want 10000, 10000, 9900, 99900",
+			quote.PromoDiscountAmount, quote.DiscountAmount, quote.TaxAmount, quote.TotalAmount)
+	}
+
+	below := QuoteInput{PromoCode: &code, Items: []dto.CreateWorkOrderItemRequest{{ProductID: cheap.ID, Quantity: 1}}}
+	if _, err := env.pricing.Quote(ctx, below, time.Now()); err == nil {
+		t.Errorf("promo was applied below its minimum subtotal")
+	}
+
+	err = env.db.Transaction(func(tx *gorm.DB) error {
+		return env.pricing.RedeemPromo(ctx, tx, quote)
+	})
+	if err != nil {
+		t.Fatalf("redeem promo: %v", err)
+	}
+	env.reload(t, promo, promo.ID)
+	if promo.UsedCount != 1 {
+		t.Errorf("promo used %d times after redeeming it, want 1", promo.UsedCount)
+	}
+
+	// The only use is gone, for new quotes and for orders quoted before
+	if _, err := env.pricing.Quote(ctx, input, time.Now()); err == nil {
+		t.Errorf("used up promo was applied to a new quote")
+	}
+	err = env.db.Transaction(func(tx *gorm.DB) error {
+		return env.pricing.RedeemPromo(ctx, tx, quote)
+	})
+	if err == nil {
+		t.Errorf("used up promo was redeemed again")
+	}
+}
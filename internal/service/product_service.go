@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PriceStatusScheduled = "scheduled"
+	PriceStatusCurrent   = "current"
+	PriceStatusPast      = "past"
+)
+
+type ProductService struct {
+	productRepo      *repository.ProductRepository
+	categoryRepo     *repository.ProductCategoryRepository
+	productPriceRepo *repository.ProductPriceRepository
+	db               *gorm.DB
+}
+
+func NewProductService(
+	productRepo *repository.ProductRepository,
+	categoryRepo *repository.ProductCategoryRepository,
+	productPriceRepo *repository.ProductPriceRepository,
+	db *gorm.DB,
+) *ProductService {
+	return &ProductService{
+		productRepo:      productRepo,
+		categoryRepo:     categoryRepo,
+		productPriceRepo: productPriceRepo,
+		db:               db,
+	}
+}
+
+func (s *ProductService) GetPriceHistory(ctx context.Context, productID uint) ([]dto.ProductPriceResponse, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	prices, err := s.productPriceRepo.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prices are ordered newest first, so the first entry that is already
+	// effective is the current one and everything after it is history.
+	now := time.Now()
+	currentFound := false
+	responses := make([]dto.ProductPriceResponse, len(prices))
+	for i, price := range prices {
+		status := PriceStatusPast
+		if price.EffectiveFrom.After(now) {
+			status = PriceStatusScheduled
+		} else if !currentFound {
+			status = PriceStatusCurrent
+			currentFound = true
+		}
+		responses[i] = *s.toPriceResponse(&price, status)
+	}
+
+	return responses, nil
+}
+
+// SchedulePriceChange records a new price for a product. Without an
+// effective_from, or with one that is not in the future, the change applies
+// immediately; otherwise it takes effect once that time is reached. The
+// price in effect is always read from the history, see
+// PricingService.PriceProduct.
+func (s *ProductService) SchedulePriceChange(ctx context.Context, productID uint, req dto.SchedulePriceChangeRequest, userID *uint) (*dto.ProductPriceResponse, error) {
+	now := time.Now()
+	effectiveFrom := now
+	if req.EffectiveFrom != nil {
+		t, err := time.Parse(time.RFC3339, *req.EffectiveFrom)
+		if err != nil {
+			return nil, errors.New("effective_from must be an RFC3339 timestamp")
+		}
+		// Timestamps are given to the second, so the current second still
+		// counts as now
+		if t.Before(now.Truncate(time.Second)) {
+			return nil, errors.New("effective_from cannot be in the past")
+		}
+		if t.After(now) {
+			effectiveFrom = t
+		}
+	}
+
+	price := &models.ProductPrice{
+		ProductID:       productID,
+		Price:           req.Price,
+		EffectiveFrom:   effectiveFrom,
+		CreatedByUserID: userID,
+		Note:            req.Note,
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The product is locked so concurrent first changes record a
+		// single baseline
+		product, err := s.productRepo.WithTx(tx).FindByIDForUpdate(ctx, productID)
+		if err != nil {
+			return errors.New("product not found")
+		}
+
+		priceRepo := s.productPriceRepo.WithTx(tx)
+		count, err := priceRepo.CountByProduct(ctx, productID)
+		if err != nil {
+			return err
+		}
+
+		// Products created before price history existed get their current
+		// price recorded first so older orders can still be explained.
+		if count == 0 {
+			note := "Initial price"
+			baseline := &models.ProductPrice{
+				ProductID:     productID,
+				Price:         product.Price,
+				EffectiveFrom: product.CreatedAt,
+				Note:          &note,
+			}
+			if err := tx.Create(baseline).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(price).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	status := PriceStatusCurrent
+	if effectiveFrom.After(now) {
+		status = PriceStatusScheduled
+	}
+	return s.toPriceResponse(price, status), nil
+}
+
+// CancelPriceChange removes a price change that has not taken effect yet.
+func (s *ProductService) CancelPriceChange(ctx context.Context, productID, priceID uint) error {
+	price, err := s.productPriceRepo.FindByID(ctx, priceID)
+	if err != nil || price.ProductID != productID {
+		return errors.New("price change not found")
+	}
+
+	if !price.EffectiveFrom.After(time.Now()) {
+		return errors.New("only scheduled price changes can be cancelled")
+	}
+
+	return s.productPriceRepo.Delete(ctx, priceID)
+}
+
+func (s *ProductService) toPriceResponse(price *models.ProductPrice, status string) *dto.ProductPriceResponse {
+	return &dto.ProductPriceResponse{
+		ID:              price.ID,
+		ProductID:       price.ProductID,
+		Price:           price.Price,
+		EffectiveFrom:   price.EffectiveFrom,
+		Status:          status,
+		CreatedByUserID: price.CreatedByUserID,
+		Note:            price.Note,
+		CreatedAt:       price.CreatedAt,
+	}
+}
+
+func (s *ProductService) GetCategories(ctx context.Context) ([]models.ProductCategory, error) {
+	var categories []models.ProductCategory
+	err := s.categoryRepo.DB().WithContext(ctx).Order("name ASC").Find(&categories).Error
+	return categories, err
+}
+
+func (s *ProductService) UpdateCategory(ctx context.Context, id uint, req dto.UpdateProductCategoryRequest) (*models.ProductCategory, error) {
+	category, err := s.categoryRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		category.Name = *req.Name
+	}
+	if req.IconImage != nil {
+		category.IconImage = req.IconImage
+	}
+	if req.IsActive != nil {
+		category.IsActive = *req.IsActive
+	}
+	if req.MinDepositPercentage != nil {
+		category.MinDepositPercentage = *req.MinDepositPercentage
+	}
+
+	if err := s.categoryRepo.Update(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"flashlight-go/internal/dto"
+)
+
+func TestSchedulePriceChange(t *testing.T) {
+	env := newTestEnv(t)
+	product := env.newProduct(t, 50000)
+	ctx := context.Background()
+
+	later := time.Now().Add(time.Hour).Format(time.RFC3339)
+	scheduled, err := env.products.SchedulePriceChange(ctx, product.ID, dto.SchedulePriceChangeRequest{Price: 60000, EffectiveFrom: &later}, nil)
+	if err != nil {
+		t.Fatalf("scheduled change: %v", err)
+	}
+	if scheduled.Status != PriceStatusScheduled {
+		t.Errorf("change an hour ahead is %s, want %s", scheduled.Status, PriceStatusScheduled)
+	}
+
+	// The current second is not in the past
+	now := time.Now().Format(time.RFC3339)
+	immediate, err := env.products.SchedulePriceChange(ctx, product.ID, dto.SchedulePriceChangeRequest{Price: 55000, EffectiveFrom: &now}, nil)
+	if err != nil {
+		t.Fatalf("change effective now: %v", err)
+	}
+	if immediate.Status != PriceStatusCurrent {
+		t.Errorf("change effective now is %s, want %s", immediate.Status, PriceStatusCurrent)
+	}
+
+	past := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	if _, err := env.products.SchedulePriceChange(ctx, product.ID, dto.SchedulePriceChangeRequest{Price: 1000, EffectiveFrom: &past}, nil); err == nil {
+		t.Errorf("change effective a minute ago was accepted")
+	}
+
+	for _, tc := range []struct {
+		at   time.Time
+		want float64
+	}{
+		{time.Now(), 55000},
+		{time.Now().Add(2 * time.Hour), 60000},
+	} {
+		priced, err := env.pricing.PriceProduct(ctx, product, tc.at)
+		if err != nil {
+			t.Fatalf("PriceProduct: %v", err)
+		}
+		if priced.BasePrice != tc.want {
+			t.Errorf("price at %s is %.2f, want %.2f", tc.at.Format(time.Kitchen), priced.BasePrice, tc.want)
+		}
+	}
+
+	history, err := env.products.GetPriceHistory(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("GetPriceHistory: %v", err)
+	}
+	baselines := 0
+	for _, price := range history {
+		if price.Note != nil && *price.Note == "Initial price" {
+			baselines++
+		}
+	}
+	if len(history) != 3 || baselines != 1 {
+		t.Errorf("history has %d entries with %d baselines, want 3 with 1", len(history), baselines)
+	}
+}
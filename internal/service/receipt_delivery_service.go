@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strconv"
+	"time"
+
+	mailer "flashlight-go/internal/mail"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/receipt"
+	"flashlight-go/internal/repository"
+)
+
+// receiptMailTimeout bounds how long sending one e-receipt may take.
+const receiptMailTimeout = 30 * time.Second
+
+type ReceiptDeliveryService struct {
+	deliveryRepo   *repository.ReceiptDeliveryRepository
+	workOrderRepo  *repository.WorkOrderRepository
+	receiptService *ReceiptService
+	transport      mailer.Transport
+	renderer       *mailer.Renderer
+	from           mail.Address
+	autoSend       bool
+}
+
+func NewReceiptDeliveryService(
+	deliveryRepo *repository.ReceiptDeliveryRepository,
+	workOrderRepo *repository.WorkOrderRepository,
+	receiptService *ReceiptService,
+	transport mailer.Transport,
+	from mail.Address,
+	autoSend bool,
+) (*ReceiptDeliveryService, error) {
+	renderer, err := mailer.NewRenderer(map[string]any{
+		"amount": receipt.FormatAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReceiptDeliveryService{
+		deliveryRepo:   deliveryRepo,
+		workOrderRepo:  workOrderRepo,
+		receiptService: receiptService,
+		transport:      transport,
+		renderer:       renderer,
+		from:           from,
+		autoSend:       autoSend,
+	}, nil
+}
+
+// SendForCompletedOrder emails the e-receipt of a work order that has just
+// been paid in full to its customer. It returns immediately; the mail is
+// sent in the background and the outcome is only visible in the delivery
+// log. Orders without a customer email are skipped.
+func (s *ReceiptDeliveryService) SendForCompletedOrder(workOrderID uint) {
+	if !s.autoSend {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), receiptMailTimeout)
+		defer cancel()
+
+		workOrder, err := s.workOrderRepo.FindWithItems(ctx, workOrderID)
+		if err != nil {
+			log.Printf("Failed to load work order %d for e-receipt: %v", workOrderID, err)
+			return
+		}
+		if workOrder.CustomerUser == nil || workOrder.CustomerUser.Email == "" {
+			return
+		}
+
+		if _, err := s.send(ctx, workOrderID, workOrder.CustomerUser.Email, models.ReceiptTriggerAuto, nil); err != nil {
+			log.Printf("Failed to send e-receipt for work order %d: %v", workOrderID, err)
+		}
+	}()
+}
+
+// Resend emails the e-receipt of a work order again, to the given address
+// or else to the customer's email. The attempt is logged even when it fails.
+func (s *ReceiptDeliveryService) Resend(ctx context.Context, workOrderID uint, email *string, requestedByUserID *uint) (*models.ReceiptDelivery, error) {
+	workOrder, err := s.workOrderRepo.FindWithItems(ctx, workOrderID)
+	if err != nil {
+		return nil, errors.New("work order not found")
+	}
+
+	var recipient string
+	switch {
+	case email != nil && *email != "":
+		recipient = *email
+	case workOrder.CustomerUser != nil && workOrder.CustomerUser.Email != "":
+		recipient = workOrder.CustomerUser.Email
+	default:
+		return nil, errors.New("work order has no customer email, provide an email address")
+	}
+
+	return s.send(ctx, workOrderID, recipient, models.ReceiptTriggerResend, requestedByUserID)
+}
+
+func (s *ReceiptDeliveryService) GetByWorkOrder(ctx context.Context, workOrderID uint) ([]models.ReceiptDelivery, error) {
+	return s.deliveryRepo.FindByWorkOrder(ctx, workOrderID)
+}
+
+// send renders and mails the receipt, then records the attempt.
+func (s *ReceiptDeliveryService) send(ctx context.Context, workOrderID uint, recipient string, trigger models.ReceiptDeliveryTrigger, requestedByUserID *uint) (*models.ReceiptDelivery, error) {
+	delivery := &models.ReceiptDelivery{
+		WorkOrderID:       workOrderID,
+		Channel:           "email",
+		Recipient:         recipient,
+		Trigger:           trigger,
+		RequestedByUserID: requestedByUserID,
+	}
+
+	sendErr := s.deliver(ctx, workOrderID, recipient)
+	if sendErr != nil {
+		message := sendErr.Error()
+		delivery.Status = models.ReceiptDeliveryFailed
+		delivery.Error = &message
+	} else {
+		now := time.Now()
+		delivery.Status = models.ReceiptDeliverySent
+		delivery.SentAt = &now
+	}
+
+	if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+		return nil, err
+	}
+	if sendErr != nil {
+		return delivery, fmt.Errorf("failed to send e-receipt: %w", sendErr)
+	}
+	return delivery, nil
+}
+
+func (s *ReceiptDeliveryService) deliver(ctx context.Context, workOrderID uint, recipient string) error {
+	r, err := s.receiptService.Build(ctx, workOrderID)
+	if err != nil {
+		return err
+	}
+
+	pdf, err := receipt.PDF(r, receipt.PaperA4)
+	if err != nil {
+		return err
+	}
+
+	customerName := r.Customer
+	if customerName == "" {
+		customerName = "Pelanggan"
+	}
+	text, html, err := s.renderer.Render("receipt", map[string]any{
+		"Receipt":      r,
+		"CustomerName": customerName,
+		"TaxRate":      strconv.FormatFloat(r.TaxRate, 'f', -1, 64),
+	})
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Struk %s", r.OrderNumber)
+	if r.Header.OutletName != "" {
+		subject = fmt.Sprintf("Struk %s - %s", r.OrderNumber, r.Header.OutletName)
+	}
+
+	return s.transport.Send(ctx, &mailer.Message{
+		From:    s.from,
+		To:      []string{recipient},
+		Subject: subject,
+		Text:    text,
+		HTML:    html,
+		Attachments: []mailer.Attachment{{
+			Filename:    fmt.Sprintf("receipt-%s.pdf", r.OrderNumber),
+			ContentType: "application/pdf",
+			Data:        pdf,
+		}},
+	})
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"flashlight-go/internal/models"
+)
+
+func TestReceiptDeliveryLog(t *testing.T) {
+	env := newTestEnv(t)
+	owner := env.newUser(t, models.RoleOwner)
+	order := env.newPaidOrder(t, owner, env.newShift(t, owner))
+	ctx := context.Background()
+
+	delivery, err := env.receipts.Resend(ctx, order.workOrder.ID, nil, &owner.ID)
+	if err != nil {
+		t.Fatalf("Resend: %v", err)
+	}
+	if delivery.Status != models.ReceiptDeliverySent || delivery.SentAt == nil || delivery.Recipient != order.customer.Email {
+		t.Errorf("got delivery %s to %s, want sent to %s", delivery.Status, delivery.Recipient, order.customer.Email)
+	}
+
+	if len(env.mail.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(env.mail.sent))
+	}
+	msg := env.mail.sent[0]
+	if len(msg.To) != 1 || msg.To[0] != order.customer.Email {
+		t.Errorf("mailed to %v, want %s", msg.To, order.customer.Email)
+	}
+	if !strings.Contains(msg.Subject, order.workOrder.OrderNumber) {
+		t.Errorf("subject %q does not name the order", msg.Subject)
+	}
+	if msg.Text == "" || msg.HTML == "" {
+		t.Errorf("message needs both a text and an HTML body")
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].ContentType != "application/pdf" || !strings.HasPrefix(string(msg.Attachments[0].Data), "%PDF") {
+		t.Errorf("message needs the receipt attached as a PDF")
+	}
+
+	// A failed attempt is logged with the transport's error
+	env.mail.err = errors.New("421 service not available")
+	address := "other@example.com"
+	delivery, err = env.receipts.Resend(ctx, order.workOrder.ID, &address, &owner.ID)
+	if err == nil {
+		t.Fatalf("Resend succeeded with a failing transport")
+	}
+	if delivery == nil || delivery.Status != models.ReceiptDeliveryFailed || delivery.Error == nil || !strings.Contains(*delivery.Error, "421") {
+		t.Errorf("got delivery %+v, want it failed with the transport error", delivery)
+	}
+
+	deliveries, err := env.receipts.GetByWorkOrder(ctx, order.workOrder.ID)
+	if err != nil {
+		t.Fatalf("GetByWorkOrder: %v", err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("logged %d deliveries, want 2", len(deliveries))
+	}
+	for _, delivery := range deliveries {
+		if delivery.Trigger != models.ReceiptTriggerResend || delivery.RequestedByUserID == nil || *delivery.RequestedByUserID != owner.ID {
+			t.Errorf("delivery %d is %s by %v, want a resend by %d", delivery.ID, delivery.Trigger, delivery.RequestedByUserID, owner.ID)
+		}
+	}
+}
+
+func TestReceiptDeliveryWithoutEmail(t *testing.T) {
+	env := newTestEnv(t)
+	workOrder := env.newWorkOrder(t, 50000)
+
+	if _, err := env.receipts.Resend(context.Background(), workOrder.ID, nil, nil); err == nil {
+		t.Errorf("Resend without any email succeeded")
+	}
+	if len(env.mail.sent) != 0 {
+		t.Errorf("sent %d messages, want none", len(env.mail.sent))
+	}
+	deliveries, err := env.receipts.GetByWorkOrder(context.Background(), workOrder.ID)
+	if err != nil || len(deliveries) != 0 {
+		t.Errorf("got deliveries %v (%v), want none logged", deliveries, err)
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/receipt"
+	"flashlight-go/internal/repository"
+)
+
+type ReceiptService struct {
+	workOrderRepo *repository.WorkOrderRepository
+	header        receipt.Header
+	thermalPaper  string
+	qrURL         string
+	location      *time.Location
+}
+
+func NewReceiptService(
+	workOrderRepo *repository.WorkOrderRepository,
+	header receipt.Header,
+	thermalPaper string,
+	qrURL string,
+	location *time.Location,
+) *ReceiptService {
+	if thermalPaper != receipt.Paper58mm {
+		thermalPaper = receipt.Paper80mm
+	}
+
+	return &ReceiptService{
+		workOrderRepo: workOrderRepo,
+		header:        header,
+		thermalPaper:  thermalPaper,
+		qrURL:         qrURL,
+		location:      location,
+	}
+}
+
+// ESCPOS renders the receipt of a work order for a thermal printer. An empty
+// paper size uses the outlet's configured printer width. The cash drawer is
+// only kicked when asked to and the order was paid at least partly in cash.
+func (s *ReceiptService) ESCPOS(ctx context.Context, workOrderID uint, paper string, openDrawer bool) ([]byte, error) {
+	r, err := s.Build(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if paper == "" {
+		paper = s.thermalPaper
+	}
+	r.OpenDrawer = r.OpenDrawer && openDrawer
+	return receipt.ESCPOS(r, paper)
+}
+
+// PDF renders the receipt of a work order as a PDF, on A4 unless another
+// paper size is given.
+func (s *ReceiptService) PDF(ctx context.Context, workOrderID uint, paper string) ([]byte, error) {
+	r, err := s.Build(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if paper == "" {
+		paper = receipt.PaperA4
+	}
+	return receipt.PDF(r, paper)
+}
+
+// Build collects everything printed on a work order's receipt from the item
+// snapshots and its completed payments.
+func (s *ReceiptService) Build(ctx context.Context, workOrderID uint) (*receipt.Receipt, error) {
+	workOrder, err := s.workOrderRepo.FindWithItems(ctx, workOrderID)
+	if err != nil {
+		return nil, errors.New("work order not found")
+	}
+
+	r := &receipt.Receipt{
+		Header:         s.header,
+		OrderNumber:    workOrder.OrderNumber,
+		QueueNumber:    workOrder.QueueNumber,
+		IssuedAt:       time.Now().In(s.location),
+		Subtotal:       workOrder.Subtotal,
+		DiscountAmount: workOrder.DiscountAmount,
+		TaxAmount:      workOrder.TaxAmount,
+		TotalAmount:    workOrder.TotalAmount,
+		QRContent:      workOrder.OrderNumber,
+	}
+
+	if s.qrURL != "" {
+		r.QRContent = strings.ReplaceAll(s.qrURL, "%s", workOrder.OrderNumber)
+	}
+	if workOrder.CashierUser != nil {
+		r.Cashier = workOrder.CashierUser.Name
+	}
+	if workOrder.CustomerUser != nil {
+		r.Customer = workOrder.CustomerUser.Name
+	}
+	if workOrder.CustomerVehicle != nil {
+		r.Vehicle = workOrder.CustomerVehicle.LicensePlate
+	}
+
+	// The rate is taken from the order's own amounts, so receipts reprinted
+	// after a tax change still match what was charged
+	if taxBase := r.TaxBase(); workOrder.TaxAmount > 0 && taxBase > 0 {
+		r.TaxRate = math.Round(workOrder.TaxAmount/taxBase*10000) / 100
+	}
+
+	for _, item := range workOrder.Items {
+		line := receipt.Line{
+			Name:      item.ProductNameSnapshot,
+			Quantity:  item.Quantity,
+			UnitPrice: item.PriceSnapshot,
+			Subtotal:  item.Subtotal,
+		}
+		if item.ItemNote != nil {
+			line.Note = *item.ItemNote
+		}
+		r.Lines = append(r.Lines, line)
+	}
+
+	payments := make([]models.Payment, 0, len(workOrder.Payments))
+	for _, payment := range workOrder.Payments {
+		if payment.Status == models.PaymentStatusCompleted {
+			payments = append(payments, payment)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].ID < payments[j].ID })
+
+	for _, payment := range payments {
+		r.Tenders = append(r.Tenders, receipt.Tender{
+			Method:        string(payment.Method),
+			PaymentNumber: payment.PaymentNumber,
+			Amount:        payment.AmountPaid,
+			Change:        payment.ChangeAmount,
+			Tip:           payment.TipAmount,
+		})
+		r.ChangeAmount += payment.ChangeAmount
+		r.TipAmount += payment.TipAmount
+		r.RefundedAmount += payment.RefundedAmount
+		if payment.Method == models.MethodCash {
+			r.OpenDrawer = true
+		}
+		if payment.PaidAt != nil {
+			r.IssuedAt = payment.PaidAt.In(s.location)
+		}
+	}
+
+	r.PaidAmount = paidAmount(payments)
+	r.ChangeAmount = roundAmount(r.ChangeAmount)
+	r.TipAmount = roundAmount(r.TipAmount)
+	r.RefundedAmount = roundAmount(r.RefundedAmount)
+	if outstanding := roundAmount(r.TotalAmount - r.PaidAmount + r.RefundedAmount); outstanding > 0 {
+		r.OutstandingAmount = outstanding
+	}
+
+	return r, nil
+}
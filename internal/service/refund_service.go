@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type RefundService struct {
+	refundRepo     *repository.RefundRepository
+	paymentRepo    *repository.PaymentRepository
+	shiftRepo      *repository.ShiftRepository
+	workOrderRepo  *repository.WorkOrderRepository
+	walletService  *WalletService
+	loyaltyService *LoyaltyService
+	paymentMethods *PaymentMethodService
+	db             *gorm.DB
+}
+
+func NewRefundService(
+	refundRepo *repository.RefundRepository,
+	paymentRepo *repository.PaymentRepository,
+	shiftRepo *repository.ShiftRepository,
+	workOrderRepo *repository.WorkOrderRepository,
+	walletService *WalletService,
+	loyaltyService *LoyaltyService,
+	paymentMethods *PaymentMethodService,
+	db *gorm.DB,
+) *RefundService {
+	return &RefundService{
+		refundRepo:     refundRepo,
+		paymentRepo:    paymentRepo,
+		shiftRepo:      shiftRepo,
+		workOrderRepo:  workOrderRepo,
+		walletService:  walletService,
+		loyaltyService: loyaltyService,
+		paymentMethods: paymentMethods,
+		db:             db,
+	}
+}
+
+// Create refunds part or all of a completed payment, or of a payment
+// flagged for a refund because it came in after it had failed, approved by
+// the given user. Refunds are accounted to the approver's own active shift;
+// cash refunds come out of its drawer, wallet refunds are credited to the
+// customer's wallet. The work order stays paid, and the loyalty points it
+// earned are taken back in proportion to what is refunded.
+func (s *RefundService) Create(ctx context.Context, paymentID uint, req dto.CreateRefundRequest, approverUserID *uint) (*models.Refund, error) {
+	// Money can go back by a method that has since been disabled
+	setting, err := s.paymentMethods.Find(ctx, req.Method)
+	if err != nil {
+		return nil, err
+	}
+	method := setting.Code
+	amount := roundAmount(req.Amount)
+
+	var refund *models.Refund
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		shiftID, err := s.approverShift(ctx, tx, method, approverUserID)
+		if err != nil {
+			return err
+		}
+
+		paymentRepo := s.paymentRepo.WithTx(tx)
+		payment, err := paymentRepo.FindByIDForUpdate(ctx, paymentID)
+		if err != nil {
+			return errors.New("payment not found")
+		}
+		// A payment that came in after it had failed, with the balance
+		// already settled otherwise, is refunded in full
+		late := payment.Status == models.PaymentStatusFailed && payment.RefundRequired
+		if payment.Status != models.PaymentStatusCompleted && !late {
+			return fmt.Errorf("cannot refund a %s payment", payment.Status)
+		}
+
+		// An on-account charge is refunded by taking it off the account
+		// before it is invoiced; no money changes hands
+		if (payment.FleetAccountID != nil) != (method == models.MethodAccount) {
+			return errors.New("on-account charges can only be refunded to the account")
+		}
+		if payment.FleetAccountID != nil && payment.InvoiceID != nil {
+			return errors.New("payment has been invoiced to its fleet account and cannot be refunded")
+		}
+
+		// Change handed back never counts as paid. A late payment's tip was
+		// never split, so it goes back too.
+		refundable := roundAmount(payment.AmountPaid - payment.ChangeAmount - payment.RefundedAmount)
+		if late {
+			refundable = roundAmount(refundable + payment.TipAmount)
+		}
+		if amount > refundable {
+			return fmt.Errorf("refund of %.2f exceeds the refundable amount of %.2f", amount, refundable)
+		}
+
+		// What the order still keeps before this refund, for the share of
+		// points it takes back
+		netPaid, err := paymentRepo.GetNetPaidForWorkOrder(ctx, payment.WorkOrderID)
+		if err != nil {
+			return err
+		}
+
+		refundNumber, err := s.refundRepo.WithTx(tx).GenerateRefundNumber(ctx)
+		if err != nil {
+			return err
+		}
+
+		refund = &models.Refund{
+			RefundNumber:     refundNumber,
+			PaymentID:        payment.ID,
+			WorkOrderID:      payment.WorkOrderID,
+			ShiftID:          shiftID,
+			Method:           method,
+			Amount:           amount,
+			Reason:           req.Reason,
+			ReferenceNumber:  req.ReferenceNumber,
+			ApprovedByUserID: approverUserID,
+		}
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+
+		payment.RefundedAmount = roundAmount(payment.RefundedAmount + amount)
+		if late && amount == refundable {
+			payment.RefundRequired = false
+		}
+		if err := tx.Save(payment).Error; err != nil {
+			return err
+		}
+
+		if method == models.MethodWallet {
+			workOrder, err := s.workOrderRepo.WithTx(tx).FindByID(ctx, payment.WorkOrderID)
+			if err != nil {
+				return err
+			}
+			if workOrder.CustomerUserID == nil {
+				return errors.New("wallet refunds require a work order with a customer")
+			}
+			if err := s.walletService.CreditForRefund(ctx, tx, *workOrder.CustomerUserID, amount, payment.ID, approverUserID); err != nil {
+				return err
+			}
+		}
+
+		// A late payment never counted towards the order or its points
+		if late {
+			return nil
+		}
+		return s.loyaltyService.ReverseForRefund(ctx, tx, payment.WorkOrderID, amount, roundAmount(netPaid))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+func (s *RefundService) GetByPayment(ctx context.Context, paymentID uint) ([]models.Refund, error) {
+	return s.refundRepo.FindByPayment(ctx, paymentID)
+}
+
+func (s *RefundService) GetByWorkOrder(ctx context.Context, workOrderID uint) ([]models.Refund, error) {
+	return s.refundRepo.FindByWorkOrder(ctx, workOrderID)
+}
+
+// approverShift returns the shift a refund is accounted to: the approver's
+// own active shift, never another cashier's, which stays locked against
+// closing until the transaction ends. Cash has to leave a drawer, so it
+// needs one; other methods are accounted to the shift when there is one.
+func (s *RefundService) approverShift(ctx context.Context, tx *gorm.DB, method models.PaymentMethod, userID *uint) (*uint, error) {
+	var shift *models.Shift
+	if userID != nil {
+		var err error
+		shift, err = s.shiftRepo.WithTx(tx).FindActiveShiftByUserForShare(ctx, *userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if shift == nil {
+		if method == models.MethodCash {
+			return nil, ErrNoActiveShift
+		}
+		return nil, nil
+	}
+	return &shift.ID, nil
+}
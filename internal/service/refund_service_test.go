@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/gateway"
+	"flashlight-go/internal/models"
+)
+
+// paidOrder is a customer's work order paid in full in cash, which earned
+// 100 points.
+type paidOrder struct {
+	customer  *models.User
+	workOrder *models.WorkOrder
+	payment   *models.Payment
+}
+
+func (e *testEnv) newPaidOrder(t *testing.T, cashier *models.User, shift *models.Shift) *paidOrder {
+	t.Helper()
+	customer := e.newUser(t, models.RoleCustomer)
+	workOrder := e.newWorkOrder(t, 100000)
+	workOrder.CustomerUserID = &customer.ID
+	workOrder.Status = models.StatusCompleted
+	if err := e.db.Save(workOrder).Error; err != nil {
+		t.Fatalf("save work order: %v", err)
+	}
+
+	now := time.Now()
+	payment := &models.Payment{
+		WorkOrderID:   workOrder.ID,
+		CashierUserID: &cashier.ID,
+		ShiftID:       &shift.ID,
+		PaymentNumber: "PAY-1",
+		Method:        models.MethodCash,
+		Status:        models.PaymentStatusCompleted,
+		AmountPaid:    100000,
+		NetAmount:     100000,
+		PaidAt:        &now,
+	}
+	e.create(t, payment, &models.LoyaltyPointEntry{
+		CustomerUserID: customer.ID,
+		EntryType:      models.LoyaltyEntryEarn,
+		Points:         100,
+		BalanceAfter:   100,
+		WorkOrderID:    &workOrder.ID,
+	})
+	return &paidOrder{customer: customer, workOrder: workOrder, payment: payment}
+}
+
+func (e *testEnv) pointsBalance(t *testing.T, customerID uint) int {
+	t.Helper()
+	var balance int
+	err := e.db.Model(&models.LoyaltyPointEntry{}).
+		Where("customer_user_id = ?", customerID).
+		Select("COALESCE(SUM(points), 0)").
+		Scan(&balance).Error
+	if err != nil {
+		t.Fatalf("points balance: %v", err)
+	}
+	return balance
+}
+
+func TestPartialRefundReopensBalance(t *testing.T) {
+	env := newTestEnv(t)
+	owner := env.newUser(t, models.RoleOwner)
+	shift := env.newShift(t, owner)
+	order := env.newPaidOrder(t, owner, shift)
+	ctx := context.Background()
+
+	refund, err := env.refunds.Create(ctx, order.payment.ID, dto.CreateRefundRequest{Amount: 25000, Reason: "test", Method: "cash"}, &owner.ID)
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if refund.ShiftID == nil || *refund.ShiftID != shift.ID {
+		t.Errorf("refund is on shift %v, want the approver's shift %d", refund.ShiftID, shift.ID)
+	}
+
+	// The work was done, so the order stays completed, but the refund is
+	// owed again
+	env.reload(t, order.workOrder, order.workOrder.ID)
+	if order.workOrder.Status != models.StatusCompleted {
+		t.Errorf("work order is %s after a partial refund, want completed", order.workOrder.Status)
+	}
+	balance, err := env.workOrders.GetByID(ctx, order.workOrder.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if balance.PaidAmount != 100000 || balance.RefundedAmount != 25000 || balance.NetPaid != 75000 ||
+		balance.OutstandingAmount != 25000 || balance.PaymentStatus != PaymentStatePartial {
+		t.Errorf("balance is %.2f paid, %.2f refunded, %.2f net, %.2f outstanding (%s); want 100000, 25000, 75000, 25000 (%s)",
+			balance.PaidAmount, balance.RefundedAmount, balance.NetPaid, balance.OutstandingAmount, balance.PaymentStatus, PaymentStatePartial)
+	}
+
+	// Only the refunded part can be paid again
+	_, err = env.payments.Create(ctx, dto.CreatePaymentRequest{WorkOrderID: order.workOrder.ID, Method: "card", AmountPaid: 30000}, &owner.ID)
+	if err == nil {
+		t.Errorf("payment of more than the refunded amount was accepted")
+	}
+
+	// A quarter of the order was refunded
+	if balance := env.pointsBalance(t, order.customer.ID); balance != 75 {
+		t.Errorf("points balance is %d after refunding a quarter, want 75", balance)
+	}
+
+	// Refunding the rest takes back the remaining points
+	if _, err := env.refunds.Create(ctx, order.payment.ID, dto.CreateRefundRequest{Amount: 75000, Reason: "test", Method: "cash"}, &owner.ID); err != nil {
+		t.Fatalf("second refund: %v", err)
+	}
+	if balance := env.pointsBalance(t, order.customer.ID); balance != 0 {
+		t.Errorf("points balance is %d after refunding everything, want 0", balance)
+	}
+}
+
+func TestRefundUsesOnlyApproverShift(t *testing.T) {
+	env := newTestEnv(t)
+	cashier := env.newUser(t, models.RoleCashier)
+	shift := env.newShift(t, cashier)
+	owner := env.newUser(t, models.RoleOwner)
+	order := env.newPaidOrder(t, cashier, shift)
+
+	// The cashier's drawer is open, but the approver has none of their own
+	_, err := env.refunds.Create(context.Background(), order.payment.ID, dto.CreateRefundRequest{Amount: 10000, Reason: "test", Method: "cash"}, &owner.ID)
+	if !errors.Is(err, ErrNoActiveShift) {
+		t.Errorf("got error %v, want %v", err, ErrNoActiveShift)
+	}
+}
+
+func TestRefundLatePayment(t *testing.T) {
+	env := newTestEnv(t)
+	owner := env.newUser(t, models.RoleOwner)
+	shift := env.newShift(t, owner)
+	order := env.newPaidOrder(t, owner, shift)
+	ctx := context.Background()
+
+	// A QRIS code for the order expired and was paid after the order had
+	// been paid in cash
+	late := env.newPendingQRIS(t, order.workOrder, "PAY-2", 100000)
+	env.expireQRIS(t, late)
+	result, err := env.deliver(t, gateway.FakePayload{EventID: "evt-1", PaymentNumber: "PAY-2", TransactionID: "TX-1", Status: "paid", Amount: 100000})
+	if err != nil || result.Status != string(models.WebhookEventRefundRequired) {
+		t.Fatalf("late payment: got %+v, %v, want it flagged for a refund", result, err)
+	}
+
+	if _, err := env.refunds.Create(ctx, late.ID, dto.CreateRefundRequest{Amount: 40000, Reason: "paid twice", Method: "cash"}, &owner.ID); err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+	env.reload(t, late, late.ID)
+	if !late.RefundRequired || late.RefundedAmount != 40000 {
+		t.Errorf("after a partial refund: refund required %v, refunded %.2f, want still required with 40000 refunded", late.RefundRequired, late.RefundedAmount)
+	}
+
+	if _, err := env.refunds.Create(ctx, late.ID, dto.CreateRefundRequest{Amount: 60000, Reason: "paid twice", Method: "cash"}, &owner.ID); err != nil {
+		t.Fatalf("refund of the rest: %v", err)
+	}
+	env.reload(t, late, late.ID)
+	if late.RefundRequired || late.Status != models.PaymentStatusFailed {
+		t.Errorf("after refunding in full: %s with refund required %v, want failed and no longer flagged", late.Status, late.RefundRequired)
+	}
+	if _, err := env.refunds.Create(ctx, late.ID, dto.CreateRefundRequest{Amount: 1000, Reason: "again", Method: "cash"}, &owner.ID); err == nil {
+		t.Errorf("refunded a late payment once it was no longer flagged")
+	}
+
+	// The order and its points are those of the cash payment alone
+	env.reload(t, order.workOrder, order.workOrder.ID)
+	if order.workOrder.Status != models.StatusCompleted {
+		t.Errorf("work order is %s, want completed", order.workOrder.Status)
+	}
+	if balance := env.pointsBalance(t, order.customer.ID); balance != 100 {
+		t.Errorf("points balance is %d, want the 100 earned by the cash payment", balance)
+	}
+}
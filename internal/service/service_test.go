@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"testing"
+	"time"
+
+	"flashlight-go/internal/database"
+	"flashlight-go/internal/gateway"
+	mailer "flashlight-go/internal/mail"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/receipt"
+	"flashlight-go/internal/repository"
+	"flashlight-go/pkg/utils"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testWebhookSecret = "test-secret"
+
+// testDriver is SQLite with no-op stand-ins for the Postgres advisory lock
+// functions the repositories call.
+const testDriver = "sqlite3_flashlight"
+
+func init() {
+	sql.Register(testDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if err := conn.RegisterFunc("hashtext", func(string) int64 { return 0 }, true); err != nil {
+				return err
+			}
+			return conn.RegisterFunc("pg_advisory_xact_lock", func(int64) int64 { return 0 }, false)
+		},
+	})
+}
+
+// testEnv wires the services the way cmd/server does, on an in-memory
+// SQLite database. SQLite ignores row locks, so tests cover the logic of a
+// single request, not concurrency.
+type testEnv struct {
+	db         *gorm.DB
+	workOrders *WorkOrderService
+	payments   *PaymentService
+	refunds    *RefundService
+	webhooks   *PaymentWebhookService
+	receipts   *ReceiptDeliveryService
+	supervisor *SupervisorService
+	products   *ProductService
+	pricing    *PricingService
+	fake       *gateway.FakeAdapter
+	mail       *testTransport
+}
+
+// testTransport keeps the messages it is given, or fails with err.
+type testTransport struct {
+	sent []*mailer.Message
+	err  error
+}
+
+func (t *testTransport) Send(ctx context.Context, msg *mailer.Message) error {
+	if t.err != nil {
+		return t.err
+	}
+	t.sent = append(t.sent, msg)
+	return nil
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	db, err := gorm.Open(&sqlite.Dialector{DriverName: testDriver, DSN: ":memory:"}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	// Every connection to :memory: opens a database of its own
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	location := time.UTC
+	userRepo := repository.NewUserRepository(db)
+	workOrderRepo := repository.NewWorkOrderRepository(db)
+	productRepo := repository.NewProductRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	shiftRepo := repository.NewShiftRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	productPriceRepo := repository.NewProductPriceRepository(db)
+
+	pricingService := NewPricingService(productRepo, productPriceRepo, repository.NewPricingRuleRepository(db), repository.NewVehiclePriceTierRepository(db), repository.NewPromoRepository(db), repository.NewCustomerVehicleRepository(db), userRepo, location, 11)
+	loyaltyService := NewLoyaltyService(repository.NewLoyaltyPointRepository(db), repository.NewLoyaltyEarnRuleRepository(db), repository.NewLoyaltyRewardRepository(db), workOrderRepo, paymentRepo, productRepo, userRepo, pricingService, db, 365)
+	paymentMethodService := NewPaymentMethodService(repository.NewPaymentMethodRepository(db), paymentRepo, refundRepo, "TEST", location)
+	if err := paymentMethodService.EnsureDefaults(context.Background()); err != nil {
+		t.Fatalf("payment methods: %v", err)
+	}
+	walletService := NewWalletService(repository.NewStoredValueRepository(db), repository.NewGiftCardRepository(db), userRepo, shiftRepo, paymentMethodService, db, 365)
+	tipService := NewTipService(repository.NewTipAllocationRepository(db), userRepo, string(models.TipSplitEqual), location)
+	receiptService := NewReceiptService(workOrderRepo, receipt.Header{OutletName: "Test"}, receipt.Paper80mm, "", location)
+	transport := &testTransport{}
+	receiptDelivery, err := NewReceiptDeliveryService(repository.NewReceiptDeliveryRepository(db), workOrderRepo, receiptService, transport, mail.Address{Address: "receipts@example.com"}, false)
+	if err != nil {
+		t.Fatalf("receipt delivery: %v", err)
+	}
+	fleetAccountService := NewFleetAccountService(repository.NewFleetAccountRepository(db), repository.NewCustomerVehicleRepository(db), userRepo, paymentRepo, repository.NewInvoiceRepository(db))
+
+	fake := gateway.NewFakeAdapter(testWebhookSecret)
+	gateways := gateway.NewRegistry()
+	gateways.Register(fake)
+
+	paymentService := NewPaymentService(paymentRepo, workOrderRepo, shiftRepo, walletService, loyaltyService, tipService, receiptDelivery, paymentMethodService, fleetAccountService, utils.QRISMerchant{}, db)
+	return &testEnv{
+		db:         db,
+		workOrders: NewWorkOrderService(workOrderRepo, repository.NewWorkOrderItemRepository(db), productRepo, repository.NewProductCategoryRepository(db), shiftRepo, pricingService, loyaltyService, db),
+		payments:   paymentService,
+		refunds:    NewRefundService(refundRepo, paymentRepo, shiftRepo, workOrderRepo, walletService, loyaltyService, paymentMethodService, db),
+		webhooks:   NewPaymentWebhookService(gateways, paymentRepo, repository.NewPaymentWebhookEventRepository(db), workOrderRepo, paymentService, db),
+		receipts:   receiptDelivery,
+		supervisor: NewSupervisorService(userRepo, repository.NewUsedApprovalTokenRepository(db), db),
+		products:   NewProductService(productRepo, repository.NewProductCategoryRepository(db), productPriceRepo, db),
+		pricing:    pricingService,
+		fake:       fake,
+		mail:       transport,
+	}
+}
+
+// create inserts fixture rows, failing the test on error.
+func (e *testEnv) create(t *testing.T, values ...interface{}) {
+	t.Helper()
+	for _, value := range values {
+		if err := e.db.Create(value).Error; err != nil {
+			t.Fatalf("create %T: %v", value, err)
+		}
+	}
+}
+
+// reload reads a row back from the database into a zeroed value, as NULL
+// columns leave fields that are already set untouched.
+func (e *testEnv) reload(t *testing.T, value interface{}, id uint) {
+	t.Helper()
+	target := reflect.ValueOf(value).Elem()
+	target.Set(reflect.Zero(target.Type()))
+	if err := e.db.First(value, id).Error; err != nil {
+		t.Fatalf("reload %T %d: %v", value, id, err)
+	}
+}
+
+// newWorkOrder creates a walk-in work order without a customer.
+func (e *testEnv) newWorkOrder(t *testing.T, total float64) *models.WorkOrder {
+	t.Helper()
+	workOrder := &models.WorkOrder{
+		OrderNumber: fmt.Sprintf("WO-%d", time.Now().UnixNano()),
+		Source:      models.SourceCashier,
+		Type:        models.TypeService,
+		Status:      models.StatusReady,
+		Subtotal:    total,
+		TotalAmount: total,
+	}
+	e.create(t, workOrder)
+	return workOrder
+}
+
+// newProduct creates a service product with the given list price.
+func (e *testEnv) newProduct(t *testing.T, price float64) *models.Product {
+	t.Helper()
+	category := &models.ProductCategory{Name: "Wash", IsActive: true}
+	e.create(t, category)
+	product := &models.Product{
+		Name:       fmt.Sprintf("Product %d", time.Now().UnixNano()),
+		Price:      price,
+		CategoryID: category.ID,
+		Kind:       models.ProductKindService,
+		IsActive:   true,
+	}
+	e.create(t, product)
+	return product
+}
+
+// newUser creates a user with the given role.
+func (e *testEnv) newUser(t *testing.T, role models.UserRole) *models.User {
+	t.Helper()
+	user := &models.User{
+		Name:     string(role),
+		Email:    fmt.Sprintf("%s-%d@example.com", role, time.Now().UnixNano()),
+		Password: "-",
+		Role:     role,
+		IsActive: true,
+	}
+	e.create(t, user)
+	return user
+}
+
+// newShift opens a shift for a user.
+func (e *testEnv) newShift(t *testing.T, user *models.User) *models.Shift {
+	t.Helper()
+	shift := &models.Shift{
+		UserID:    user.ID,
+		StartTime: time.Now(),
+		Status:    models.ShiftStatusActive,
+	}
+	e.create(t, shift)
+	return shift
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+	"flashlight-go/internal/settlement"
+
+	"gorm.io/gorm"
+)
+
+// ErrSettlementImported is returned when a settlement file is imported a
+// second time.
+var ErrSettlementImported = errors.New("settlement file already imported")
+
+type SettlementService struct {
+	settlementRepo *repository.SettlementRepository
+	paymentRepo    *repository.PaymentRepository
+	layouts        settlement.Layouts
+	location       *time.Location
+	db             *gorm.DB
+}
+
+func NewSettlementService(
+	settlementRepo *repository.SettlementRepository,
+	paymentRepo *repository.PaymentRepository,
+	layouts settlement.Layouts,
+	location *time.Location,
+	db *gorm.DB,
+) *SettlementService {
+	return &SettlementService{
+		settlementRepo: settlementRepo,
+		paymentRepo:    paymentRepo,
+		layouts:        layouts,
+		location:       location,
+		db:             db,
+	}
+}
+
+func (s *SettlementService) GetLayouts() []settlement.Layout {
+	return s.layouts.List()
+}
+
+// Import reads an acquirer's settlement file with the given layout, matches
+// each line to a payment by reference number and amount, and returns the
+// batch along with a reconciliation report for every settlement date in it.
+// Files without a settlement date column are all settled on settlementDate.
+func (s *SettlementService) Import(ctx context.Context, layoutName, filename string, data []byte, settlementDate string, importedByUserID *uint) (*models.SettlementBatch, []dto.SettlementReport, error) {
+	layout, err := s.layouts.Get(layoutName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if settlementDate != "" {
+		if _, err := time.Parse(settlement.DateFormat, settlementDate); err != nil {
+			return nil, nil, errors.New("settlement_date must be a date in YYYY-MM-DD format")
+		}
+	}
+
+	lines, err := settlement.Parse(bytes.NewReader(data), layout, settlementDate)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil, errors.New("the file has no settlement lines")
+	}
+
+	sum := sha256.Sum256(data)
+	fileHash := hex.EncodeToString(sum[:])
+	existing, err := s.settlementRepo.FindByHash(ctx, fileHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing != nil {
+		return nil, nil, fmt.Errorf("%w as batch %d", ErrSettlementImported, existing.ID)
+	}
+
+	methods := layoutMethods(layout)
+	batch := &models.SettlementBatch{
+		Layout:           layout.Name,
+		Filename:         filename,
+		FileHash:         fileHash,
+		LineCount:        len(lines),
+		ImportedByUserID: importedByUserID,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		settlementRepo := s.settlementRepo.WithTx(tx)
+		paymentRepo := s.paymentRepo.WithTx(tx)
+
+		// Payments settled by earlier lines of this same file
+		settled := make(map[uint]bool)
+
+		records := make([]models.SettlementLine, 0, len(lines))
+		for _, line := range lines {
+			record, err := s.matchLine(ctx, settlementRepo, paymentRepo, methods, line, settled)
+			if err != nil {
+				return err
+			}
+			record.Layout = layout.Name
+			records = append(records, *record)
+
+			batch.GrossAmount += line.Amount
+			batch.MDRAmount += line.MDRAmount
+			batch.NetAmount += line.NetAmount
+		}
+		batch.GrossAmount = roundAmount(batch.GrossAmount)
+		batch.MDRAmount = roundAmount(batch.MDRAmount)
+		batch.NetAmount = roundAmount(batch.NetAmount)
+
+		if err := settlementRepo.Create(ctx, batch); err != nil {
+			return err
+		}
+		for i := range records {
+			records[i].BatchID = batch.ID
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dates := make(map[string]bool)
+	for _, line := range lines {
+		dates[line.SettlementDate] = true
+	}
+	var sortedDates []string
+	for date := range dates {
+		sortedDates = append(sortedDates, date)
+	}
+	sort.Strings(sortedDates)
+
+	reports := make([]dto.SettlementReport, 0, len(sortedDates))
+	for _, date := range sortedDates {
+		report, err := s.GetReport(ctx, layout.Name, date)
+		if err != nil {
+			return nil, nil, err
+		}
+		reports = append(reports, *report)
+	}
+
+	return batch, reports, nil
+}
+
+// matchLine finds the payment a settlement line settles. A payment can only
+// be settled once; later lines for it are duplicates.
+func (s *SettlementService) matchLine(
+	ctx context.Context,
+	settlementRepo *repository.SettlementRepository,
+	paymentRepo *repository.PaymentRepository,
+	methods []models.PaymentMethod,
+	line settlement.Line,
+	settled map[uint]bool,
+) (*models.SettlementLine, error) {
+	record := &models.SettlementLine{
+		RowNumber:      line.Row,
+		SettlementDate: line.SettlementDate,
+		Reference:      line.Reference,
+		GrossAmount:    line.Amount,
+		MDRAmount:      line.MDRAmount,
+		NetAmount:      line.NetAmount,
+		Status:         models.SettlementLineUnmatched,
+	}
+
+	payments, err := paymentRepo.FindByReferenceNumber(ctx, line.Reference, methods)
+	if err != nil {
+		return nil, err
+	}
+
+	var payment, duplicateOf *models.Payment
+	for i := range payments {
+		candidate := &payments[i]
+		if candidate.Status != models.PaymentStatusCompleted && candidate.Status != models.PaymentStatusRefunded {
+			continue
+		}
+		alreadySettled := settled[candidate.ID]
+		if !alreadySettled {
+			if alreadySettled, err = settlementRepo.IsPaymentSettled(ctx, candidate.ID); err != nil {
+				return nil, err
+			}
+		}
+		if !alreadySettled {
+			payment = candidate
+			break
+		}
+		if duplicateOf == nil {
+			duplicateOf = candidate
+		}
+	}
+
+	switch {
+	case payment != nil:
+		expected := roundAmount(payment.AmountPaid + payment.TipAmount)
+		record.PaymentID = &payment.ID
+		record.ExpectedAmount = &expected
+		record.DifferenceAmount = roundAmount(line.Amount - expected)
+		if record.DifferenceAmount == 0 {
+			record.Status = models.SettlementLineMatched
+		} else {
+			record.Status = models.SettlementLineAmountMismatch
+		}
+		settled[payment.ID] = true
+
+	case duplicateOf != nil:
+		record.PaymentID = &duplicateOf.ID
+		record.Status = models.SettlementLineDuplicate
+		note := fmt.Sprintf("payment %s was already settled", duplicateOf.PaymentNumber)
+		record.Note = &note
+
+	case len(payments) > 0:
+		// The reference is known, but its payment was never taken
+		note := fmt.Sprintf("payment %s is %s", payments[0].PaymentNumber, payments[0].Status)
+		record.Note = &note
+	}
+
+	return record, nil
+}
+
+// GetReport reconciles a layout's settlement lines of one date. Lines that
+// did not match a payment for its amount are listed, as are the payments of
+// the day the settlement covers that no settlement line has settled yet.
+func (s *SettlementService) GetReport(ctx context.Context, layoutName, settlementDate string) (*dto.SettlementReport, error) {
+	layout, err := s.layouts.Get(layoutName)
+	if err != nil {
+		return nil, err
+	}
+	date, err := time.ParseInLocation(settlement.DateFormat, settlementDate, s.location)
+	if err != nil {
+		return nil, errors.New("date must be a date in YYYY-MM-DD format")
+	}
+	from := date.AddDate(0, 0, -layout.SettlementLagDays)
+	to := from.AddDate(0, 0, 1)
+
+	report := &dto.SettlementReport{
+		Layout:           layout.Name,
+		SettlementDate:   settlementDate,
+		TransactionDate:  from.Format(settlement.DateFormat),
+		Unmatched:        []dto.SettlementReportLine{},
+		AmountMismatches: []dto.SettlementReportLine{},
+		Duplicates:       []dto.SettlementReportLine{},
+		Missing:          []dto.SettlementMissingPayment{},
+	}
+
+	lines, err := s.settlementRepo.FindLinesByDate(ctx, layout.Name, settlementDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		report.LineCount++
+		report.GrossAmount += line.GrossAmount
+		report.MDRAmount += line.MDRAmount
+		report.NetAmount += line.NetAmount
+
+		switch line.Status {
+		case models.SettlementLineMatched:
+			report.MatchedCount++
+			report.MatchedAmount += line.GrossAmount
+		case models.SettlementLineAmountMismatch:
+			report.AmountMismatches = append(report.AmountMismatches, settlementReportLine(line))
+		case models.SettlementLineDuplicate:
+			report.Duplicates = append(report.Duplicates, settlementReportLine(line))
+		default:
+			report.Unmatched = append(report.Unmatched, settlementReportLine(line))
+		}
+	}
+
+	missing, err := s.paymentRepo.FindUnsettled(ctx, layoutMethods(layout), from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, payment := range missing {
+		amount := roundAmount(payment.AmountPaid + payment.TipAmount)
+		report.Missing = append(report.Missing, dto.SettlementMissingPayment{
+			PaymentID:       payment.ID,
+			PaymentNumber:   payment.PaymentNumber,
+			WorkOrderID:     payment.WorkOrderID,
+			Method:          string(payment.Method),
+			ReferenceNumber: payment.ReferenceNumber,
+			Amount:          amount,
+			PaidAt:          payment.PaidAt,
+		})
+		report.MissingAmount += amount
+	}
+
+	report.GrossAmount = roundAmount(report.GrossAmount)
+	report.MDRAmount = roundAmount(report.MDRAmount)
+	report.NetAmount = roundAmount(report.NetAmount)
+	report.MatchedAmount = roundAmount(report.MatchedAmount)
+	report.MissingAmount = roundAmount(report.MissingAmount)
+
+	return report, nil
+}
+
+func (s *SettlementService) GetByID(ctx context.Context, id uint) (*models.SettlementBatch, error) {
+	return s.settlementRepo.FindWithLines(ctx, id)
+}
+
+func (s *SettlementService) GetAll(ctx context.Context, page, perPage int) ([]models.SettlementBatch, *dto.PaginationMeta, error) {
+	batches, total, err := s.settlementRepo.FindAll(ctx, page, perPage, "ImportedBy")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+
+	meta := &dto.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return batches, meta, nil
+}
+
+func layoutMethods(layout settlement.Layout) []models.PaymentMethod {
+	methods := make([]models.PaymentMethod, 0, len(layout.Methods))
+	for _, method := range layout.Methods {
+		methods = append(methods, models.PaymentMethod(method))
+	}
+	return methods
+}
+
+func settlementReportLine(line models.SettlementLine) dto.SettlementReportLine {
+	reportLine := dto.SettlementReportLine{
+		LineID:           line.ID,
+		BatchID:          line.BatchID,
+		RowNumber:        line.RowNumber,
+		Reference:        line.Reference,
+		GrossAmount:      line.GrossAmount,
+		MDRAmount:        line.MDRAmount,
+		NetAmount:        line.NetAmount,
+		PaymentID:        line.PaymentID,
+		ExpectedAmount:   line.ExpectedAmount,
+		DifferenceAmount: line.DifferenceAmount,
+		Note:             line.Note,
+	}
+	if line.Payment != nil {
+		reportLine.PaymentNumber = &line.Payment.PaymentNumber
+	}
+	return reportLine
+}
@@ -3,79 +3,309 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
+	"flashlight-go/internal/dto"
 	"flashlight-go/internal/models"
 	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
 )
 
 type ShiftService struct {
-	shiftRepo   *repository.ShiftRepository
-	paymentRepo *repository.PaymentRepository
+	shiftRepo     *repository.ShiftRepository
+	handoverRepo  *repository.ShiftHandoverRepository
+	paymentRepo   *repository.PaymentRepository
+	userRepo      *repository.UserRepository
+	denominations []float64
+	db            *gorm.DB
 }
 
 func NewShiftService(
 	shiftRepo *repository.ShiftRepository,
+	handoverRepo *repository.ShiftHandoverRepository,
 	paymentRepo *repository.PaymentRepository,
+	userRepo *repository.UserRepository,
+	denominations []float64,
+	db *gorm.DB,
 ) *ShiftService {
 	return &ShiftService{
-		shiftRepo:   shiftRepo,
-		paymentRepo: paymentRepo,
+		shiftRepo:     shiftRepo,
+		handoverRepo:  handoverRepo,
+		paymentRepo:   paymentRepo,
+		userRepo:      userRepo,
+		denominations: denominations,
+		db:            db,
 	}
 }
 
-func (s *ShiftService) Start(ctx context.Context, userID uint, initialCash float64, receivedFrom *string) (*models.Shift, error) {
-	// Check if user already has an active shift
-	activeShift, err := s.shiftRepo.FindActiveShiftByUser(ctx, userID)
+// Start opens a shift for a user. A user with a drawer handed over to them
+// has to acknowledge it by starting with the handover and the float they
+// counted, which becomes the shift's initial cash. A count that differs from
+// what the previous cashier handed over flags the handover.
+func (s *ShiftService) Start(ctx context.Context, userID uint, req dto.CreateShiftRequest) (*models.Shift, error) {
+	var shift *models.Shift
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+
+		// Check if user already has an active shift
+		activeShift, err := shiftRepo.FindActiveShiftByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if activeShift != nil {
+			return errors.New("user already has an active shift")
+		}
+
+		shift = &models.Shift{
+			UserID:      userID,
+			StartTime:   time.Now(),
+			InitialCash: roundAmount(req.InitialCash),
+			Status:      models.ShiftStatusActive,
+		}
+
+		handoverRepo := s.handoverRepo.WithTx(tx)
+		if req.HandoverID == nil {
+			pending, err := handoverRepo.FindPendingForUser(ctx, userID)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				from := pending[0]
+				if from.FromUser != nil {
+					return fmt.Errorf("acknowledge handover %d from %s before starting a shift", from.ID, from.FromUser.Name)
+				}
+				return fmt.Errorf("acknowledge handover %d before starting a shift", from.ID)
+			}
+			return shiftRepo.Create(ctx, shift)
+		}
+
+		if req.ReceivedAmount == nil {
+			return errors.New("received_amount is required to acknowledge a handover")
+		}
+		handover, err := handoverRepo.FindByIDForUpdate(ctx, *req.HandoverID)
+		if err != nil || handover.ToUserID != userID {
+			return errors.New("handover not found")
+		}
+		if handover.Status != models.HandoverStatusPending {
+			return errors.New("handover has already been acknowledged")
+		}
+
+		received := roundAmount(*req.ReceivedAmount)
+		shift.InitialCash = received
+		if err := shiftRepo.Create(ctx, shift); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		handover.ToShiftID = &shift.ID
+		handover.ReceivedAmount = &received
+		handover.Discrepancy = roundAmount(received - handover.HandedOverAmount)
+		handover.IsFlagged = handover.Discrepancy != 0
+		handover.Status = models.HandoverStatusAcknowledged
+		handover.AcknowledgedAt = &now
+		if err := tx.Save(handover).Error; err != nil {
+			return err
+		}
+		if handover.IsFlagged {
+			log.Printf("Shift handover %d flagged: %.2f handed over, %.2f received", handover.ID, handover.HandedOverAmount, received)
+		}
+
+		shift.HandoverIn = handover
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	if activeShift != nil {
-		return nil, errors.New("user already has an active shift")
-	}
 
-	shift := &models.Shift{
-		UserID:       userID,
-		StartTime:    time.Now(),
-		InitialCash:  initialCash,
-		Status:       models.ShiftStatusActive,
-		ReceivedFrom: receivedFrom,
+	return shift, nil
+}
+
+// Close ends a shift with a blind count of its drawer. The counted notes and
+// coins are kept, and the counted total is compared with the cash the
+// drawer should hold; the difference is stored as the shift's variance,
+// with the cashier's explanation if they gave one. Part or all of the
+// counted cash can be handed over to the next cashier, who acknowledges it
+// when starting their shift.
+func (s *ShiftService) Close(ctx context.Context, shiftID uint, req dto.CloseShiftRequest, closedByUserID *uint) (*models.Shift, error) {
+	counts := make([]models.ShiftCashCount, 0, len(req.CashCounts))
+	seen := make(map[float64]bool, len(req.CashCounts))
+	var counted float64
+	for _, count := range req.CashCounts {
+		denomination := roundAmount(count.Denomination)
+		if !s.isDenomination(denomination) {
+			return nil, fmt.Errorf("%.2f is not a cash denomination", count.Denomination)
+		}
+		if seen[denomination] {
+			return nil, fmt.Errorf("denomination %.2f is counted twice", denomination)
+		}
+		seen[denomination] = true
+
+		amount := roundAmount(denomination * float64(count.Quantity))
+		counts = append(counts, models.ShiftCashCount{
+			Denomination: denomination,
+			Quantity:     count.Quantity,
+			Amount:       amount,
+		})
+		counted += amount
 	}
 
-	if err := s.shiftRepo.Create(ctx, shift); err != nil {
+	var shift *models.Shift
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+		var err error
+		shift, err = shiftRepo.FindByIDForUpdate(ctx, shiftID)
+		if err != nil {
+			return errors.New("shift not found")
+		}
+		if shift.Status != models.ShiftStatusActive {
+			return errors.New("shift is not active")
+		}
+
+		summary, err := shiftRepo.GetShiftSummary(ctx, shiftID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		shift.EndTime = &now
+		shift.FinalCash = roundAmount(counted)
+		shift.TotalSales = summary["total_sales"].(float64)
+		shift.TotalTips = summary["total_tips"].(float64)
+		shift.CashSales = roundAmount(summary["cash_sales"].(float64))
+		shift.NonCashSales = roundAmount(summary["non_cash_sales"].(float64))
+		shift.ExpectedCash = expectedCash(shift, summary)
+		shift.CashVariance = roundAmount(shift.FinalCash - shift.ExpectedCash)
+		shift.VarianceNote = req.VarianceNote
+		shift.ClosedByUserID = closedByUserID
+		shift.Status = models.ShiftStatusClosed
+		if err := tx.Save(shift).Error; err != nil {
+			return err
+		}
+
+		for i := range counts {
+			counts[i].ShiftID = shift.ID
+		}
+		if err := tx.Create(&counts).Error; err != nil {
+			return err
+		}
+		shift.CashCounts = counts
+
+		if req.Handover == nil {
+			return nil
+		}
+		handover, err := s.handOver(ctx, tx, shift, *req.Handover)
+		if err != nil {
+			return err
+		}
+		shift.HandoverOut = handover
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return shift, nil
 }
 
-func (s *ShiftService) Close(ctx context.Context, shiftID uint, finalCash float64) (*models.Shift, error) {
-	shift, err := s.shiftRepo.FindByID(ctx, shiftID)
+// handOver records the float a closing shift passes on to the next cashier.
+func (s *ShiftService) handOver(ctx context.Context, tx *gorm.DB, shift *models.Shift, req dto.ShiftHandoverRequest) (*models.ShiftHandover, error) {
+	if req.ToUserID == shift.UserID {
+		return nil, errors.New("cannot hand a drawer over to yourself")
+	}
+	receiver, err := s.userRepo.FindByID(ctx, req.ToUserID)
 	if err != nil {
-		return nil, err
+		return nil, errors.New("receiving user not found")
+	}
+	switch receiver.Role {
+	case models.RoleOwner, models.RoleAdmin, models.RoleCashier:
+	default:
+		return nil, fmt.Errorf("%s cannot receive a drawer", receiver.Name)
 	}
 
-	if shift.Status != models.ShiftStatusActive {
-		return nil, errors.New("shift is not active")
+	amount := shift.FinalCash
+	if req.Amount != nil {
+		amount = roundAmount(*req.Amount)
+	}
+	if amount > shift.FinalCash {
+		return nil, fmt.Errorf("cannot hand over %.2f, only %.2f was counted", amount, shift.FinalCash)
 	}
 
-	// Calculate total sales
-	summary, err := s.shiftRepo.GetShiftSummary(ctx, shiftID)
+	handover := &models.ShiftHandover{
+		FromShiftID:      shift.ID,
+		FromUserID:       shift.UserID,
+		ToUserID:         receiver.ID,
+		HandedOverAmount: amount,
+		Status:           models.HandoverStatusPending,
+		Note:             req.Note,
+	}
+	if err := s.handoverRepo.WithTx(tx).Create(ctx, handover); err != nil {
+		return nil, err
+	}
+	handover.ToUser = receiver
+	return handover, nil
+}
+
+// GetPendingHandovers returns the handovers waiting for a user to
+// acknowledge, without the amounts so the float is counted blind.
+func (s *ShiftService) GetPendingHandovers(ctx context.Context, userID uint) ([]dto.PendingHandoverResponse, error) {
+	handovers, err := s.handoverRepo.FindPendingForUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	now := time.Now()
-	shift.EndTime = &now
-	shift.FinalCash = finalCash
-	shift.TotalSales = summary["total_sales"].(float64)
-	shift.Status = models.ShiftStatusClosed
+	responses := make([]dto.PendingHandoverResponse, len(handovers))
+	for i, handover := range handovers {
+		responses[i] = dto.PendingHandoverResponse{
+			ID:          handover.ID,
+			FromShiftID: handover.FromShiftID,
+			FromUserID:  handover.FromUserID,
+			Note:        handover.Note,
+			CreatedAt:   handover.CreatedAt,
+		}
+		if handover.FromUser != nil {
+			responses[i].FromUserName = handover.FromUser.Name
+		}
+	}
+	return responses, nil
+}
 
-	if err := s.shiftRepo.Update(ctx, shift); err != nil {
-		return nil, err
+func (s *ShiftService) GetHandovers(ctx context.Context, page, perPage int, status string, flaggedOnly bool) ([]models.ShiftHandover, *dto.PaginationMeta, error) {
+	handovers, total, err := s.handoverRepo.FindFiltered(ctx, page, perPage, status, flaggedOnly)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return shift, nil
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+
+	meta := &dto.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return handovers, meta, nil
+}
+
+// Denominations lists the notes and coins a drawer is counted in, largest
+// first.
+func (s *ShiftService) Denominations() []float64 {
+	return s.denominations
+}
+
+func (s *ShiftService) isDenomination(value float64) bool {
+	for _, denomination := range s.denominations {
+		if roundAmount(denomination) == value {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *ShiftService) GetByID(ctx context.Context, id uint) (*models.Shift, error) {
@@ -97,6 +327,20 @@ func (s *ShiftService) GetSummary(ctx context.Context, shiftID uint) (map[string
 		return nil, err
 	}
 
+	summary["expected_cash"] = expectedCash(shift, summary)
 	summary["shift"] = shift
 	return summary, nil
 }
+
+// expectedCash is the cash a shift's drawer should hold according to its
+// summary: the opening float, cash taken for sales and stored value, less
+// cash refunds, plus pay-ins and less pay-outs and safe drops.
+func expectedCash(shift *models.Shift, summary map[string]interface{}) float64 {
+	return roundAmount(shift.InitialCash +
+		summary["cash_received"].(float64) +
+		summary["stored_value_cash"].(float64) -
+		summary["cash_refunds"].(float64) +
+		summary["cash_pay_ins"].(float64) -
+		summary["cash_pay_outs"].(float64) -
+		summary["safe_drops"].(float64))
+}
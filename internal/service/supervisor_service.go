@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+	"flashlight-go/pkg/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// minPINLength is the shortest supervisor PIN accepted. Shorter PINs set
+	// before it was raised no longer approve anything and have to be set
+	// again.
+	minPINLength = 6
+	// maxPINFailures wrong PINs in a row lock a supervisor PIN for
+	// pinLockout.
+	maxPINFailures = 5
+	pinLockout     = 15 * time.Minute
+	// approvalTokenTTL is how long a supervisor approval token is valid.
+	approvalTokenTTL = 5 * time.Minute
+)
+
+var (
+	// ErrApprovalRequired is returned when an operation needs a supervisor's
+	// approval and none was given.
+	ErrApprovalRequired = errors.New("supervisor approval is required")
+	// ErrInvalidApproval is returned when the supervisor PIN or token does
+	// not check out.
+	ErrInvalidApproval = errors.New("invalid supervisor approval")
+	// ErrApprovalLocked is returned while a supervisor PIN is locked after
+	// too many wrong attempts.
+	ErrApprovalLocked = errors.New("supervisor PIN is locked after too many wrong attempts, try again later")
+)
+
+// SupervisorService checks that sensitive operations carried out by cashiers
+// are approved by an owner or admin, either with the supervisor's PIN or
+// with a short-lived approval token the supervisor issued.
+type SupervisorService struct {
+	userRepo      *repository.UserRepository
+	usedTokenRepo *repository.UsedApprovalTokenRepository
+	db            *gorm.DB
+}
+
+func NewSupervisorService(userRepo *repository.UserRepository, usedTokenRepo *repository.UsedApprovalTokenRepository, db *gorm.DB) *SupervisorService {
+	return &SupervisorService{userRepo: userRepo, usedTokenRepo: usedTokenRepo, db: db}
+}
+
+// Approval is a supervisor's approval of one operation. An approval given
+// with a token has to be consumed in the transaction of the operation it
+// approves, so the token cannot approve a second one.
+type Approval struct {
+	Supervisor *models.User
+
+	tokenID       string
+	expiresAt     time.Time
+	usedTokenRepo *repository.UsedApprovalTokenRepository
+}
+
+// Consume records the approval's token as used, as part of the caller's
+// transaction. It fails with ErrInvalidApproval when the token was used
+// already, rolling the operation back. PIN approvals have nothing to
+// consume.
+func (a *Approval) Consume(ctx context.Context, tx *gorm.DB) error {
+	if a.tokenID == "" {
+		return nil
+	}
+	unused, err := a.usedTokenRepo.WithTx(tx).Record(ctx, &models.UsedApprovalToken{
+		TokenID:          a.tokenID,
+		SupervisorUserID: a.Supervisor.ID,
+		ExpiresAt:        a.expiresAt,
+		UsedAt:           time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if !unused {
+		return ErrInvalidApproval
+	}
+	return nil
+}
+
+// Approve returns the approval of an operation requested by the given user.
+// Owners and admins approve their own requests; anyone else needs a
+// supervisor PIN or an approval token that has not been used yet.
+func (s *SupervisorService) Approve(ctx context.Context, requesterUserID *uint, approval dto.SupervisorApprovalRequest) (*Approval, error) {
+	if requesterUserID != nil {
+		requester, err := s.userRepo.FindByID(ctx, *requesterUserID)
+		if err == nil && isSupervisor(requester) {
+			return &Approval{Supervisor: requester}, nil
+		}
+	}
+
+	switch {
+	case approval.SupervisorToken != nil && *approval.SupervisorToken != "":
+		// Login tokens are refused, only approval tokens vouch for this
+		claims, err := utils.ValidateToken(*approval.SupervisorToken)
+		if err != nil || claims.Purpose != utils.PurposeSupervisorApproval || claims.ID == "" || claims.ExpiresAt == nil {
+			return nil, ErrInvalidApproval
+		}
+		// Checked again when the approval is consumed; this only saves
+		// starting the operation
+		used, err := s.usedTokenRepo.IsUsed(ctx, claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			return nil, ErrInvalidApproval
+		}
+		supervisor, err := s.userRepo.FindByID(ctx, claims.UserID)
+		if err != nil || !isSupervisor(supervisor) {
+			return nil, ErrInvalidApproval
+		}
+		return &Approval{
+			Supervisor:    supervisor,
+			tokenID:       claims.ID,
+			expiresAt:     claims.ExpiresAt.Time,
+			usedTokenRepo: s.usedTokenRepo,
+		}, nil
+
+	case approval.SupervisorUserID != nil && approval.SupervisorPIN != nil:
+		if len(*approval.SupervisorPIN) < minPINLength {
+			return nil, ErrInvalidApproval
+		}
+		supervisor, err := s.checkPIN(ctx, *approval.SupervisorUserID, *approval.SupervisorPIN)
+		if err != nil {
+			return nil, err
+		}
+		return &Approval{Supervisor: supervisor}, nil
+
+	default:
+		return nil, ErrApprovalRequired
+	}
+}
+
+// checkPIN verifies a supervisor PIN. The supervisor's row is locked while
+// the PIN is checked, so wrong attempts are counted one at a time; after
+// maxPINFailures in a row the PIN is refused until pinLockout has passed.
+func (s *SupervisorService) checkPIN(ctx context.Context, supervisorUserID uint, pin string) (*models.User, error) {
+	var supervisor *models.User
+	var approvalErr error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		supervisor, err = s.userRepo.WithTx(tx).FindByIDForUpdate(ctx, supervisorUserID)
+		if err != nil || !isSupervisor(supervisor) || supervisor.SupervisorPINHash == nil {
+			approvalErr = ErrInvalidApproval
+			return nil
+		}
+
+		now := time.Now()
+		if supervisor.PINLockedUntil != nil && now.Before(*supervisor.PINLockedUntil) {
+			approvalErr = ErrApprovalLocked
+			return nil
+		}
+
+		updates := map[string]interface{}{"pin_failures": 0, "pin_locked_until": nil}
+		if bcrypt.CompareHashAndPassword([]byte(*supervisor.SupervisorPINHash), []byte(pin)) != nil {
+			approvalErr = ErrInvalidApproval
+			failures := supervisor.PINFailures + 1
+			updates["pin_failures"] = failures
+			if failures >= maxPINFailures {
+				updates["pin_failures"] = 0
+				updates["pin_locked_until"] = now.Add(pinLockout)
+			}
+		} else if supervisor.PINFailures == 0 && supervisor.PINLockedUntil == nil {
+			return nil
+		}
+		return tx.Model(supervisor).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if approvalErr != nil {
+		return nil, approvalErr
+	}
+	return supervisor, nil
+}
+
+// IssueApprovalToken gives a signed-in owner or admin a token that approves
+// one operation within approvalTokenTTL. It cannot be used to sign in.
+func (s *SupervisorService) IssueApprovalToken(ctx context.Context, userID uint) (*dto.ApprovalTokenResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSupervisor(user) {
+		return nil, errors.New("only owners and admins can approve operations")
+	}
+
+	token, expiresAt, err := utils.GenerateApprovalToken(user.ID, string(user.Role), approvalTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ApprovalTokenResponse{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// SetPIN sets the supervisor PIN of an owner or admin, confirmed with their
+// password.
+func (s *SupervisorService) SetPIN(ctx context.Context, userID uint, req dto.SetSupervisorPINRequest) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !isSupervisor(user) {
+		return errors.New("only owners and admins can have a supervisor PIN")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return errors.New("invalid password")
+	}
+
+	hashedPIN, err := bcrypt.GenerateFromPassword([]byte(req.PIN), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	pinHash := string(hashedPIN)
+	user.SupervisorPINHash = &pinHash
+	user.PINFailures = 0
+	user.PINLockedUntil = nil
+
+	return s.userRepo.Update(ctx, user)
+}
+
+func isSupervisor(user *models.User) bool {
+	return user.IsActive && (user.Role == models.RoleOwner || user.Role == models.RoleAdmin)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/pkg/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// newSupervisor creates an owner with the given supervisor PIN.
+func (e *testEnv) newSupervisor(t *testing.T, pin string) *models.User {
+	t.Helper()
+	supervisor := e.newUser(t, models.RoleOwner)
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash PIN: %v", err)
+	}
+	pinHash := string(hash)
+	supervisor.SupervisorPINHash = &pinHash
+	if err := e.db.Save(supervisor).Error; err != nil {
+		t.Fatalf("save supervisor: %v", err)
+	}
+	return supervisor
+}
+
+func pinApproval(supervisor *models.User, pin string) dto.SupervisorApprovalRequest {
+	return dto.SupervisorApprovalRequest{SupervisorUserID: &supervisor.ID, SupervisorPIN: &pin}
+}
+
+func TestSupervisorPINLockout(t *testing.T) {
+	env := newTestEnv(t)
+	supervisor := env.newSupervisor(t, "482193")
+	cashier := env.newUser(t, models.RoleCashier)
+	ctx := context.Background()
+
+	for i := 0; i < maxPINFailures; i++ {
+		if _, err := env.supervisor.Approve(ctx, &cashier.ID, pinApproval(supervisor, "000000")); !errors.Is(err, ErrInvalidApproval) {
+			t.Fatalf("wrong PIN %d: got error %v, want %v", i+1, err, ErrInvalidApproval)
+		}
+	}
+
+	// Locked: even the right PIN is refused
+	if _, err := env.supervisor.Approve(ctx, &cashier.ID, pinApproval(supervisor, "482193")); !errors.Is(err, ErrApprovalLocked) {
+		t.Fatalf("right PIN while locked: got error %v, want %v", err, ErrApprovalLocked)
+	}
+
+	// Once the lockout has passed the right PIN works and clears the count
+	past := time.Now().Add(-time.Minute)
+	if err := env.db.Model(supervisor).Update("pin_locked_until", past).Error; err != nil {
+		t.Fatalf("expire lockout: %v", err)
+	}
+	approver, err := env.supervisor.Approve(ctx, &cashier.ID, pinApproval(supervisor, "482193"))
+	if err != nil || approver.Supervisor.ID != supervisor.ID {
+		t.Fatalf("right PIN after lockout: got %v, %v", approver, err)
+	}
+	env.reload(t, supervisor, supervisor.ID)
+	if supervisor.PINFailures != 0 || supervisor.PINLockedUntil != nil {
+		t.Errorf("got %d failures locked until %v, want both cleared", supervisor.PINFailures, supervisor.PINLockedUntil)
+	}
+}
+
+func TestSupervisorPINTooShort(t *testing.T) {
+	env := newTestEnv(t)
+	supervisor := env.newSupervisor(t, "4821")
+	cashier := env.newUser(t, models.RoleCashier)
+
+	if _, err := env.supervisor.Approve(context.Background(), &cashier.ID, pinApproval(supervisor, "4821")); !errors.Is(err, ErrInvalidApproval) {
+		t.Errorf("4-digit PIN: got error %v, want %v", err, ErrInvalidApproval)
+	}
+}
+
+func TestSupervisorApprovalToken(t *testing.T) {
+	env := newTestEnv(t)
+	supervisor := env.newSupervisor(t, "482193")
+	cashier := env.newUser(t, models.RoleCashier)
+	ctx := context.Background()
+
+	loginToken, err := utils.GenerateToken(supervisor.ID, string(supervisor.Role))
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if _, err := env.supervisor.Approve(ctx, &cashier.ID, dto.SupervisorApprovalRequest{SupervisorToken: &loginToken}); !errors.Is(err, ErrInvalidApproval) {
+		t.Errorf("login token: got error %v, want %v", err, ErrInvalidApproval)
+	}
+
+	issued, err := env.supervisor.IssueApprovalToken(ctx, supervisor.ID)
+	if err != nil {
+		t.Fatalf("IssueApprovalToken: %v", err)
+	}
+	if ttl := time.Until(issued.ExpiresAt); ttl <= 0 || ttl > approvalTokenTTL {
+		t.Errorf("token expires in %v, want within %v", ttl, approvalTokenTTL)
+	}
+	approver, err := env.supervisor.Approve(ctx, &cashier.ID, dto.SupervisorApprovalRequest{SupervisorToken: &issued.Token})
+	if err != nil || approver.Supervisor.ID != supervisor.ID {
+		t.Errorf("approval token: got %v, %v", approver, err)
+	}
+
+	if _, err := env.supervisor.IssueApprovalToken(ctx, cashier.ID); err == nil {
+		t.Errorf("a cashier was issued an approval token")
+	}
+}
+
+func TestSupervisorApprovalTokenSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	supervisor := env.newSupervisor(t, "482193")
+	cashier := env.newUser(t, models.RoleCashier)
+	order := env.newPaidOrder(t, cashier, env.newShift(t, cashier))
+	ctx := context.Background()
+
+	issued, err := env.supervisor.IssueApprovalToken(ctx, supervisor.ID)
+	if err != nil {
+		t.Fatalf("IssueApprovalToken: %v", err)
+	}
+	request := dto.SupervisorApprovalRequest{SupervisorToken: &issued.Token}
+
+	// Two requests checking the token before either has used it
+	first, err := env.supervisor.Approve(ctx, &cashier.ID, request)
+	if err != nil {
+		t.Fatalf("first Approve: %v", err)
+	}
+	second, err := env.supervisor.Approve(ctx, &cashier.ID, request)
+	if err != nil {
+		t.Fatalf("second Approve: %v", err)
+	}
+
+	if _, err := env.payments.Void(ctx, order.payment.ID, "wrong method", &cashier.ID, first); err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+
+	// The token is used up, whether it is checked again or consumed by the
+	// request that checked it earlier
+	if _, err := env.supervisor.Approve(ctx, &cashier.ID, request); !errors.Is(err, ErrInvalidApproval) {
+		t.Errorf("Approve with a used token: got error %v, want %v", err, ErrInvalidApproval)
+	}
+	err = env.db.Transaction(func(tx *gorm.DB) error {
+		return second.Consume(ctx, tx)
+	})
+	if !errors.Is(err, ErrInvalidApproval) {
+		t.Errorf("Consume of a used token: got error %v, want %v", err, ErrInvalidApproval)
+	}
+
+	// PIN approvals are not used up
+	approval, err := env.supervisor.Approve(ctx, &cashier.ID, pinApproval(supervisor, "482193"))
+	if err != nil {
+		t.Fatalf("Approve with PIN: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := env.db.Transaction(func(tx *gorm.DB) error { return approval.Consume(ctx, tx) }); err != nil {
+			t.Errorf("Consume of a PIN approval %d: %v", i+1, err)
+		}
+	}
+}
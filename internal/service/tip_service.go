@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type TipService struct {
+	tipRepo   *repository.TipAllocationRepository
+	userRepo  *repository.UserRepository
+	splitRule models.TipSplitRule
+	location  *time.Location
+}
+
+func NewTipService(
+	tipRepo *repository.TipAllocationRepository,
+	userRepo *repository.UserRepository,
+	splitRule string,
+	location *time.Location,
+) *TipService {
+	rule := models.TipSplitRule(splitRule)
+	if rule != models.TipSplitByItemValue {
+		rule = models.TipSplitEqual
+	}
+
+	return &TipService{
+		tipRepo:   tipRepo,
+		userRepo:  userRepo,
+		splitRule: rule,
+		location:  location,
+	}
+}
+
+// Distribute splits the tip of a payment among the staff assigned to the
+// items of its work order. It runs inside the transaction that records the
+// payment so a payment never exists without its allocations.
+func (s *TipService) Distribute(ctx context.Context, tx *gorm.DB, payment *models.Payment) error {
+	if payment.TipAmount <= 0 {
+		return nil
+	}
+
+	var items []models.WorkOrderItem
+	if err := tx.WithContext(ctx).Where("work_order_id = ?", payment.WorkOrderID).Find(&items).Error; err != nil {
+		return err
+	}
+
+	shares := splitTip(payment.TipAmount, items, s.splitRule)
+	allocations := make([]models.TipAllocation, len(shares))
+	for i, share := range shares {
+		allocations[i] = models.TipAllocation{
+			PaymentID:   payment.ID,
+			WorkOrderID: payment.WorkOrderID,
+			StaffUserID: share.staffUserID,
+			ShiftID:     payment.ShiftID,
+			Amount:      share.amount,
+		}
+	}
+
+	return s.tipRepo.WithTx(tx).CreateAll(ctx, allocations)
+}
+
+// Release removes the tip split of a payment that failed or was voided, so
+// staff are not credited a tip that was never kept.
+func (s *TipService) Release(ctx context.Context, tx *gorm.DB, paymentID uint) error {
+	return s.tipRepo.WithTx(tx).DeleteByPayment(ctx, paymentID)
+}
+
+// GetStaffEarnings reports the services performed and tips received by each
+// staff member between two outlet-local dates, both inclusive. A staff
+// member can be given to limit the report to them. Missing dates default to
+// the current day.
+func (s *TipService) GetStaffEarnings(ctx context.Context, fromDate, toDate string, staffUserID *uint) (*dto.StaffEarningsResponse, error) {
+	today := time.Now().In(s.location).Format("2006-01-02")
+	if fromDate == "" {
+		fromDate = today
+	}
+	if toDate == "" {
+		toDate = fromDate
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", fromDate, s.location)
+	if err != nil {
+		return nil, errors.New("from must be a date in YYYY-MM-DD format")
+	}
+	to, err := time.ParseInLocation("2006-01-02", toDate, s.location)
+	if err != nil {
+		return nil, errors.New("to must be a date in YYYY-MM-DD format")
+	}
+	if to.Before(from) {
+		return nil, errors.New("to must not be before from")
+	}
+	end := to.AddDate(0, 0, 1)
+
+	services, err := s.tipRepo.SumServicesByStaff(ctx, from, end, staffUserID)
+	if err != nil {
+		return nil, err
+	}
+	tips, err := s.tipRepo.SumByStaff(ctx, from, end, staffUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.StaffEarningsResponse{
+		From:  fromDate,
+		To:    toDate,
+		Staff: []dto.StaffEarningsLine{},
+	}
+
+	lines := make(map[uint]*dto.StaffEarningsLine)
+	lineFor := func(id uint) *dto.StaffEarningsLine {
+		if line, ok := lines[id]; ok {
+			return line
+		}
+		line := &dto.StaffEarningsLine{StaffUserID: id}
+		lines[id] = line
+		return line
+	}
+
+	for _, total := range services {
+		line := lineFor(total.StaffUserID)
+		line.ItemCount = total.ItemCount
+		line.ServiceRevenue = roundAmount(total.ServiceRevenue)
+	}
+	for _, total := range tips {
+		response.TotalTips += total.Tips
+		if total.StaffUserID == nil {
+			response.UnassignedTips += total.Tips
+			continue
+		}
+		lineFor(*total.StaffUserID).Tips = roundAmount(total.Tips)
+	}
+	response.TotalTips = roundAmount(response.TotalTips)
+	response.UnassignedTips = roundAmount(response.UnassignedTips)
+
+	for id, line := range lines {
+		if user, err := s.userRepo.FindByID(ctx, id); err == nil {
+			line.StaffName = user.Name
+		}
+		response.Staff = append(response.Staff, *line)
+	}
+	sort.Slice(response.Staff, func(i, j int) bool {
+		return response.Staff[i].StaffUserID < response.Staff[j].StaffUserID
+	})
+
+	return response, nil
+}
+
+type tipShare struct {
+	staffUserID *uint
+	amount      float64
+}
+
+// splitTip divides a tip among the staff assigned to the given items. The
+// split is done in whole cents and any remainder goes to the first staff
+// members in ID order, so the shares always add up to the tip. Without any
+// assigned staff the whole tip becomes a single unassigned share.
+func splitTip(tip float64, items []models.WorkOrderItem, rule models.TipSplitRule) []tipShare {
+	weights := make(map[uint]float64)
+	for _, item := range items {
+		if item.AssignedStaffUserID == nil {
+			continue
+		}
+		if rule == models.TipSplitByItemValue {
+			weights[*item.AssignedStaffUserID] += item.Subtotal
+		} else {
+			weights[*item.AssignedStaffUserID] = 1
+		}
+	}
+
+	if len(weights) == 0 {
+		return []tipShare{{amount: roundAmount(tip)}}
+	}
+
+	staffIDs := make([]uint, 0, len(weights))
+	var totalWeight float64
+	for id, weight := range weights {
+		staffIDs = append(staffIDs, id)
+		totalWeight += weight
+	}
+	sort.Slice(staffIDs, func(i, j int) bool { return staffIDs[i] < staffIDs[j] })
+
+	// Items that were all free carry no value to split by
+	if totalWeight <= 0 {
+		for _, id := range staffIDs {
+			weights[id] = 1
+		}
+		totalWeight = float64(len(staffIDs))
+	}
+
+	cents := int64(math.Round(tip * 100))
+	shares := make([]tipShare, len(staffIDs))
+	var allocated int64
+	for i, id := range staffIDs {
+		staffID := id
+		shareCents := int64(math.Floor(float64(cents) * weights[id] / totalWeight))
+		shares[i] = tipShare{staffUserID: &staffID, amount: float64(shareCents)}
+		allocated += shareCents
+	}
+	for i := 0; allocated < cents; i = (i + 1) % len(shares) {
+		shares[i].amount++
+		allocated++
+	}
+	for i := range shares {
+		shares[i].amount /= 100
+	}
+
+	return shares
+}
@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"flashlight-go/internal/dto"
+	"flashlight-go/internal/models"
+	"flashlight-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrInsufficientBalance = errors.New("insufficient wallet balance")
+
+// giftCardAlphabet leaves out characters that are easy to misread on a card.
+const giftCardAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+type WalletService struct {
+	storedValueRepo  *repository.StoredValueRepository
+	giftCardRepo     *repository.GiftCardRepository
+	userRepo         *repository.UserRepository
+	shiftRepo        *repository.ShiftRepository
+	paymentMethods   *PaymentMethodService
+	db               *gorm.DB
+	giftCardValidity time.Duration
+}
+
+func NewWalletService(
+	storedValueRepo *repository.StoredValueRepository,
+	giftCardRepo *repository.GiftCardRepository,
+	userRepo *repository.UserRepository,
+	shiftRepo *repository.ShiftRepository,
+	paymentMethods *PaymentMethodService,
+	db *gorm.DB,
+	giftCardValidityDays int,
+) *WalletService {
+	return &WalletService{
+		storedValueRepo:  storedValueRepo,
+		giftCardRepo:     giftCardRepo,
+		userRepo:         userRepo,
+		shiftRepo:        shiftRepo,
+		paymentMethods:   paymentMethods,
+		db:               db,
+		giftCardValidity: time.Duration(giftCardValidityDays) * 24 * time.Hour,
+	}
+}
+
+func (s *WalletService) GetBalance(ctx context.Context, customerID uint) (*dto.WalletBalanceResponse, error) {
+	if _, err := s.userRepo.FindByID(ctx, customerID); err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	balance, err := s.storedValueRepo.GetBalance(ctx, models.AccountWallet, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.WalletBalanceResponse{
+		CustomerUserID: customerID,
+		Balance:        balance,
+	}, nil
+}
+
+func (s *WalletService) GetEntries(ctx context.Context, customerID uint, page, perPage int) ([]dto.StoredValueEntryResponse, *dto.PaginationMeta, error) {
+	entries, total, err := s.storedValueRepo.FindByAccount(ctx, models.AccountWallet, customerID, page, perPage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]dto.StoredValueEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = *s.toEntryResponse(&entry)
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+
+	meta := &dto.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return responses, meta, nil
+}
+
+// TopUp adds money the customer paid at the counter to their wallet. The
+// entry records how it was paid and the shift it was taken in.
+func (s *WalletService) TopUp(ctx context.Context, customerID uint, req dto.TopUpWalletRequest, userID *uint) (*dto.StoredValueEntryResponse, error) {
+	if _, err := s.userRepo.FindByID(ctx, customerID); err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	method, err := s.resolveTender(ctx, req.Method, req.ReferenceNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.StoredValueEntry{
+		AccountType:     models.AccountWallet,
+		AccountID:       customerID,
+		EntryType:       models.EntryTopUp,
+		Amount:          req.Amount,
+		TenderMethod:    &method,
+		ReferenceNumber: req.ReferenceNumber,
+		Note:            req.Note,
+		CreatedByUserID: userID,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		shiftID, err := s.tenderShift(ctx, tx, method, userID)
+		if err != nil {
+			return err
+		}
+		entry.ShiftID = shiftID
+		return s.post(ctx, tx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toEntryResponse(entry), nil
+}
+
+// DebitForPayment takes a wallet payment out of the customer's balance. It
+// must run inside the transaction that records the payment.
+func (s *WalletService) DebitForPayment(ctx context.Context, tx *gorm.DB, customerID uint, amount float64, paymentID uint, userID *uint) error {
+	return s.post(ctx, tx, &models.StoredValueEntry{
+		AccountType:     models.AccountWallet,
+		AccountID:       customerID,
+		EntryType:       models.EntryPayment,
+		Amount:          -amount,
+		PaymentID:       &paymentID,
+		CreatedByUserID: userID,
+	})
+}
+
+// CreditForRefund returns refunded money to the customer's wallet inside the
+// transaction that records the refund.
+func (s *WalletService) CreditForRefund(ctx context.Context, tx *gorm.DB, customerID uint, amount float64, paymentID uint, userID *uint) error {
+	return s.post(ctx, tx, &models.StoredValueEntry{
+		AccountType:     models.AccountWallet,
+		AccountID:       customerID,
+		EntryType:       models.EntryRefund,
+		Amount:          amount,
+		PaymentID:       &paymentID,
+		CreatedByUserID: userID,
+	})
+}
+
+// CreditForVoid gives back what a voided wallet payment took from the
+// customer's balance, inside the transaction that voids the payment.
+func (s *WalletService) CreditForVoid(ctx context.Context, tx *gorm.DB, customerID uint, amount float64, paymentID uint, userID *uint) error {
+	return s.post(ctx, tx, &models.StoredValueEntry{
+		AccountType:     models.AccountWallet,
+		AccountID:       customerID,
+		EntryType:       models.EntryVoid,
+		Amount:          amount,
+		PaymentID:       &paymentID,
+		CreatedByUserID: userID,
+	})
+}
+
+// IssueGiftCard sells a new gift card. The issue entry records how the card
+// was paid for and the shift it was sold in.
+func (s *WalletService) IssueGiftCard(ctx context.Context, req dto.IssueGiftCardRequest, userID *uint) (*dto.GiftCardResponse, error) {
+	method, err := s.resolveTender(ctx, req.Method, req.ReferenceNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		t, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+		if err != nil {
+			return nil, errors.New("expires_at must be an RFC3339 timestamp")
+		}
+		if !t.After(time.Now()) {
+			return nil, errors.New("expires_at must be in the future")
+		}
+		expiresAt = &t
+	} else if s.giftCardValidity > 0 {
+		t := time.Now().Add(s.giftCardValidity)
+		expiresAt = &t
+	}
+
+	code, err := generateGiftCardCode()
+	if err != nil {
+		return nil, err
+	}
+
+	card := &models.GiftCard{
+		Code:           code,
+		InitialAmount:  req.Amount,
+		Status:         models.GiftCardStatusActive,
+		ExpiresAt:      expiresAt,
+		IssuedByUserID: userID,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		shiftID, err := s.tenderShift(ctx, tx, method, userID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(card).Error; err != nil {
+			return err
+		}
+		return s.post(ctx, tx, &models.StoredValueEntry{
+			AccountType:     models.AccountGiftCard,
+			AccountID:       card.ID,
+			EntryType:       models.EntryGiftCardIssue,
+			Amount:          req.Amount,
+			GiftCardID:      &card.ID,
+			TenderMethod:    &method,
+			ShiftID:         shiftID,
+			ReferenceNumber: req.ReferenceNumber,
+			CreatedByUserID: userID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toGiftCardResponse(card, req.Amount), nil
+}
+
+func (s *WalletService) GetGiftCard(ctx context.Context, code string) (*dto.GiftCardResponse, error) {
+	card, err := s.giftCardRepo.FindByCode(ctx, normalizeGiftCardCode(code))
+	if err != nil {
+		return nil, errors.New("gift card not found")
+	}
+
+	balance, err := s.storedValueRepo.GetBalance(ctx, models.AccountGiftCard, card.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toGiftCardResponse(card, balance), nil
+}
+
+// RedeemGiftCard moves the full balance of a gift card into a customer's
+// wallet.
+func (s *WalletService) RedeemGiftCard(ctx context.Context, code string, customerID uint, userID *uint) (*dto.GiftCardResponse, error) {
+	if _, err := s.userRepo.FindByID(ctx, customerID); err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	var card *models.GiftCard
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		card, err = s.giftCardRepo.WithTx(tx).FindByCodeForUpdate(ctx, normalizeGiftCardCode(code))
+		if err != nil {
+			return errors.New("gift card not found")
+		}
+
+		now := time.Now()
+		switch {
+		case card.Status == models.GiftCardStatusRedeemed:
+			return errors.New("gift card has already been redeemed")
+		case card.Status == models.GiftCardStatusExpired,
+			card.ExpiresAt != nil && !card.ExpiresAt.After(now):
+			return errors.New("gift card has expired")
+		}
+
+		balance, err := s.storedValueRepo.WithTx(tx).GetBalance(ctx, models.AccountGiftCard, card.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.post(ctx, tx, &models.StoredValueEntry{
+			AccountType:     models.AccountGiftCard,
+			AccountID:       card.ID,
+			EntryType:       models.EntryGiftCardRedemption,
+			Amount:          -balance,
+			GiftCardID:      &card.ID,
+			CreatedByUserID: userID,
+		}); err != nil {
+			return err
+		}
+
+		if err := s.post(ctx, tx, &models.StoredValueEntry{
+			AccountType:     models.AccountWallet,
+			AccountID:       customerID,
+			EntryType:       models.EntryGiftCardRedemption,
+			Amount:          balance,
+			GiftCardID:      &card.ID,
+			CreatedByUserID: userID,
+		}); err != nil {
+			return err
+		}
+
+		card.Status = models.GiftCardStatusRedeemed
+		card.RedeemedByUserID = &customerID
+		card.RedeemedAt = &now
+		return tx.Save(card).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toGiftCardResponse(card, 0), nil
+}
+
+// ExpireGiftCards writes off the remaining balance of every active gift card
+// whose expiry has passed and returns how many cards were expired.
+func (s *WalletService) ExpireGiftCards(ctx context.Context, now time.Time) (int, error) {
+	cards, err := s.giftCardRepo.FindExpired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, candidate := range cards {
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			card, err := s.giftCardRepo.WithTx(tx).FindByCodeForUpdate(ctx, candidate.Code)
+			if err != nil {
+				return err
+			}
+			// Redeemed between the scan and the lock
+			if card.Status != models.GiftCardStatusActive {
+				return nil
+			}
+
+			balance, err := s.storedValueRepo.WithTx(tx).GetBalance(ctx, models.AccountGiftCard, card.ID)
+			if err != nil {
+				return err
+			}
+
+			if balance > 0 {
+				if err := s.post(ctx, tx, &models.StoredValueEntry{
+					AccountType: models.AccountGiftCard,
+					AccountID:   card.ID,
+					EntryType:   models.EntryExpiry,
+					Amount:      -balance,
+					GiftCardID:  &card.ID,
+				}); err != nil {
+					return err
+				}
+			}
+
+			card.Status = models.GiftCardStatusExpired
+			if err := tx.Save(card).Error; err != nil {
+				return err
+			}
+			expired++
+			return nil
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+
+	return expired, nil
+}
+
+// resolveTender returns the enabled method stored value is bought with.
+// Stored value cannot pay for itself or be charged to a fleet account.
+func (s *WalletService) resolveTender(ctx context.Context, code string, referenceNumber *string) (models.PaymentMethod, error) {
+	method, err := s.paymentMethods.Resolve(ctx, code, referenceNumber)
+	if err != nil {
+		return "", err
+	}
+	if method.Code == models.MethodWallet || method.Code == models.MethodAccount {
+		return "", fmt.Errorf("stored value cannot be bought with %s", method.DisplayName)
+	}
+	return method.Code, nil
+}
+
+// tenderShift returns the shift money taken for stored value is accounted
+// to: the user's active shift, which stays locked against closing until the
+// transaction ends. Cash has to go into a drawer, so it needs one; other
+// methods are accounted to the shift when there is one.
+func (s *WalletService) tenderShift(ctx context.Context, tx *gorm.DB, method models.PaymentMethod, userID *uint) (*uint, error) {
+	var shift *models.Shift
+	if userID != nil {
+		var err error
+		shift, err = s.shiftRepo.WithTx(tx).FindActiveShiftByUserForShare(ctx, *userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if shift == nil {
+		if method == models.MethodCash {
+			return nil, ErrNoActiveShift
+		}
+		return nil, nil
+	}
+	return &shift.ID, nil
+}
+
+// post appends an entry to its account's ledger. The account is locked for
+// the rest of the transaction and the entry is rejected if it would take the
+// balance below zero.
+func (s *WalletService) post(ctx context.Context, tx *gorm.DB, entry *models.StoredValueEntry) error {
+	repo := s.storedValueRepo.WithTx(tx)
+	if err := repo.LockAccount(ctx, entry.AccountType, entry.AccountID); err != nil {
+		return err
+	}
+
+	balance, err := repo.GetBalance(ctx, entry.AccountType, entry.AccountID)
+	if err != nil {
+		return err
+	}
+
+	entry.BalanceAfter = roundAmount(balance + entry.Amount)
+	if entry.BalanceAfter < 0 {
+		return ErrInsufficientBalance
+	}
+
+	return repo.Append(ctx, entry)
+}
+
+func (s *WalletService) toEntryResponse(entry *models.StoredValueEntry) *dto.StoredValueEntryResponse {
+	var tenderMethod *string
+	if entry.TenderMethod != nil {
+		method := string(*entry.TenderMethod)
+		tenderMethod = &method
+	}
+
+	return &dto.StoredValueEntryResponse{
+		ID:              entry.ID,
+		AccountType:     string(entry.AccountType),
+		AccountID:       entry.AccountID,
+		EntryType:       string(entry.EntryType),
+		Amount:          entry.Amount,
+		BalanceAfter:    entry.BalanceAfter,
+		PaymentID:       entry.PaymentID,
+		GiftCardID:      entry.GiftCardID,
+		TenderMethod:    tenderMethod,
+		ShiftID:         entry.ShiftID,
+		ReferenceNumber: entry.ReferenceNumber,
+		Note:            entry.Note,
+		CreatedByUserID: entry.CreatedByUserID,
+		CreatedAt:       entry.CreatedAt,
+	}
+}
+
+func (s *WalletService) toGiftCardResponse(card *models.GiftCard, balance float64) *dto.GiftCardResponse {
+	return &dto.GiftCardResponse{
+		ID:               card.ID,
+		Code:             card.Code,
+		InitialAmount:    card.InitialAmount,
+		Balance:          balance,
+		Status:           string(card.Status),
+		ExpiresAt:        card.ExpiresAt,
+		RedeemedByUserID: card.RedeemedByUserID,
+		RedeemedAt:       card.RedeemedAt,
+		CreatedAt:        card.CreatedAt,
+	}
+}
+
+// generateGiftCardCode returns a random code formatted as XXXX-XXXX-XXXX-XXXX.
+func generateGiftCardCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(giftCardAlphabet)))
+	for i := 0; i < 16; i++ {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(giftCardAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+func normalizeGiftCardCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
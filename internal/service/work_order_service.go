@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"flashlight-go/internal/dto"
@@ -12,10 +11,21 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	PaymentStateUnpaid   = "unpaid"
+	PaymentStatePartial  = "partial"
+	PaymentStatePaid     = "paid"
+	PaymentStateOverpaid = "overpaid"
+)
+
 type WorkOrderService struct {
 	workOrderRepo     *repository.WorkOrderRepository
 	workOrderItemRepo *repository.WorkOrderItemRepository
 	productRepo       *repository.ProductRepository
+	categoryRepo      *repository.ProductCategoryRepository
+	shiftRepo         *repository.ShiftRepository
+	pricingService    *PricingService
+	loyaltyService    *LoyaltyService
 	db                *gorm.DB
 }
 
@@ -23,17 +33,54 @@ func NewWorkOrderService(
 	workOrderRepo *repository.WorkOrderRepository,
 	workOrderItemRepo *repository.WorkOrderItemRepository,
 	productRepo *repository.ProductRepository,
+	categoryRepo *repository.ProductCategoryRepository,
+	shiftRepo *repository.ShiftRepository,
+	pricingService *PricingService,
+	loyaltyService *LoyaltyService,
 	db *gorm.DB,
 ) *WorkOrderService {
 	return &WorkOrderService{
 		workOrderRepo:     workOrderRepo,
 		workOrderItemRepo: workOrderItemRepo,
 		productRepo:       productRepo,
+		categoryRepo:      categoryRepo,
+		shiftRepo:         shiftRepo,
+		pricingService:    pricingService,
+		loyaltyService:    loyaltyService,
 		db:                db,
 	}
 }
 
 func (s *WorkOrderService) Create(ctx context.Context, req dto.CreateWorkOrderRequest, cashierUserID *uint) (*dto.WorkOrderResponse, error) {
+	// Items are priced as of the moment the order is created
+	now := time.Now()
+	quote, err := s.pricingService.Quote(ctx, QuoteInput{
+		CustomerUserID:    req.CustomerUserID,
+		CustomerVehicleID: req.CustomerVehicleID,
+		PromoCode:         req.PromoCode,
+		Items:             req.Items,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	depositRequired, err := s.depositRequired(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	// Orders taken at the register belong to the cashier's open shift
+	var shiftID *uint
+	if cashierUserID != nil {
+		shift, err := s.shiftRepo.FindActiveShiftByUser(ctx, *cashierUserID)
+		if err != nil {
+			return nil, err
+		}
+		if shift != nil {
+			shiftID = &shift.ID
+		}
+	}
+
 	// Start transaction
 	tx := s.db.Begin()
 	defer func() {
@@ -64,38 +111,52 @@ func (s *WorkOrderService) Create(ctx context.Context, req dto.CreateWorkOrderRe
 		CustomerUserID:      req.CustomerUserID,
 		CustomerVehicleID:   req.CustomerVehicleID,
 		CashierUserID:       cashierUserID,
+		ShiftID:             shiftID,
 		QueueNumber:         &queueNumber,
 		Status:              models.StatusPending,
 		Notes:               req.Notes,
 		SpecialInstructions: req.SpecialInstructions,
+		Subtotal:            quote.Subtotal,
+		DiscountAmount:      quote.DiscountAmount,
+		TaxAmount:           quote.TaxAmount,
+		TotalAmount:         quote.TotalAmount,
+		DepositRequired:     depositRequired,
+		CreatedAt:           now,
+	}
+	if quote.Promo != nil {
+		workOrder.PromoID = &quote.Promo.ID
 	}
 
-	if err := tx.Create(workOrder).Error; err != nil {
+	// The promo is used up together with the order that redeems it
+	if err := s.pricingService.RedeemPromo(ctx, tx, quote); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	// Create work order items and calculate totals
-	var subtotal float64
-	for _, itemReq := range req.Items {
-		product, err := s.productRepo.FindByID(ctx, itemReq.ProductID)
-		if err != nil {
-			tx.Rollback()
-			return nil, errors.New("product not found")
-		}
-
-		itemSubtotal := product.Price * float64(itemReq.Quantity)
-		subtotal += itemSubtotal
+	if err := tx.Create(workOrder).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
+	// Create work order items from the quoted lines
+	for _, line := range quote.Lines {
 		item := &models.WorkOrderItem{
 			WorkOrderID:         workOrder.ID,
-			ProductID:           product.ID,
-			ProductNameSnapshot: product.Name,
-			PriceSnapshot:       product.Price,
-			Quantity:            itemReq.Quantity,
-			Subtotal:            itemSubtotal,
-			AssignedStaffUserID: itemReq.AssignedStaffUserID,
-			ItemNote:            itemReq.ItemNote,
+			ProductID:           line.Product.ID,
+			ProductNameSnapshot: line.Product.Name,
+			BasePriceSnapshot:   line.BasePrice,
+			PriceSnapshot:       line.UnitPrice,
+			Quantity:            line.Quantity,
+			Subtotal:            line.Subtotal,
+			AssignedStaffUserID: line.AssignedStaffUserID,
+			ItemNote:            line.ItemNote,
+		}
+		if line.Tier != nil {
+			item.VehiclePriceTierID = &line.Tier.ID
+		}
+		if line.Rule != nil {
+			item.PricingRuleID = &line.Rule.ID
+			item.PricingRuleNameSnapshot = &line.Rule.Name
 		}
 
 		if err := tx.Create(item).Error; err != nil {
@@ -104,15 +165,6 @@ func (s *WorkOrderService) Create(ctx context.Context, req dto.CreateWorkOrderRe
 		}
 	}
 
-	// Update work order totals
-	workOrder.Subtotal = subtotal
-	workOrder.TotalAmount = subtotal
-
-	if err := tx.Save(workOrder).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
@@ -126,6 +178,65 @@ func (s *WorkOrderService) Create(ctx context.Context, req dto.CreateWorkOrderRe
 	return s.toResponse(result), nil
 }
 
+// Quote prices an order exactly like Create would, without creating it or
+// consuming an order or queue number.
+func (s *WorkOrderService) Quote(ctx context.Context, req dto.QuoteWorkOrderRequest) (*dto.WorkOrderQuoteResponse, error) {
+	quote, err := s.pricingService.Quote(ctx, QuoteInput{
+		CustomerUserID:    req.CustomerUserID,
+		CustomerVehicleID: req.CustomerVehicleID,
+		PromoCode:         req.PromoCode,
+		Items:             req.Items,
+	}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	depositRequired, err := s.depositRequired(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.WorkOrderQuoteResponse{
+		Items:               make([]dto.WorkOrderQuoteItemResponse, len(quote.Lines)),
+		Subtotal:            quote.Subtotal,
+		DiscountAmount:      quote.DiscountAmount,
+		DiscountDescription: quote.DiscountDescription,
+		PromoDiscountAmount: quote.PromoDiscountAmount,
+		TaxRate:             quote.TaxRate,
+		TaxAmount:           quote.TaxAmount,
+		TotalAmount:         quote.TotalAmount,
+		DepositRequired:     depositRequired,
+		PricedAt:            quote.PricedAt,
+	}
+	if quote.Promo != nil {
+		response.PromoCode = &quote.Promo.Code
+	}
+	if quote.VehicleType != "" {
+		response.VehicleType = &quote.VehicleType
+	}
+
+	for i, line := range quote.Lines {
+		item := dto.WorkOrderQuoteItemResponse{
+			ProductID:   line.Product.ID,
+			ProductName: line.Product.Name,
+			BasePrice:   line.BasePrice,
+			UnitPrice:   line.UnitPrice,
+			Quantity:    line.Quantity,
+			Subtotal:    line.Subtotal,
+		}
+		if line.Tier != nil {
+			item.VehicleTierID = &line.Tier.ID
+		}
+		if line.Rule != nil {
+			item.PricingRuleID = &line.Rule.ID
+			item.PricingRuleName = &line.Rule.Name
+		}
+		response.Items[i] = item
+	}
+
+	return response, nil
+}
+
 func (s *WorkOrderService) GetByID(ctx context.Context, id uint) (*dto.WorkOrderResponse, error) {
 	workOrder, err := s.workOrderRepo.FindWithItems(ctx, id)
 	if err != nil {
@@ -135,7 +246,7 @@ func (s *WorkOrderService) GetByID(ctx context.Context, id uint) (*dto.WorkOrder
 }
 
 func (s *WorkOrderService) GetAll(ctx context.Context, page, perPage int) ([]dto.WorkOrderResponse, *dto.PaginationMeta, error) {
-	workOrders, total, err := s.workOrderRepo.FindAll(ctx, page, perPage, "Items", "CustomerUser", "CustomerVehicle", "CustomerVehicle.Vehicle")
+	workOrders, total, err := s.workOrderRepo.FindAll(ctx, page, perPage, "Items", "Payments", "CustomerUser", "CustomerVehicle", "CustomerVehicle.Vehicle")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -166,6 +277,7 @@ func (s *WorkOrderService) Update(ctx context.Context, id uint, req dto.UpdateWo
 		return nil, err
 	}
 
+	previousStatus := workOrder.Status
 	if req.Status != nil {
 		workOrder.Status = models.WorkOrderStatus(*req.Status)
 
@@ -203,7 +315,18 @@ func (s *WorkOrderService) Update(ctx context.Context, id uint, req dto.UpdateWo
 	// Recalculate total
 	workOrder.TotalAmount = workOrder.Subtotal - workOrder.DiscountAmount + workOrder.TaxAmount
 
-	if err := s.workOrderRepo.Update(ctx, workOrder); err != nil {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(workOrder).Error; err != nil {
+			return err
+		}
+
+		// Cancelling an order takes back earned points and returns redeemed ones
+		if previousStatus != models.StatusCancelled && workOrder.Status == models.StatusCancelled {
+			return s.loyaltyService.ReverseForWorkOrder(ctx, tx, workOrder.ID, true)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -254,29 +377,111 @@ func (s *WorkOrderService) toResponse(wo *models.WorkOrder) *dto.WorkOrderRespon
 		DiscountAmount:      wo.DiscountAmount,
 		TaxAmount:           wo.TaxAmount,
 		TotalAmount:         wo.TotalAmount,
+		DepositRequired:     wo.DepositRequired,
+		PromoID:             wo.PromoID,
 		CreatedAt:           wo.CreatedAt,
 		UpdatedAt:           wo.UpdatedAt,
 	}
 
+	// Balance from the order's payments, which every query loads. Refunds
+	// are taken off what was paid, so they reopen the balance.
+	response.PaidAmount = paidAmount(wo.Payments)
+	response.RefundedAmount = refundedAmount(wo.Payments)
+	response.NetPaid = roundAmount(response.PaidAmount - response.RefundedAmount)
+	response.OutstandingAmount = roundAmount(wo.TotalAmount - response.NetPaid)
+	if response.OutstandingAmount < 0 {
+		response.OutstandingAmount = 0
+	}
+	response.PaymentStatus = paymentState(wo.TotalAmount, response.NetPaid)
+
 	// Add items if loaded
 	if len(wo.Items) > 0 {
 		response.Items = make([]dto.WorkOrderItemResponse, len(wo.Items))
 		for i, item := range wo.Items {
 			response.Items[i] = dto.WorkOrderItemResponse{
-				ID:                  item.ID,
-				WorkOrderID:         item.WorkOrderID,
-				ProductID:           item.ProductID,
-				ProductNameSnapshot: item.ProductNameSnapshot,
-				PriceSnapshot:       item.PriceSnapshot,
-				Quantity:            item.Quantity,
-				Subtotal:            item.Subtotal,
-				AssignedStaffUserID: item.AssignedStaffUserID,
-				ItemNote:            item.ItemNote,
-				CreatedAt:           item.CreatedAt,
-				UpdatedAt:           item.UpdatedAt,
+				ID:                      item.ID,
+				WorkOrderID:             item.WorkOrderID,
+				ProductID:               item.ProductID,
+				ProductNameSnapshot:     item.ProductNameSnapshot,
+				BasePriceSnapshot:       item.BasePriceSnapshot,
+				PriceSnapshot:           item.PriceSnapshot,
+				PricingRuleID:           item.PricingRuleID,
+				PricingRuleNameSnapshot: item.PricingRuleNameSnapshot,
+				VehiclePriceTierID:      item.VehiclePriceTierID,
+				Quantity:                item.Quantity,
+				Subtotal:                item.Subtotal,
+				AssignedStaffUserID:     item.AssignedStaffUserID,
+				ItemNote:                item.ItemNote,
+				CreatedAt:               item.CreatedAt,
+				UpdatedAt:               item.UpdatedAt,
 			}
 		}
 	}
 
 	return response
 }
+
+// depositRequired works out the minimum deposit for a quoted order from the
+// deposit percentage of each item's category.
+func (s *WorkOrderService) depositRequired(ctx context.Context, quote *Quote) (float64, error) {
+	percentages := make(map[uint]float64)
+	var deposit float64
+	for _, line := range quote.Lines {
+		categoryID := line.Product.CategoryID
+		percentage, ok := percentages[categoryID]
+		if !ok {
+			category, err := s.categoryRepo.FindByID(ctx, categoryID)
+			if err != nil {
+				return 0, err
+			}
+			percentage = category.MinDepositPercentage
+			percentages[categoryID] = percentage
+		}
+		deposit += line.Subtotal * percentage / 100
+	}
+
+	// The deposit can never be more than the order costs
+	deposit = roundAmount(deposit)
+	if deposit > quote.TotalAmount {
+		deposit = quote.TotalAmount
+	}
+	return deposit, nil
+}
+
+// paidAmount sums what completed payments contribute to an order, net of
+// change. Refunds are summed separately by refundedAmount.
+func paidAmount(payments []models.Payment) float64 {
+	var paid float64
+	for _, payment := range payments {
+		if payment.Status == models.PaymentStatusCompleted {
+			paid += payment.AmountPaid - payment.ChangeAmount
+		}
+	}
+	return roundAmount(paid)
+}
+
+// refundedAmount sums what has been refunded on an order's completed
+// payments.
+func refundedAmount(payments []models.Payment) float64 {
+	var refunded float64
+	for _, payment := range payments {
+		if payment.Status == models.PaymentStatusCompleted {
+			refunded += payment.RefundedAmount
+		}
+	}
+	return roundAmount(refunded)
+}
+
+// paymentState describes how far an order has been paid.
+func paymentState(total, paid float64) string {
+	switch {
+	case paid <= 0:
+		return PaymentStateUnpaid
+	case paid < roundAmount(total):
+		return PaymentStatePartial
+	case paid == roundAmount(total):
+		return PaymentStatePaid
+	default:
+		return PaymentStateOverpaid
+	}
+}
@@ -0,0 +1,124 @@
+// Package settlement reads the settlement files QRIS and e-wallet acquirers
+// send each day. Every acquirer lays out its CSV differently, so the columns,
+// delimiter and number and date formats of a file are described by a Layout.
+package settlement
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+//go:embed layouts.json
+var defaultLayouts []byte
+
+// Layout describes the CSV file of one acquirer.
+type Layout struct {
+	Name string `json:"name"`
+	// Methods are the payment methods the acquirer settles, used to find the
+	// payments missing from a settlement.
+	Methods []string `json:"methods"`
+	// Delimiter separates fields; a comma when empty.
+	Delimiter string `json:"delimiter"`
+	// SkipRows is the number of rows before the header row, such as a title
+	// or the merchant's details.
+	SkipRows int `json:"skip_rows"`
+	// DateFormat is the Go layout of the settlement date column.
+	DateFormat string `json:"date_format"`
+	// DecimalSeparator is "." or ","; the other one is taken as the
+	// thousands separator.
+	DecimalSeparator string `json:"decimal_separator"`
+	// MDRPercent is the merchant discount rate used when the file has
+	// neither a fee nor a net amount column.
+	MDRPercent float64 `json:"mdr_percent"`
+	// SettlementLagDays is how many days after the payment the acquirer
+	// settles it.
+	SettlementLagDays int     `json:"settlement_lag_days"`
+	Columns           Columns `json:"columns"`
+}
+
+// Columns names the header of each column read from the file. Reference and
+// Amount are required, the others optional.
+type Columns struct {
+	Reference      string `json:"reference"`
+	Amount         string `json:"amount"`
+	Fee            string `json:"fee"`
+	Net            string `json:"net"`
+	SettlementDate string `json:"settlement_date"`
+}
+
+func (l *Layout) validate() error {
+	switch {
+	case l.Name == "":
+		return errors.New("layout without a name")
+	case l.Columns.Reference == "" || l.Columns.Amount == "":
+		return fmt.Errorf("layout %q needs reference and amount columns", l.Name)
+	case len(l.Methods) == 0:
+		return fmt.Errorf("layout %q has no payment methods", l.Name)
+	case len([]rune(l.Delimiter)) > 1:
+		return fmt.Errorf("layout %q has a delimiter longer than one character", l.Name)
+	case l.DecimalSeparator != "" && l.DecimalSeparator != "." && l.DecimalSeparator != ",":
+		return fmt.Errorf("layout %q has an unsupported decimal separator %q", l.Name, l.DecimalSeparator)
+	}
+	return nil
+}
+
+// Layouts holds the known layouts by name.
+type Layouts map[string]Layout
+
+// LoadLayouts returns the built-in layouts, extended or overridden by the
+// layouts in the JSON file at path when one is given.
+func LoadLayouts(path string) (Layouts, error) {
+	layouts := Layouts{}
+	if err := layouts.add(defaultLayouts); err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		return layouts, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := layouts.add(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return layouts, nil
+}
+
+func (l Layouts) add(data []byte) error {
+	var layouts []Layout
+	if err := json.Unmarshal(data, &layouts); err != nil {
+		return err
+	}
+	for _, layout := range layouts {
+		if err := layout.validate(); err != nil {
+			return err
+		}
+		l[layout.Name] = layout
+	}
+	return nil
+}
+
+// Get returns the layout with the given name.
+func (l Layouts) Get(name string) (Layout, error) {
+	layout, ok := l[name]
+	if !ok {
+		return Layout{}, fmt.Errorf("unknown settlement layout %q", name)
+	}
+	return layout, nil
+}
+
+// List returns the layouts sorted by name.
+func (l Layouts) List() []Layout {
+	layouts := make([]Layout, 0, len(l))
+	for _, layout := range l {
+		layouts = append(layouts, layout)
+	}
+	sort.Slice(layouts, func(i, j int) bool { return layouts[i].Name < layouts[j].Name })
+	return layouts
+}
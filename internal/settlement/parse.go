@@ -0,0 +1,188 @@
+package settlement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat is the format of settlement dates outside of files.
+const DateFormat = "2006-01-02"
+
+// Line is one settled transaction read from a file. Amounts are rounded to
+// two decimals.
+type Line struct {
+	// Row is the line's 1-based row number in the file.
+	Row       int
+	Reference string
+	// Amount is the gross amount the customer paid.
+	Amount float64
+	// MDRAmount is the merchant discount the acquirer kept.
+	MDRAmount float64
+	// NetAmount is what the acquirer paid out.
+	NetAmount float64
+	// SettlementDate is formatted as DateFormat.
+	SettlementDate string
+}
+
+// Parse reads the lines of a settlement file. Files without a settlement
+// date column use the given date, formatted as DateFormat.
+func Parse(r io.Reader, layout Layout, settlementDate string) ([]Line, error) {
+	if layout.Columns.SettlementDate == "" && settlementDate == "" {
+		return nil, errors.New("the layout has no settlement date column, a settlement date is required")
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	if layout.Delimiter != "" {
+		reader.Comma = []rune(layout.Delimiter)[0]
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) <= layout.SkipRows {
+		return nil, errors.New("the file has no header row")
+	}
+
+	header := records[layout.SkipRows]
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	index := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		i, ok := columns[strings.ToLower(name)]
+		if !ok {
+			return -1, fmt.Errorf("column %q not found in the header", name)
+		}
+		return i, nil
+	}
+
+	referenceCol, err := index(layout.Columns.Reference)
+	if err != nil {
+		return nil, err
+	}
+	amountCol, err := index(layout.Columns.Amount)
+	if err != nil {
+		return nil, err
+	}
+	feeCol, err := index(layout.Columns.Fee)
+	if err != nil {
+		return nil, err
+	}
+	netCol, err := index(layout.Columns.Net)
+	if err != nil {
+		return nil, err
+	}
+	dateCol, err := index(layout.Columns.SettlementDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []Line
+	for i, record := range records[layout.SkipRows+1:] {
+		row := layout.SkipRows + i + 2
+		if isBlank(record) {
+			continue
+		}
+
+		field := func(col int) string {
+			if col < 0 || col >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[col])
+		}
+
+		line := Line{Row: row, Reference: field(referenceCol), SettlementDate: settlementDate}
+		if line.Reference == "" {
+			return nil, fmt.Errorf("row %d: missing reference", row)
+		}
+
+		if line.Amount, err = parseAmount(field(amountCol), layout.DecimalSeparator); err != nil {
+			return nil, fmt.Errorf("row %d: amount: %w", row, err)
+		}
+
+		// The fee is read, or derived from the net amount, or else charged at
+		// the layout's rate
+		switch {
+		case feeCol >= 0:
+			if line.MDRAmount, err = parseAmount(field(feeCol), layout.DecimalSeparator); err != nil {
+				return nil, fmt.Errorf("row %d: fee: %w", row, err)
+			}
+		case netCol >= 0:
+			net, err := parseAmount(field(netCol), layout.DecimalSeparator)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: net amount: %w", row, err)
+			}
+			line.MDRAmount = round(line.Amount - net)
+		default:
+			line.MDRAmount = round(line.Amount * layout.MDRPercent / 100)
+		}
+
+		if netCol >= 0 {
+			if line.NetAmount, err = parseAmount(field(netCol), layout.DecimalSeparator); err != nil {
+				return nil, fmt.Errorf("row %d: net amount: %w", row, err)
+			}
+		} else {
+			line.NetAmount = round(line.Amount - line.MDRAmount)
+		}
+
+		if dateCol >= 0 {
+			date, err := time.Parse(layout.DateFormat, field(dateCol))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: settlement date: %w", row, err)
+			}
+			line.SettlementDate = date.Format(DateFormat)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// parseAmount reads an amount such as "155000", "155,000.00" or, with a
+// decimal comma, "155.000,00". A currency prefix is ignored.
+func parseAmount(value, decimalSeparator string) (float64, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "Rp"), "IDR")
+	value = strings.NewReplacer(" ", "", "\u00a0", "").Replace(value)
+
+	if decimalSeparator == "," {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	} else {
+		value = strings.ReplaceAll(value, ",", "")
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return round(amount), nil
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"os"
 	"time"
@@ -8,37 +10,62 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// PurposeSupervisorApproval marks a token that approves one operation on a
+// supervisor's behalf and cannot be used to sign in. Its ID is recorded
+// when it is used, so it cannot approve another.
+const PurposeSupervisorApproval = "supervisor_approval"
+
 type Claims struct {
 	UserID uint   `json:"user_id"`
 	Role   string `json:"role"`
+	// Purpose is empty for login tokens.
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func GenerateToken(userID uint, role string) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-secret-key"
+	return signToken(userID, role, "", "", time.Now().Add(24*time.Hour))
+}
+
+// GenerateApprovalToken issues a supervisor approval token valid for ttl,
+// with a random ID to tell it apart once used.
+func GenerateApprovalToken(userID uint, role string, ttl time.Duration) (string, time.Time, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", time.Time{}, err
 	}
 
-	expirationTime := time.Now().Add(24 * time.Hour)
+	expiresAt := time.Now().Add(ttl)
+	token, err := signToken(userID, role, PurposeSupervisorApproval, hex.EncodeToString(id), expiresAt)
+	return token, expiresAt, err
+}
+
+func signToken(userID uint, role, purpose, id string, expiresAt time.Time) (string, error) {
 	claims := &Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:  userID,
+		Role:    role,
+		Purpose: purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			ID:        id,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return token.SignedString([]byte(jwtSecret()))
 }
 
-func ValidateToken(tokenString string) (*Claims, error) {
+func jwtSecret() string {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "your-secret-key"
 	}
+	return secret
+}
+
+func ValidateToken(tokenString string) (*Claims, error) {
+	secret := jwtSecret()
 
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// QRISMerchant holds the merchant details encoded into every QRIS payload.
+type QRISMerchant struct {
+	AcquirerGUI string // e.g. ID.CO.BANKNAME.WWW
+	MerchantPAN string
+	MerchantID  string
+	NMID        string // National Merchant ID issued for QRIS
+	Criteria    string // UMI, UKE, UME or UBE
+	MCC         string
+	Name        string
+	City        string
+	PostalCode  string
+	TerminalID  string
+}
+
+// Configured reports whether enough merchant details are set to produce a
+// payload a QRIS app will accept.
+func (m QRISMerchant) Configured() bool {
+	return m.AcquirerGUI != "" && m.MerchantPAN != "" && m.NMID != "" && m.Name != "" && m.City != ""
+}
+
+// BuildQRISPayload builds a dynamic QRIS (EMVCo merchant-presented mode)
+// string for a single payment of the given amount in rupiah. The reference
+// is carried in the additional data field so the payment can be matched
+// when the acquirer reports it.
+func BuildQRISPayload(merchant QRISMerchant, amount float64, reference string) (string, error) {
+	if !merchant.Configured() {
+		return "", errors.New("QRIS merchant is not configured")
+	}
+	if amount <= 0 {
+		return "", errors.New("QRIS amount must be greater than zero")
+	}
+
+	criteria := merchant.Criteria
+	if criteria == "" {
+		criteria = "UMI"
+	}
+	mcc := merchant.MCC
+	if mcc == "" {
+		mcc = "7542"
+	}
+
+	transactionAmount, err := formatQRISAmount(amount)
+	if err != nil {
+		return "", err
+	}
+
+	merchantAccount, err := emvTemplate(
+		emvField{"00", merchant.AcquirerGUI},
+		emvField{"01", merchant.MerchantPAN},
+		emvField{"02", firstNonEmpty(merchant.MerchantID, merchant.NMID)},
+		emvField{"03", criteria},
+	)
+	if err != nil {
+		return "", err
+	}
+	qrisAccount, err := emvTemplate(
+		emvField{"00", "ID.CO.QRIS.WWW"},
+		emvField{"02", merchant.NMID},
+		emvField{"03", criteria},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	additionalFields := []emvField{{"05", truncate(reference, 25)}}
+	if merchant.TerminalID != "" {
+		additionalFields = append(additionalFields, emvField{"07", truncate(merchant.TerminalID, 25)})
+	}
+	additional, err := emvTemplate(additionalFields...)
+	if err != nil {
+		return "", err
+	}
+
+	fields := []emvField{
+		{"00", "01"},
+		{"01", "12"}, // dynamic, single use
+		{"26", merchantAccount},
+		{"51", qrisAccount},
+		{"52", mcc},
+		{"53", "360"},
+		{"54", transactionAmount},
+		{"58", "ID"},
+		{"59", truncate(merchant.Name, 25)},
+		{"60", truncate(merchant.City, 15)},
+	}
+	if merchant.PostalCode != "" {
+		fields = append(fields, emvField{"61", truncate(merchant.PostalCode, 10)})
+	}
+	fields = append(fields, emvField{"62", additional})
+
+	payload, err := emvTemplate(fields...)
+	if err != nil {
+		return "", err
+	}
+
+	// The checksum covers everything up to and including its own tag and length
+	payload += "6304"
+	return payload + fmt.Sprintf("%04X", CRC16CCITT([]byte(payload))), nil
+}
+
+// formatQRISAmount writes an amount in rupiah for the transaction amount
+// field: rounded to sen, without decimals when it is a whole amount and
+// with exactly two otherwise. The field holds at most 13 characters.
+func formatQRISAmount(amount float64) (string, error) {
+	sen := int64(math.Round(amount * 100))
+	if sen <= 0 {
+		return "", errors.New("QRIS amount must be greater than zero")
+	}
+
+	formatted := strconv.FormatInt(sen/100, 10)
+	if sen%100 != 0 {
+		formatted += fmt.Sprintf(".%02d", sen%100)
+	}
+	if len(formatted) > 13 {
+		return "", fmt.Errorf("QRIS amount %s is too large", formatted)
+	}
+	return formatted, nil
+}
+
+// CRC16CCITT computes the CRC-16/CCITT-FALSE checksum (polynomial 0x1021,
+// initial value 0xFFFF) that EMVCo QR codes use.
+func CRC16CCITT(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, c := range data {
+		crc ^= uint16(c) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// emvField is one tag-length-value data object of an EMVCo QR code.
+type emvField struct {
+	tag   string
+	value string
+}
+
+// emvTemplate encodes fields in order. The length is written as two digits,
+// so a value longer than 99 bytes cannot be encoded.
+func emvTemplate(fields ...emvField) (string, error) {
+	var b strings.Builder
+	for _, field := range fields {
+		if len(field.value) > 99 {
+			return "", fmt.Errorf("QRIS field %s is %d bytes long, at most 99 fit", field.tag, len(field.value))
+		}
+		fmt.Fprintf(&b, "%s%02d%s", field.tag, len(field.value), field.value)
+	}
+	return b.String(), nil
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
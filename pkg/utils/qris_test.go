@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+var testMerchant = QRISMerchant{
+	AcquirerGUI: "ID.CO.BANKNAME.WWW",
+	MerchantPAN: "936000140000012345",
+	NMID:        "ID1020012345678",
+	Criteria:    "UMI",
+	MCC:         "7542",
+	Name:        "FLASHLIGHT CAR WASH",
+	City:        "JAKARTA",
+	PostalCode:  "12345",
+	TerminalID:  "KASIR01",
+}
+
+func TestCRC16CCITT(t *testing.T) {
+	// The standard check value of CRC-16/CCITT-FALSE
+	if got := CRC16CCITT([]byte("123456789")); got != 0x29B1 {
+		t.Fatalf("CRC16CCITT(123456789) = %04X, want 29B1", got)
+	}
+}
+
+func TestBuildQRISPayload(t *testing.T) {
+	// 11101.11 + 0.1 is 11101.210000000001 in floating point
+	payload, err := BuildQRISPayload(testMerchant, 11101.11+0.1, "PAY-20240101-0001")
+	if err != nil {
+		t.Fatalf("BuildQRISPayload: %v", err)
+	}
+
+	want := "000201010212" +
+		"26700018ID.CO.BANKNAME.WWW0118936000140000012345" + "0215ID1020012345678" + "0303UMI" +
+		"51440014ID.CO.QRIS.WWW0215ID10200123456780303UMI" +
+		"52047542" +
+		"5303360" +
+		"540811101.21" +
+		"5802ID" +
+		"5919FLASHLIGHT CAR WASH" +
+		"6007JAKARTA" +
+		"610512345" +
+		"62320517PAY-20240101-00010707KASIR01" +
+		"63048EDB"
+	if payload != want {
+		t.Fatalf("payload\n got %s\nwant %s", payload, want)
+	}
+}
+
+func TestBuildQRISPayloadAmount(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   string
+	}{
+		{50000, "540550000"},
+		{49999.999, "540550000"},
+		{12500.5, "540812500.50"},
+		{0.01, "54040.01"},
+	}
+
+	for _, tt := range tests {
+		payload, err := BuildQRISPayload(testMerchant, tt.amount, "PAY-1")
+		if err != nil {
+			t.Fatalf("BuildQRISPayload(%v): %v", tt.amount, err)
+		}
+		if !strings.Contains(payload, "5303360"+tt.want+"5802ID") {
+			t.Errorf("BuildQRISPayload(%v) = %s, want amount field %s", tt.amount, payload, tt.want)
+		}
+	}
+}
+
+func TestBuildQRISPayloadRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		merchant QRISMerchant
+		amount   float64
+	}{
+		{"zero amount", testMerchant, 0},
+		{"rounds to zero", testMerchant, 0.004},
+		{"amount too long", testMerchant, 1e13},
+		{"unconfigured merchant", QRISMerchant{}, 10000},
+		{"field over 99 bytes", func() QRISMerchant {
+			m := testMerchant
+			m.AcquirerGUI = strings.Repeat("A", 100)
+			return m
+		}(), 10000},
+	}
+
+	for _, tt := range tests {
+		if payload, err := BuildQRISPayload(tt.merchant, tt.amount, "PAY-1"); err == nil {
+			t.Errorf("%s: got payload %s, want an error", tt.name, payload)
+		}
+	}
+}